@@ -1,23 +1,84 @@
 package api
 
 import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/auth"
+	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/external"
 	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/handler"
+	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/metrics"
+	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/openapi"
+	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/service"
+	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/stream"
+	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/tracing"
+	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/worker"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
 )
 
 type application struct {
-	handler handler.Handlers
-	config  Config
+	handler    handler.Handlers
+	service    service.Service
+	external   external.External
+	tokenCache *auth.TokenCache
+	config     Config
+	workers    []*worker.Worker
+	hub        *stream.Hub
+	http       *fiber.App
+	admin      *fiber.App
+	grpc       *grpc.Server
+	db         *pgxpool.Pool
+	ctx        context.Context
+	cancel     context.CancelFunc
+	// background tracks the workers and listeners running on ctx
+	background sync.WaitGroup
+	// stopTracing flushes the spans not exported yet
+	stopTracing func(context.Context) error
 }
 
 type Config struct {
 	addrHTTP string
 	addrGRPC string
-	logger   *logrus.Logger
-	db       DBConfig
-	auth     AuthConfig
+	// addrAdmin serves the operational endpoints, kept off the public port
+	addrAdmin string
+	logger    *logrus.Logger
+	db        DBConfig
+	auth      AuthConfig
+	reward    RewardConfig
+	wallet    WalletConfig
+	batch     BatchConfig
+	dispute   DisputeConfig
+	recon     ReconciliationConfig
+	webhook   WebhookConfig
+	event     EventConfig
+	clients   GRPCClientsConfig
+	notif     NotificationConfig
+	openapi   OpenAPIConfig
+	metrics   MetricsConfig
+	tracing   TracingConfig
+}
+
+type GRPCClientsConfig struct {
+	userService      ServiceClientConfig
+	notifService     ServiceClientConfig
+	keepaliveTime    string
+	keepaliveTimeout string
+	maxAttempts      int
+}
+
+type ServiceClientConfig struct {
+	addr          string
+	timeout       string
+	tls           bool
+	tlsCA         string
+	tlsCert       string
+	tlsKey        string
+	tlsServerName string
 }
 
 type DBConfig struct {
@@ -28,28 +89,183 @@ type DBConfig struct {
 }
 
 type AuthConfig struct {
-	secret string
-	iss    string
-	aud    string
+	secret              string
+	iss                 string
+	aud                 string
+	mode                string
+	jwksURL             string
+	jwksRefreshInterval string
+	cacheSize           int
+	cacheTTL            string
+	cacheStatsInterval  string
+}
+
+type RewardConfig struct {
+	clearingPeriod string
+	workerInterval string
+}
+
+type DisputeConfig struct {
+	reviewPeriod   string
+	workerInterval string
+}
+
+type ReconciliationConfig struct {
+	interval string
+}
+
+type WebhookConfig struct {
+	maxAttempts    int
+	retryBase      string
+	disableAfter   int
+	workerInterval string
+}
+
+type NotificationConfig struct {
+	templatesFile  string
+	workers        int
+	maxAttempts    int
+	retryBase      string
+	workerInterval string
+}
+
+type EventConfig struct {
+	broker        string
+	natsURL       string
+	relayInterval string
+	maxAttempts   int
+}
+
+type MetricsConfig struct {
+	queueInterval string
+}
+
+type TracingConfig struct {
+	exporter     string
+	otlpEndpoint string
+	otlpInsecure bool
+	serviceName  string
+	sampleRatio  string
+}
+
+type OpenAPIConfig struct {
+	lenientPrefixes string
+}
+
+type BatchConfig struct {
+	maxItems int
+}
+
+type WalletConfig struct {
+	serviceToken     string
+	addr             string
+	basePath         string
+	timeout          string
+	maxAttempts      int
+	retryBase        string
+	retryMax         string
+	breakerThreshold int
+	breakerCooldown  string
+	statsInterval    string
 }
 
 func (app *application) mount() *fiber.App {
-	r := fiber.New()
+	r := fiber.New(fiber.Config{
+		ErrorHandler: handler.ErrorHandler,
+	})
+
+	r.Use(tracing.HTTP())
+	r.Use(metrics.HTTP())
+
+	validator := openapi.NewValidator(strings.Split(app.config.openapi.lenientPrefixes, ","))
+	r.Use(validator.Middleware())
 
 	r.Get("/health", app.handler.Health.CheckHealth)
 
 	v1 := r.Group("/v1")
 	transactionRoute := v1.Group("/transaction")
-	transactionRoute.Post("/", app.handler.Middleware.AuthMiddleware(), app.handler.Transaction.Create)
-	transactionRoute.Put("/:reference", app.handler.Middleware.AuthMiddleware(), app.handler.Transaction.Update)
-	transactionRoute.Get("/", app.handler.Middleware.AuthMiddleware(), app.handler.Transaction.GetTransactions)
-	transactionRoute.Get("/:reference", app.handler.Middleware.AuthMiddleware(), app.handler.Transaction.GetTransaction)
-	transactionRoute.Post("/refund", app.handler.Middleware.AuthMiddleware(), app.handler.Transaction.Refund)
+	transactionRoute.Post("/", app.handler.Middleware.AuthMiddleware(), app.handler.Middleware.Authorize(), app.handler.Transaction.Create)
+	transactionRoute.Post("/batch", app.handler.Middleware.AuthMiddleware(), app.handler.Middleware.Authorize(), app.handler.Transaction.CreateBatch)
+	transactionRoute.Get("/batch/:batch_id", app.handler.Middleware.AuthMiddleware(), app.handler.Middleware.Authorize(), app.handler.Transaction.GetBatch)
+	transactionRoute.Get("/notification-preferences", app.handler.Middleware.AuthMiddleware(), app.handler.Middleware.Authorize(), app.handler.Notification.GetPreferences)
+	transactionRoute.Put("/notification-preferences", app.handler.Middleware.AuthMiddleware(), app.handler.Middleware.Authorize(), app.handler.Notification.UpdatePreferences)
+	transactionRoute.Put("/:reference", app.handler.Middleware.AuthMiddleware(), app.handler.Middleware.Authorize(), app.handler.Transaction.Update)
+	transactionRoute.Post("/:reference/cancel", app.handler.Middleware.AuthMiddleware(), app.handler.Middleware.Authorize(), app.handler.Transaction.Cancel)
+	transactionRoute.Post("/:reference/dispute", app.handler.Middleware.AuthMiddleware(), app.handler.Middleware.Authorize(), app.handler.Dispute.Open)
+	transactionRoute.Get("/", app.handler.Middleware.AuthMiddleware(), app.handler.Middleware.Authorize(), app.handler.Transaction.GetTransactions)
+	transactionRoute.Get("/stream", app.handler.Middleware.AuthMiddleware(), app.handler.Middleware.Authorize(), app.handler.Stream.Events)
+	transactionRoute.Get("/stream/ws", app.handler.Middleware.AuthMiddleware(), app.handler.Middleware.Authorize(), app.handler.Stream.Upgrade, app.handler.Stream.WebSocket())
+	transactionRoute.Get("/rewards", app.handler.Middleware.AuthMiddleware(), app.handler.Middleware.Authorize(), app.handler.Reward.GetRewards)
+	transactionRoute.Get("/:reference", app.handler.Middleware.AuthMiddleware(), app.handler.Middleware.Authorize(), app.handler.Transaction.GetTransaction)
+	transactionRoute.Post("/refund", app.handler.Middleware.AuthMiddleware(), app.handler.Middleware.Authorize(), app.handler.Transaction.Refund)
+
+	disputeRoute := v1.Group("/dispute")
+	disputeRoute.Get("/", app.handler.Middleware.AuthMiddleware(), app.handler.Middleware.Authorize(), app.handler.Dispute.GetDisputes)
+	disputeRoute.Get("/:id", app.handler.Middleware.AuthMiddleware(), app.handler.Middleware.Authorize(), app.handler.Dispute.GetDispute)
+	disputeRoute.Post("/:id/evidence", app.handler.Middleware.AuthMiddleware(), app.handler.Middleware.Authorize(), app.handler.Dispute.AddEvidence)
+
+	webhookRoute := v1.Group("/webhook")
+	webhookRoute.Post("/", app.handler.Middleware.AuthMiddleware(), app.handler.Middleware.Authorize(), app.handler.Webhook.Register)
+	webhookRoute.Get("/", app.handler.Middleware.AuthMiddleware(), app.handler.Middleware.Authorize(), app.handler.Webhook.GetEndpoints)
+	webhookRoute.Delete("/:id", app.handler.Middleware.AuthMiddleware(), app.handler.Middleware.Authorize(), app.handler.Webhook.DeleteEndpoint)
+	webhookRoute.Post("/:id/enable", app.handler.Middleware.AuthMiddleware(), app.handler.Middleware.Authorize(), app.handler.Webhook.EnableEndpoint)
+	webhookRoute.Get("/:id/deliveries", app.handler.Middleware.AuthMiddleware(), app.handler.Middleware.Authorize(), app.handler.Webhook.GetDeliveries)
+	webhookRoute.Get("/:id/deliveries/:delivery_id", app.handler.Middleware.AuthMiddleware(), app.handler.Middleware.Authorize(), app.handler.Webhook.GetDelivery)
+	webhookRoute.Post("/:id/deliveries/:delivery_id/redeliver", app.handler.Middleware.AuthMiddleware(), app.handler.Middleware.Authorize(), app.handler.Webhook.Redeliver)
+
+	admin := r.Group("/admin/v1")
+	admin.Get("/transaction", app.handler.Middleware.AuthMiddleware(), app.handler.Middleware.Authorize(), app.handler.Admin.SearchTransactions)
+	admin.Get("/transaction/:reference", app.handler.Middleware.AuthMiddleware(), app.handler.Middleware.Authorize(), app.handler.Admin.GetTransaction)
+	admin.Put("/transaction/:reference/status", app.handler.Middleware.AuthMiddleware(), app.handler.Middleware.Authorize(), app.handler.Admin.ForceStatus)
+	admin.Post("/transaction/:reference/refund", app.handler.Middleware.AuthMiddleware(), app.handler.Middleware.Authorize(), app.handler.Admin.Refund)
+	admin.Post("/dispute/:id/resolve", app.handler.Middleware.AuthMiddleware(), app.handler.Middleware.Authorize(), app.handler.Admin.ResolveDispute)
+	admin.Get("/reconciliation", app.handler.Middleware.AuthMiddleware(), app.handler.Middleware.Authorize(), app.handler.Admin.GetReconciliationReports)
+	admin.Get("/reconciliation/:id", app.handler.Middleware.AuthMiddleware(), app.handler.Middleware.Authorize(), app.handler.Admin.GetReconciliationReport)
+	admin.Get("/audit", app.handler.Middleware.AuthMiddleware(), app.handler.Middleware.Authorize(), app.handler.Admin.GetAuditLogs)
+
+	// the document covers the routes above, it is built once they are all
+	// registered
+	doc, err := openapi.Build(r.GetRoutes(true), openapi.Operations, openapi.Info{
+		Title:   "Transaction API",
+		Version: "1.0.0",
+	})
+	if err != nil {
+		app.config.logger.Fatalf("failed to build openapi document :%v", err)
+	}
+
+	if err := validator.Load(doc); err != nil {
+		app.config.logger.Fatalf("failed to load openapi document :%v", err)
+	}
+
+	spec, err := openapi.SpecHandler(doc)
+	if err != nil {
+		app.config.logger.Fatal(err.Error())
+	}
+	r.Get("/openapi.json", spec)
+	r.Get("/docs", openapi.DocsHandler(doc.Info.Title, "/openapi.json"))
+
+	return r
+}
+
+// mountAdmin builds the admin server, it is only meant to be reachable by the
+// metrics scraper.
+func (app *application) mountAdmin() *fiber.App {
+	r := fiber.New(fiber.Config{
+		ErrorHandler:          handler.ErrorHandler,
+		DisableStartupMessage: true,
+	})
+
+	r.Get("/metrics", metrics.Handler())
 
 	return r
 }
 
-func (app *application) run(r *fiber.App) error {
+func (app *application) run() error {
 	app.config.logger.Printf("http server has running, port%v", app.config.addrHTTP)
-	return r.Listen(app.config.addrHTTP)
+	return app.http.Listen(app.config.addrHTTP)
+}
+
+func (app *application) runAdmin() error {
+	app.config.logger.Printf("admin server has running, port%v", app.config.addrAdmin)
+	return app.admin.Listen(app.config.addrAdmin)
 }
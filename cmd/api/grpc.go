@@ -3,32 +3,35 @@ package api
 import (
 	"net"
 
+	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/handler/protohandler"
+	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/proto/transaction"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"google.golang.org/grpc"
 )
 
-func SetupGRPC() {
-	app, err := SetupGRPCApplication()
-	if err != nil {
-		app.config.logger.Fatalf("failed to setup application (grpc): %v", err)
-	}
+func (app *application) newGRPCServer() *grpc.Server {
+	server := grpc.NewServer(
+		grpc.UnaryInterceptor(protohandler.AuthInterceptor(app.external)),
+		grpc.StreamInterceptor(protohandler.AuthStreamInterceptor(app.external)),
+		grpc.StatsHandler(otelgrpc.NewServerHandler()),
+	)
+
+	// register grpc
+	transactionServer := protohandler.NewTransactionServer(app.service)
+	transaction.RegisterTransactionServiceServer(server, transactionServer)
+
+	return server
+}
 
+func SetupGRPC(app *application) {
 	lis, err := net.Listen("tcp", app.config.addrGRPC)
 	if err != nil {
 		app.config.logger.Fatalf("failed to listen grpc port, err: %v", err)
 	}
 
-	server := grpc.NewServer()
-
-	// db, _ := ConnectDatabase(app.config.db, app.config.logger)
-	// auth := auth.NewJwt(app.config.auth.secret, app.config.auth.aud, app.config.auth.iss)
-
-	// // register grpc
-	// tokenHandler := protohandler.NewTokenService(sqlc.New(db), auth)
-	// token.RegisterTokenServiceServer(server, tokenHandler)
-
 	app.config.logger.Printf("grpc server has running, port%v", app.config.addrGRPC)
 
-	if err := server.Serve(lis); err != nil {
+	if err := app.grpc.Serve(lis); err != nil {
 		app.config.logger.Fatalf("failed to starting grpc server, err:%v", err)
 	}
 }
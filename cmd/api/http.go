@@ -1,13 +1,35 @@
 package api
 
-func SetupHTTP() {
-	app, err := SetupHTTPApplication()
-	if err != nil {
-		app.config.logger.Fatalf("failed setup application (http): %v", err)
+import "context"
+
+// goBackground runs fn on the application ctx, Shutdown waits for it to return
+// before closing what it uses.
+func (app *application) goBackground(fn func(context.Context)) {
+	app.background.Add(1)
+	go func() {
+		defer app.background.Done()
+		fn(app.ctx)
+	}()
+}
+
+func SetupHTTP(app *application) {
+	for _, w := range app.workers {
+		app.goBackground(w.Run)
 	}
+	app.goBackground(app.hub.Listen)
+	if app.tokenCache != nil {
+		app.goBackground(func(ctx context.Context) {
+			app.external.WatchRevocations(ctx, app.tokenCache, app.config.logger)
+		})
+	}
+
+	go func() {
+		if err := app.runAdmin(); err != nil {
+			app.config.logger.Fatalf("failed to start admin server: %v", err)
+		}
+	}()
 
-	api := app.mount()
-	if err := app.run(api); err != nil {
+	if err := app.run(); err != nil {
 		app.config.logger.Fatalf("failed to start http server: %v", err)
 	}
 }
@@ -2,11 +2,23 @@ package api
 
 import (
 	"context"
+	"fmt"
+	"strconv"
+	"time"
 
+	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/auth"
 	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/config/db"
 	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/config/logger"
 	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/env"
+	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/event"
+	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/external"
 	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/handler"
+	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/metrics"
+	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/notification"
+	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/service"
+	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/stream"
+	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/tracing"
+	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/worker"
 
 	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/storage/sqlc"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -23,8 +35,11 @@ func LoadConfig() (Config, error) {
 
 	cfg := Config{
 		addrHTTP: env.GetEnvString("ADDR_HTTP", ":4000"),
-		addrGRPC: env.GetEnvString("ADDR_GRPC", ":5000"),
-		logger:   logrus,
+		// not :5000, the default USER_SERVICE address on the same host
+		addrGRPC: env.GetEnvString("ADDR_GRPC", ":5001"),
+		// /metrics is served here rather than on ADDR_HTTP
+		addrAdmin: env.GetEnvString("ADDR_ADMIN", ":9090"),
+		logger:    logrus,
 		db: DBConfig{
 			addr:         env.GetEnvString("DB_ADDR", ""),
 			maxOpenConns: env.GetEnvInt("DB_MAX_CONNS", 5),
@@ -35,12 +50,168 @@ func LoadConfig() (Config, error) {
 			secret: env.GetEnvString("JWT_SECRET", ""),
 			iss:    env.GetEnvString("JWT_ISS", ""),
 			aud:    env.GetEnvString("JWT_AUD", ""),
+			// remote, local or hybrid, local modes need AUTH_JWKS_URL,
+			// JWT_ISS and JWT_AUD
+			mode:                env.GetEnvString("AUTH_MODE", "remote"),
+			jwksURL:             env.GetEnvString("AUTH_JWKS_URL", ""),
+			jwksRefreshInterval: env.GetEnvString("AUTH_JWKS_REFRESH_INTERVAL", "10m"),
+			// a size of 0 disables the token cache
+			cacheSize:          env.GetEnvInt("TOKEN_CACHE_SIZE", 10000),
+			cacheTTL:           env.GetEnvString("TOKEN_CACHE_TTL", "5m"),
+			cacheStatsInterval: env.GetEnvString("TOKEN_CACHE_STATS_INTERVAL", "5m"),
+		},
+		reward: RewardConfig{
+			clearingPeriod: env.GetEnvString("REWARD_CLEARING_PERIOD", "72h"),
+			workerInterval: env.GetEnvString("REWARD_WORKER_INTERVAL", "1m"),
+		},
+		wallet: WalletConfig{
+			serviceToken:     env.GetEnvString("WALLET_SERVICE_TOKEN", ""),
+			addr:             env.GetEnvString("WALLET_SERVICE", ""),
+			basePath:         env.GetEnvString("WALLET_BASE_PATH", ""),
+			timeout:          env.GetEnvString("WALLET_TIMEOUT", "10s"),
+			maxAttempts:      env.GetEnvInt("WALLET_MAX_ATTEMPTS", 3),
+			retryBase:        env.GetEnvString("WALLET_RETRY_BASE", "200ms"),
+			retryMax:         env.GetEnvString("WALLET_RETRY_MAX", "2s"),
+			breakerThreshold: env.GetEnvInt("WALLET_BREAKER_THRESHOLD", 5),
+			breakerCooldown:  env.GetEnvString("WALLET_BREAKER_COOLDOWN", "30s"),
+			statsInterval:    env.GetEnvString("WALLET_STATS_INTERVAL", "5m"),
+		},
+		batch: BatchConfig{
+			maxItems: env.GetEnvInt("TRANSACTION_BATCH_MAX_ITEMS", 500),
+		},
+		dispute: DisputeConfig{
+			reviewPeriod:   env.GetEnvString("DISPUTE_REVIEW_PERIOD", "336h"),
+			workerInterval: env.GetEnvString("DISPUTE_WORKER_INTERVAL", "5m"),
+		},
+		recon: ReconciliationConfig{
+			interval: env.GetEnvString("RECONCILIATION_INTERVAL", "24h"),
+		},
+		webhook: WebhookConfig{
+			maxAttempts:    env.GetEnvInt("WEBHOOK_MAX_ATTEMPTS", 8),
+			retryBase:      env.GetEnvString("WEBHOOK_RETRY_BASE", "30s"),
+			disableAfter:   env.GetEnvInt("WEBHOOK_DISABLE_AFTER", 20),
+			workerInterval: env.GetEnvString("WEBHOOK_WORKER_INTERVAL", "10s"),
+		},
+		event: EventConfig{
+			broker:        env.GetEnvString("EVENT_BROKER", "inprocess"),
+			natsURL:       env.GetEnvString("NATS_URL", "nats://127.0.0.1:4222"),
+			relayInterval: env.GetEnvString("EVENT_RELAY_INTERVAL", "2s"),
+			maxAttempts:   env.GetEnvInt("EVENT_MAX_ATTEMPTS", 20),
+		},
+		notif: NotificationConfig{
+			// a JSON file overriding the default transition templates
+			templatesFile:  env.GetEnvString("NOTIFICATION_TEMPLATES_FILE", ""),
+			workers:        env.GetEnvInt("NOTIFICATION_WORKERS", 4),
+			maxAttempts:    env.GetEnvInt("NOTIFICATION_MAX_ATTEMPTS", 10),
+			retryBase:      env.GetEnvString("NOTIFICATION_RETRY_BASE", "30s"),
+			workerInterval: env.GetEnvString("NOTIFICATION_WORKER_INTERVAL", "5s"),
+		},
+		metrics: MetricsConfig{
+			queueInterval: env.GetEnvString("METRICS_QUEUE_INTERVAL", "30s"),
+		},
+		tracing: TracingConfig{
+			// none, otlp or stdout, none still propagates the trace context
+			exporter:     env.GetEnvString("TRACING_EXPORTER", "none"),
+			otlpEndpoint: env.GetEnvString("TRACING_OTLP_ENDPOINT", "localhost:4317"),
+			otlpInsecure: env.GetEnvBool("TRACING_OTLP_INSECURE", true),
+			serviceName:  env.GetEnvString("TRACING_SERVICE_NAME", "transaction-service"),
+			sampleRatio:  env.GetEnvString("TRACING_SAMPLE_RATIO", "1"),
+		},
+		openapi: OpenAPIConfig{
+			// path prefixes whose requests are only logged when they don't
+			// match the openapi document, every other path rejects them
+			lenientPrefixes: env.GetEnvString("OPENAPI_LENIENT_PREFIXES", "/v1"),
+		},
+		clients: GRPCClientsConfig{
+			userService:      serviceClientConfig("USER_SERVICE", "localhost:5000", "3s"),
+			notifService:     serviceClientConfig("NOTIF_SERVICE", "", "5s"),
+			keepaliveTime:    env.GetEnvString("GRPC_CLIENT_KEEPALIVE_TIME", "30s"),
+			keepaliveTimeout: env.GetEnvString("GRPC_CLIENT_KEEPALIVE_TIMEOUT", "10s"),
+			maxAttempts:      env.GetEnvInt("GRPC_CLIENT_MAX_ATTEMPTS", 3),
 		},
 	}
 
 	return cfg, nil
 }
 
+// serviceClientConfig reads the client config of a gRPC service, prefix is the
+// address variable, e.g. NOTIF_SERVICE and NOTIF_SERVICE_TIMEOUT.
+func serviceClientConfig(prefix, addr, timeout string) ServiceClientConfig {
+	return ServiceClientConfig{
+		addr:          env.GetEnvString(prefix, addr),
+		timeout:       env.GetEnvString(prefix+"_TIMEOUT", timeout),
+		tls:           env.GetEnvBool(prefix+"_TLS", false),
+		tlsCA:         env.GetEnvString(prefix+"_TLS_CA", ""),
+		tlsCert:       env.GetEnvString(prefix+"_TLS_CERT", ""),
+		tlsKey:        env.GetEnvString(prefix+"_TLS_KEY", ""),
+		tlsServerName: env.GetEnvString(prefix+"_TLS_SERVER_NAME", ""),
+	}
+}
+
+func NewGRPCClientConfig(cfg ServiceClientConfig, clients GRPCClientsConfig) (external.GRPCClientConfig, error) {
+	timeout, err := time.ParseDuration(cfg.timeout)
+	if err != nil {
+		return external.GRPCClientConfig{}, fmt.Errorf("failed to parse timeout :%w", err)
+	}
+
+	keepaliveTime, err := time.ParseDuration(clients.keepaliveTime)
+	if err != nil {
+		return external.GRPCClientConfig{}, fmt.Errorf("failed to parse keepalive time :%w", err)
+	}
+
+	keepaliveTimeout, err := time.ParseDuration(clients.keepaliveTimeout)
+	if err != nil {
+		return external.GRPCClientConfig{}, fmt.Errorf("failed to parse keepalive timeout :%w", err)
+	}
+
+	return external.GRPCClientConfig{
+		Addr:             cfg.addr,
+		Timeout:          timeout,
+		KeepaliveTime:    keepaliveTime,
+		KeepaliveTimeout: keepaliveTimeout,
+		MaxAttempts:      clients.maxAttempts,
+		TLS: external.TLSConfig{
+			Enabled:    cfg.tls,
+			CAFile:     cfg.tlsCA,
+			CertFile:   cfg.tlsCert,
+			KeyFile:    cfg.tlsKey,
+			ServerName: cfg.tlsServerName,
+		},
+	}, nil
+}
+
+func NewWalletClientConfig(cfg WalletConfig) (external.WalletClientConfig, error) {
+	timeout, err := time.ParseDuration(cfg.timeout)
+	if err != nil {
+		return external.WalletClientConfig{}, fmt.Errorf("failed to parse timeout :%w", err)
+	}
+
+	retryBase, err := time.ParseDuration(cfg.retryBase)
+	if err != nil {
+		return external.WalletClientConfig{}, fmt.Errorf("failed to parse retry base :%w", err)
+	}
+
+	retryMax, err := time.ParseDuration(cfg.retryMax)
+	if err != nil {
+		return external.WalletClientConfig{}, fmt.Errorf("failed to parse retry max :%w", err)
+	}
+
+	breakerCooldown, err := time.ParseDuration(cfg.breakerCooldown)
+	if err != nil {
+		return external.WalletClientConfig{}, fmt.Errorf("failed to parse breaker cooldown :%w", err)
+	}
+
+	return external.WalletClientConfig{
+		BaseURL:          cfg.addr + cfg.basePath,
+		Timeout:          timeout,
+		MaxAttempts:      cfg.maxAttempts,
+		RetryBase:        retryBase,
+		RetryMax:         retryMax,
+		BreakerThreshold: cfg.breakerThreshold,
+		BreakerCooldown:  breakerCooldown,
+	}, nil
+}
+
 func ConnectDatabase(cfg DBConfig, logger *logrus.Logger) (*pgxpool.Pool, error) {
 	conn, err := db.New(cfg.addr, cfg.maxOpenConns, cfg.maxIdleConns, cfg.maxIdleTime)
 	if err != nil {
@@ -55,34 +226,380 @@ func ConnectDatabase(cfg DBConfig, logger *logrus.Logger) (*pgxpool.Pool, error)
 	return conn, nil
 }
 
-func SetupHTTPApplication() (*application, error) {
+// NewExternal creates the clients of the other services from clients, which
+// already holds the gRPC client configs. The local auth modes need the JWKS
+// URL, issuer and audience, and load the JWKS up front. A failed load is only
+// logged since tokens fall back to the user service until a refresh succeeds.
+func NewExternal(cfg AuthConfig, clients external.Config, logger *logrus.Logger) (external.External, *auth.KeySet, error) {
+	clients.AuthMode = cfg.mode
+
+	switch cfg.mode {
+	case external.AuthModeRemote:
+		ext, err := external.NewExternal(clients)
+		return ext, nil, err
+	case external.AuthModeLocal, external.AuthModeHybrid:
+		if cfg.jwksURL == "" {
+			return external.External{}, nil, fmt.Errorf("auth mode %q needs AUTH_JWKS_URL", cfg.mode)
+		}
+		// a token issued by another issuer or for another service verifies
+		// against the same keys, iss and aud are what tell them apart
+		if cfg.iss == "" || cfg.aud == "" {
+			return external.External{}, nil, fmt.Errorf("auth mode %q needs JWT_ISS and JWT_AUD", cfg.mode)
+		}
+	default:
+		return external.External{}, nil, fmt.Errorf("unknown auth mode %q, use 'remote', 'local' or 'hybrid'", cfg.mode)
+	}
+
+	keys := auth.NewKeySet(cfg.jwksURL)
+	if err := keys.Refresh(context.Background()); err != nil {
+		logger.WithError(err).Error("failed to load jwks, validating tokens with the user service")
+	}
+
+	clients.Verifier = auth.NewVerifier(keys, cfg.iss, cfg.aud)
+	ext, err := external.NewExternal(clients)
+	if err != nil {
+		return external.External{}, nil, err
+	}
+
+	return ext, keys, nil
+}
+
+func NewPublisher(cfg EventConfig) (event.Publisher, error) {
+	switch cfg.broker {
+	case "nats":
+		return event.NewNATSPublisher(cfg.natsURL)
+	case "inprocess":
+		return event.NewBroker(), nil
+	default:
+		return nil, fmt.Errorf("unknown event broker %q, use 'inprocess' or 'nats'", cfg.broker)
+	}
+}
+
+// SetupApplication builds the application shared by the HTTP and gRPC servers.
+func SetupApplication() (*application, error) {
 	cfg, err := LoadConfig()
 	if err != nil {
 		cfg.logger.Fatal(err.Error())
 	}
 
+	sampleRatio, err := strconv.ParseFloat(cfg.tracing.sampleRatio, 64)
+	if err != nil {
+		cfg.logger.Fatalf("failed to parse tracing sample ratio :%v", err)
+	}
+
+	// before the database and the clients, which trace through the global
+	// provider and propagator
+	stopTracing, err := tracing.Setup(context.Background(), tracing.Config{
+		ServiceName:  cfg.tracing.serviceName,
+		Exporter:     cfg.tracing.exporter,
+		OTLPEndpoint: cfg.tracing.otlpEndpoint,
+		OTLPInsecure: cfg.tracing.otlpInsecure,
+		SampleRatio:  sampleRatio,
+	})
+	if err != nil {
+		cfg.logger.Fatalf("failed to setup tracing :%v", err)
+	}
+
 	conn, err := ConnectDatabase(cfg.db, cfg.logger)
 	if err != nil {
 		cfg.logger.Fatalf("failed to connected database :%v", err)
 	}
 
-	//auth := auth.NewJwt(cfg.auth.secret, cfg.auth.aud, cfg.auth.iss)
+	if err := metrics.RegisterPool(conn); err != nil {
+		cfg.logger.Fatalf("failed to register database pool metrics :%v", err)
+	}
+
+	clearingPeriod, err := time.ParseDuration(cfg.reward.clearingPeriod)
+	if err != nil {
+		cfg.logger.Fatalf("failed to parse reward clearing period :%v", err)
+	}
+
+	rewardInterval, err := time.ParseDuration(cfg.reward.workerInterval)
+	if err != nil {
+		cfg.logger.Fatalf("failed to parse reward worker interval :%v", err)
+	}
+
+	disputeReviewPeriod, err := time.ParseDuration(cfg.dispute.reviewPeriod)
+	if err != nil {
+		cfg.logger.Fatalf("failed to parse dispute review period :%v", err)
+	}
+
+	disputeInterval, err := time.ParseDuration(cfg.dispute.workerInterval)
+	if err != nil {
+		cfg.logger.Fatalf("failed to parse dispute worker interval :%v", err)
+	}
+
+	reconInterval, err := time.ParseDuration(cfg.recon.interval)
+	if err != nil {
+		cfg.logger.Fatalf("failed to parse reconciliation interval :%v", err)
+	}
+
+	webhookRetryBase, err := time.ParseDuration(cfg.webhook.retryBase)
+	if err != nil {
+		cfg.logger.Fatalf("failed to parse webhook retry base :%v", err)
+	}
+
+	webhookInterval, err := time.ParseDuration(cfg.webhook.workerInterval)
+	if err != nil {
+		cfg.logger.Fatalf("failed to parse webhook worker interval :%v", err)
+	}
+
+	eventRelayInterval, err := time.ParseDuration(cfg.event.relayInterval)
+	if err != nil {
+		cfg.logger.Fatalf("failed to parse event relay interval :%v", err)
+	}
+
+	notifRetryBase, err := time.ParseDuration(cfg.notif.retryBase)
+	if err != nil {
+		cfg.logger.Fatalf("failed to parse notification retry base :%v", err)
+	}
+
+	notifInterval, err := time.ParseDuration(cfg.notif.workerInterval)
+	if err != nil {
+		cfg.logger.Fatalf("failed to parse notification worker interval :%v", err)
+	}
+
+	notifTemplates, err := notification.LoadTemplates(cfg.notif.templatesFile)
+	if err != nil {
+		cfg.logger.Fatalf("failed to load notification templates :%v", err)
+	}
+
+	jwksRefreshInterval, err := time.ParseDuration(cfg.auth.jwksRefreshInterval)
+	if err != nil {
+		cfg.logger.Fatalf("failed to parse jwks refresh interval :%v", err)
+	}
+
+	tokenCacheTTL, err := time.ParseDuration(cfg.auth.cacheTTL)
+	if err != nil {
+		cfg.logger.Fatalf("failed to parse token cache ttl :%v", err)
+	}
+
+	tokenCacheStatsInterval, err := time.ParseDuration(cfg.auth.cacheStatsInterval)
+	if err != nil {
+		cfg.logger.Fatalf("failed to parse token cache stats interval :%v", err)
+	}
+
+	queueDepthInterval, err := time.ParseDuration(cfg.metrics.queueInterval)
+	if err != nil {
+		cfg.logger.Fatalf("failed to parse metrics queue interval :%v", err)
+	}
+
+	var tokenCache *auth.TokenCache
+	if cfg.auth.cacheSize > 0 {
+		tokenCache = auth.NewTokenCache(cfg.auth.cacheSize, tokenCacheTTL)
+	}
+
+	userService, err := NewGRPCClientConfig(cfg.clients.userService, cfg.clients)
+	if err != nil {
+		cfg.logger.Fatalf("failed to parse user service client config :%v", err)
+	}
+
+	notifService, err := NewGRPCClientConfig(cfg.clients.notifService, cfg.clients)
+	if err != nil {
+		cfg.logger.Fatalf("failed to parse notification service client config :%v", err)
+	}
+
+	wallet, err := NewWalletClientConfig(cfg.wallet)
+	if err != nil {
+		cfg.logger.Fatalf("failed to parse wallet client config :%v", err)
+	}
+
+	walletStatsInterval, err := time.ParseDuration(cfg.wallet.statsInterval)
+	if err != nil {
+		cfg.logger.Fatalf("failed to parse wallet stats interval :%v", err)
+	}
+
+	external, keys, err := NewExternal(cfg.auth, external.Config{
+		UserService:  userService,
+		NotifService: notifService,
+		Wallet:       wallet,
+		TokenCache:   tokenCache,
+	}, cfg.logger)
+	if err != nil {
+		cfg.logger.Fatalf("failed to create external clients :%v", err)
+	}
+
+	if err := metrics.RegisterWalletCircuit(func() bool {
+		return external.Wallet.Stats().CircuitOpen
+	}); err != nil {
+		cfg.logger.Fatalf("failed to register wallet metrics :%v", err)
+	}
+
+	publisher, err := NewPublisher(cfg.event)
+	if err != nil {
+		cfg.logger.Fatalf("failed to create event publisher :%v", err)
+	}
+
+	hub := stream.NewHub(conn, cfg.logger)
+
 	q := sqlc.New(conn)
 
-	handler := handler.NewHandler(q, conn)
+	service := service.NewService(q, conn, service.Config{
+		RewardClearingPeriod:    clearingPeriod,
+		WalletServiceToken:      cfg.wallet.serviceToken,
+		DisputeReviewPeriod:     disputeReviewPeriod,
+		BatchMaxItems:           cfg.batch.maxItems,
+		WebhookMaxAttempts:      cfg.webhook.maxAttempts,
+		WebhookRetryBase:        webhookRetryBase,
+		WebhookDisableAfter:     cfg.webhook.disableAfter,
+		NotificationWorkers:     cfg.notif.workers,
+		NotificationMaxAttempts: cfg.notif.maxAttempts,
+		NotificationRetryBase:   notifRetryBase,
+		NotificationTemplates:   notifTemplates,
+		EventMaxAttempts:        cfg.event.maxAttempts,
+		Publisher:               publisher,
+		Hub:                     hub,
+		External:                external,
+	})
+	handler := handler.NewHandler(service, external)
 
-	return &application{
-		config:  cfg,
-		handler: handler,
-	}, nil
+	workers := []*worker.Worker{
+		worker.New("reward-credit", rewardInterval, func(ctx context.Context) error {
+			credited, err := service.Reward.CreditDueRewards(ctx)
+			if credited > 0 {
+				cfg.logger.Infof("credited %d rewards", credited)
+			}
+			return err
+		}, cfg.logger),
+		worker.New("dispute-review", disputeInterval, func(ctx context.Context) error {
+			processed, err := service.Dispute.ProcessDisputes(ctx)
+			if processed > 0 {
+				cfg.logger.Infof("processed %d disputes", processed)
+			}
+			return err
+		}, cfg.logger),
+		// every run covers the window since the last completed one
+		worker.New("reconciliation", reconInterval, func(ctx context.Context) error {
+			report, err := service.Reconciliation.RunDue(ctx, time.Now(), reconInterval)
+			if err != nil || report == nil {
+				return err
+			}
+			cfg.logger.Infof("reconciliation run %d matched %d, found %d discrepancies", report.ID, report.MatchedCount, report.DiscrepancyCount)
+			return nil
+		}, cfg.logger),
+		worker.New("webhook-delivery", webhookInterval, func(ctx context.Context) error {
+			attempted, err := service.Webhook.DeliverDue(ctx)
+			if attempted > 0 {
+				cfg.logger.Infof("attempted %d webhook deliveries", attempted)
+			}
+			return err
+		}, cfg.logger),
+		worker.New("notification-delivery", notifInterval, func(ctx context.Context) error {
+			attempted, err := service.Notification.SendDue(ctx)
+			if attempted > 0 {
+				cfg.logger.Infof("attempted %d notifications", attempted)
+			}
+			return err
+		}, cfg.logger),
+		worker.New("event-relay", eventRelayInterval, func(ctx context.Context) error {
+			_, err := service.Event.PublishPending(ctx)
+			return err
+		}, cfg.logger),
+		worker.New("queue-depth", queueDepthInterval, func(ctx context.Context) error {
+			depths, err := q.GetQueueDepths(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to get queue depths :%w", err)
+			}
+			metrics.SetQueueDepth("notification", depths.Notifications)
+			metrics.SetQueueDepth("webhook_delivery", depths.WebhookDeliveries)
+			metrics.SetQueueDepth("event_outbox", depths.Events)
+			return nil
+		}, cfg.logger),
+		worker.New("wallet-stats", walletStatsInterval, func(ctx context.Context) error {
+			stats := external.Wallet.Stats()
+			for op, s := range stats.Operations {
+				cfg.logger.Infof("wallet %s: %d calls, %d attempts, %d succeeded, %d failed, %d unknown outcome, %d rejected, circuit open %v",
+					op, s.Calls, s.Attempts, s.Succeeded, s.Failed, s.UnknownOutcome, s.Rejected, stats.CircuitOpen)
+			}
+			return nil
+		}, cfg.logger),
+	}
+
+	if keys != nil {
+		workers = append(workers, worker.New("jwks-refresh", jwksRefreshInterval, keys.Refresh, cfg.logger))
+	}
+	if tokenCache != nil {
+		if err := metrics.RegisterTokenCache(tokenCache); err != nil {
+			cfg.logger.Fatalf("failed to register token cache metrics :%v", err)
+		}
+		workers = append(workers, worker.New("token-cache-stats", tokenCacheStatsInterval, func(ctx context.Context) error {
+			stats := tokenCache.Stats()
+			cfg.logger.Infof("token cache hit rate %.2f, %d entries, %d hits, %d misses, %d evictions, %d revocations",
+				stats.HitRate(), stats.Entries, stats.Hits, stats.Misses, stats.Evictions, stats.Revocations)
+			return nil
+		}, cfg.logger))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	app := &application{
+		config:      cfg,
+		handler:     handler,
+		service:     service,
+		external:    external,
+		tokenCache:  tokenCache,
+		workers:     workers,
+		hub:         hub,
+		db:          conn,
+		ctx:         ctx,
+		cancel:      cancel,
+		stopTracing: stopTracing,
+	}
+	app.http = app.mount()
+	app.admin = app.mountAdmin()
+	app.grpc = app.newGRPCServer()
+
+	return app, nil
 }
 
-func SetupGRPCApplication() (*application, error) {
-	cfg, err := LoadConfig()
-	if err != nil {
-		cfg.logger.Fatal(err.Error())
+// Shutdown stops accepting requests, waits up to timeout for the HTTP ones in
+// flight, then stops the background jobs, waits up to timeout for them and
+// closes the client connections and the database pool.
+func Shutdown(app *application, timeout time.Duration) {
+	app.config.logger.Info("shutting down")
+
+	done := make(chan struct{})
+	go func() {
+		app.grpc.GracefulStop()
+		close(done)
+	}()
+
+	if err := app.http.ShutdownWithTimeout(timeout); err != nil {
+		app.config.logger.WithError(err).Error("failed to shutdown http server")
+	}
+	if err := app.admin.ShutdownWithTimeout(timeout); err != nil {
+		app.config.logger.WithError(err).Error("failed to shutdown admin server")
+	}
+
+	// streams such as WatchTransactions never end on their own
+	select {
+	case <-done:
+	case <-time.After(timeout):
+		app.grpc.Stop()
+	}
+
+	// a worker stopped half way through a job still needs the clients and the
+	// database to finish it
+	app.cancel()
+	stopped := make(chan struct{})
+	go func() {
+		app.background.Wait()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(timeout):
+		app.config.logger.Error("background jobs still running after the shutdown timeout")
+	}
+
+	if err := app.external.Close(); err != nil {
+		app.config.logger.WithError(err).Error("failed to close external clients")
+	}
+	app.db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if err := app.stopTracing(ctx); err != nil {
+		app.config.logger.WithError(err).Error("failed to flush traces")
 	}
-	return &application{
-		config: cfg,
-	}, nil
 }
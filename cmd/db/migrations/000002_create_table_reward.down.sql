@@ -0,0 +1,10 @@
+DROP TABLE IF EXISTS reward;
+DROP TABLE IF EXISTS reward_campaign;
+
+DROP TYPE IF EXISTS reward_status;
+
+DELETE FROM transaction WHERE transaction_type = 'CASHBACK';
+ALTER TYPE transaction_type RENAME TO transaction_type_old;
+CREATE TYPE transaction_type AS ENUM ('TOPUP', 'PURCHASE', 'REFUND');
+ALTER TABLE transaction ALTER COLUMN transaction_type TYPE transaction_type USING transaction_type::text::transaction_type;
+DROP TYPE IF EXISTS transaction_type_old;
@@ -0,0 +1,32 @@
+ALTER TYPE transaction_type ADD VALUE IF NOT EXISTS 'CASHBACK';
+
+CREATE TYPE reward_status AS ENUM ('PENDING', 'CREDITED', 'CANCELLED', 'CLAWED_BACK');
+
+CREATE TABLE IF NOT EXISTS reward_campaign (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    percentage DECIMAL(5, 2) NOT NULL,
+    max_per_user DECIMAL(10, 2) NOT NULL,
+    merchant_categories TEXT[] NOT NULL DEFAULT '{}',
+    starts_at TIMESTAMP(0) NOT NULL,
+    ends_at TIMESTAMP(0) NOT NULL,
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP(0) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP(0) NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS reward (
+    id SERIAL PRIMARY KEY,
+    campaign_id INT NOT NULL REFERENCES reward_campaign(id),
+    user_id INT NOT NULL,
+    transaction_reference VARCHAR(255) NOT NULL,
+    credit_reference VARCHAR(255),
+    amount DECIMAL(10, 2) NOT NULL,
+    reward_status reward_status NOT NULL,
+    clear_at TIMESTAMP(0) NOT NULL,
+    created_at TIMESTAMP(0) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP(0) NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_reward_transaction_reference ON reward (transaction_reference);
+CREATE INDEX IF NOT EXISTS idx_reward_status_clear_at ON reward (reward_status, clear_at);
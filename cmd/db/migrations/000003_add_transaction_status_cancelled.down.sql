@@ -0,0 +1,6 @@
+UPDATE transaction SET transaction_status = 'FAILED' WHERE transaction_status = 'CANCELLED';
+
+ALTER TYPE transaction_status RENAME TO transaction_status_old;
+CREATE TYPE transaction_status AS ENUM ('PENDING', 'SUCCESS', 'FAILED', 'REVERSED');
+ALTER TABLE transaction ALTER COLUMN transaction_status TYPE transaction_status USING transaction_status::text::transaction_status;
+DROP TYPE IF EXISTS transaction_status_old;
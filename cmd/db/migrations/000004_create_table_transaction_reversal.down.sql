@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS transaction_reversal;
+
+DELETE FROM transaction WHERE transaction_type = 'REVERSAL';
+ALTER TYPE transaction_type RENAME TO transaction_type_old;
+CREATE TYPE transaction_type AS ENUM ('TOPUP', 'PURCHASE', 'REFUND', 'CASHBACK');
+ALTER TABLE transaction ALTER COLUMN transaction_type TYPE transaction_type USING transaction_type::text::transaction_type;
+DROP TYPE IF EXISTS transaction_type_old;
@@ -0,0 +1,9 @@
+ALTER TYPE transaction_type ADD VALUE IF NOT EXISTS 'REVERSAL';
+
+CREATE TABLE IF NOT EXISTS transaction_reversal (
+    id SERIAL PRIMARY KEY,
+    transaction_reference VARCHAR(255) NOT NULL UNIQUE,
+    reversal_reference VARCHAR(255) NOT NULL UNIQUE,
+    amount DECIMAL(10, 2) NOT NULL,
+    created_at TIMESTAMP(0) NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
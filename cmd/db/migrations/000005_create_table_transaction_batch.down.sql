@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS transaction_batch_item;
+DROP TABLE IF EXISTS transaction_batch;
+
+DROP TYPE IF EXISTS batch_item_status;
+DROP TYPE IF EXISTS batch_status;
@@ -0,0 +1,27 @@
+CREATE TYPE batch_status AS ENUM ('COMPLETED', 'PARTIAL', 'FAILED');
+CREATE TYPE batch_item_status AS ENUM ('CREATED', 'REJECTED');
+
+CREATE TABLE IF NOT EXISTS transaction_batch (
+    id SERIAL PRIMARY KEY,
+    batch_id VARCHAR(64) NOT NULL UNIQUE,
+    user_id INT NOT NULL,
+    all_or_nothing BOOLEAN NOT NULL DEFAULT FALSE,
+    total_items INT NOT NULL,
+    created_items INT NOT NULL,
+    rejected_items INT NOT NULL,
+    batch_status batch_status NOT NULL,
+    created_at TIMESTAMP(0) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP(0) NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS transaction_batch_item (
+    id SERIAL PRIMARY KEY,
+    batch_id VARCHAR(64) NOT NULL REFERENCES transaction_batch(batch_id) ON DELETE CASCADE,
+    item_index INT NOT NULL,
+    reference VARCHAR(255),
+    item_status batch_item_status NOT NULL,
+    error TEXT,
+    created_at TIMESTAMP(0) NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_transaction_batch_item_batch_id ON transaction_batch_item (batch_id, item_index);
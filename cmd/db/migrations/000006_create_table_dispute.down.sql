@@ -0,0 +1,10 @@
+DROP TABLE IF EXISTS dispute_evidence;
+DROP TABLE IF EXISTS dispute;
+
+DROP TYPE IF EXISTS dispute_status;
+
+DELETE FROM transaction WHERE transaction_type = 'DISPUTE_CREDIT';
+ALTER TYPE transaction_type RENAME TO transaction_type_old;
+CREATE TYPE transaction_type AS ENUM ('TOPUP', 'PURCHASE', 'REFUND', 'CASHBACK', 'REVERSAL');
+ALTER TABLE transaction ALTER COLUMN transaction_type TYPE transaction_type USING transaction_type::text::transaction_type;
+DROP TYPE IF EXISTS transaction_type_old;
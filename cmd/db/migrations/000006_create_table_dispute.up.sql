@@ -0,0 +1,33 @@
+ALTER TYPE transaction_type ADD VALUE IF NOT EXISTS 'DISPUTE_CREDIT';
+
+CREATE TYPE dispute_status AS ENUM ('OPENED', 'UNDER_REVIEW', 'WON', 'LOST');
+
+CREATE TABLE IF NOT EXISTS dispute (
+    id SERIAL PRIMARY KEY,
+    transaction_reference VARCHAR(255) NOT NULL,
+    user_id INT NOT NULL,
+    amount DECIMAL(10, 2) NOT NULL,
+    reason TEXT NOT NULL,
+    dispute_status dispute_status NOT NULL,
+    credit_reference VARCHAR(255),
+    resolution TEXT,
+    deadline_at TIMESTAMP(0) NOT NULL,
+    resolved_at TIMESTAMP(0),
+    created_at TIMESTAMP(0) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP(0) NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- a purchase can only have one dispute in progress at a time
+CREATE UNIQUE INDEX IF NOT EXISTS idx_dispute_active_reference ON dispute (transaction_reference)
+WHERE dispute_status IN ('OPENED', 'UNDER_REVIEW');
+CREATE INDEX IF NOT EXISTS idx_dispute_user_id ON dispute (user_id, created_at);
+
+CREATE TABLE IF NOT EXISTS dispute_evidence (
+    id SERIAL PRIMARY KEY,
+    dispute_id INT NOT NULL REFERENCES dispute(id) ON DELETE CASCADE,
+    author VARCHAR(32) NOT NULL,
+    message TEXT NOT NULL,
+    attachment_name VARCHAR(255),
+    attachment_url VARCHAR(1024),
+    created_at TIMESTAMP(0) NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
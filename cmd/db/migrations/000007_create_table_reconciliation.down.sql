@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_transaction_reference;
+DROP INDEX IF EXISTS idx_transaction_created_at;
+
+DROP TABLE IF EXISTS reconciliation_discrepancy;
+DROP TABLE IF EXISTS reconciliation_run;
+
+DROP TYPE IF EXISTS discrepancy_category;
+DROP TYPE IF EXISTS reconciliation_status;
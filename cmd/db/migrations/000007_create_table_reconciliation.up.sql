@@ -0,0 +1,30 @@
+CREATE TYPE reconciliation_status AS ENUM ('RUNNING', 'COMPLETED', 'FAILED');
+CREATE TYPE discrepancy_category AS ENUM ('MISSING', 'ORPHAN', 'AMOUNT_MISMATCH', 'STATUS_MISMATCH');
+
+CREATE TABLE IF NOT EXISTS reconciliation_run (
+    id SERIAL PRIMARY KEY,
+    window_start TIMESTAMP(0) NOT NULL,
+    window_end TIMESTAMP(0) NOT NULL,
+    reconciliation_status reconciliation_status NOT NULL,
+    matched_count INT NOT NULL DEFAULT 0,
+    discrepancy_count INT NOT NULL DEFAULT 0,
+    error TEXT,
+    created_at TIMESTAMP(0) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    finished_at TIMESTAMP(0)
+);
+
+CREATE TABLE IF NOT EXISTS reconciliation_discrepancy (
+    id SERIAL PRIMARY KEY,
+    run_id INT NOT NULL REFERENCES reconciliation_run(id) ON DELETE CASCADE,
+    category discrepancy_category NOT NULL,
+    reference VARCHAR(255) NOT NULL,
+    transaction_amount DECIMAL(10, 2),
+    wallet_amount DECIMAL(10, 2),
+    transaction_status VARCHAR(32),
+    suggested_repair TEXT NOT NULL,
+    created_at TIMESTAMP(0) NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_reconciliation_discrepancy_run_id ON reconciliation_discrepancy (run_id);
+CREATE INDEX IF NOT EXISTS idx_transaction_created_at ON transaction (created_at);
+CREATE INDEX IF NOT EXISTS idx_transaction_reference ON transaction (reference);
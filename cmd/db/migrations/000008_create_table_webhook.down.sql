@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS webhook_delivery_attempt;
+DROP TABLE IF EXISTS webhook_delivery;
+DROP TABLE IF EXISTS webhook_endpoint;
+
+DROP TYPE IF EXISTS webhook_delivery_status;
@@ -0,0 +1,44 @@
+CREATE TYPE webhook_delivery_status AS ENUM ('PENDING', 'DELIVERED', 'FAILED');
+
+CREATE TABLE IF NOT EXISTS webhook_endpoint (
+    id SERIAL PRIMARY KEY,
+    user_id INT NOT NULL,
+    url VARCHAR(1024) NOT NULL,
+    secret VARCHAR(255) NOT NULL,
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    consecutive_failures INT NOT NULL DEFAULT 0,
+    disabled_at TIMESTAMP(0),
+    created_at TIMESTAMP(0) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP(0) NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_endpoint_user_id ON webhook_endpoint (user_id);
+
+CREATE TABLE IF NOT EXISTS webhook_delivery (
+    id SERIAL PRIMARY KEY,
+    endpoint_id INT NOT NULL REFERENCES webhook_endpoint(id) ON DELETE CASCADE,
+    event VARCHAR(64) NOT NULL,
+    reference VARCHAR(255) NOT NULL,
+    payload TEXT NOT NULL,
+    delivery_status webhook_delivery_status NOT NULL DEFAULT 'PENDING',
+    attempts INT NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP(0) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_status_code INT,
+    last_error TEXT,
+    delivered_at TIMESTAMP(0),
+    created_at TIMESTAMP(0) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP(0) NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_delivery_due ON webhook_delivery (next_attempt_at)
+WHERE delivery_status = 'PENDING';
+CREATE INDEX IF NOT EXISTS idx_webhook_delivery_endpoint_id ON webhook_delivery (endpoint_id, created_at);
+
+CREATE TABLE IF NOT EXISTS webhook_delivery_attempt (
+    id SERIAL PRIMARY KEY,
+    delivery_id INT NOT NULL REFERENCES webhook_delivery(id) ON DELETE CASCADE,
+    status_code INT,
+    error TEXT,
+    duration_ms INT NOT NULL,
+    created_at TIMESTAMP(0) NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS event_outbox (
+    id BIGSERIAL PRIMARY KEY,
+    reference VARCHAR(255) NOT NULL,
+    event_type VARCHAR(64) NOT NULL,
+    payload BYTEA NOT NULL,
+    attempts INT NOT NULL DEFAULT 0,
+    last_error TEXT,
+    published_at TIMESTAMP(0),
+    created_at TIMESTAMP(0) NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_event_outbox_unpublished ON event_outbox (id)
+WHERE published_at IS NULL;
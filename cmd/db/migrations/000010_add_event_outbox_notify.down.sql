@@ -0,0 +1,5 @@
+DROP TRIGGER IF EXISTS event_outbox_notify ON event_outbox;
+DROP FUNCTION IF EXISTS notify_transaction_event();
+
+DROP INDEX IF EXISTS idx_event_outbox_user_id;
+ALTER TABLE event_outbox DROP COLUMN IF EXISTS user_id;
@@ -0,0 +1,15 @@
+ALTER TABLE event_outbox ADD COLUMN IF NOT EXISTS user_id INT;
+
+CREATE INDEX IF NOT EXISTS idx_event_outbox_user_id ON event_outbox (user_id, id);
+
+-- wakes up the transaction streams of every instance, the payload only says
+-- which user has new events so it stays well under the NOTIFY size limit
+CREATE OR REPLACE FUNCTION notify_transaction_event() RETURNS trigger AS $$
+BEGIN
+    PERFORM pg_notify('transaction_events', json_build_object('id', NEW.id, 'user_id', NEW.user_id)::text);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER event_outbox_notify AFTER INSERT ON event_outbox
+FOR EACH ROW EXECUTE FUNCTION notify_transaction_event();
@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS admin_audit_log (
+    id SERIAL PRIMARY KEY,
+    admin_id INT NOT NULL,
+    admin_email VARCHAR(255) NOT NULL,
+    action VARCHAR(64) NOT NULL,
+    reference VARCHAR(255),
+    reason TEXT,
+    detail TEXT,
+    succeeded BOOLEAN NOT NULL,
+    error TEXT,
+    created_at TIMESTAMP(0) NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_admin_audit_log_reference ON admin_audit_log (reference);
+CREATE INDEX IF NOT EXISTS idx_admin_audit_log_admin_id ON admin_audit_log (admin_id);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS notification_queue;
+
+DROP TYPE IF EXISTS notification_status;
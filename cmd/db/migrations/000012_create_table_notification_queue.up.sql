@@ -0,0 +1,20 @@
+CREATE TYPE notification_status AS ENUM ('PENDING', 'SENT', 'DEAD');
+
+CREATE TABLE IF NOT EXISTS notification_queue (
+    id SERIAL PRIMARY KEY,
+    reference VARCHAR(255) NOT NULL,
+    recipient VARCHAR(255) NOT NULL,
+    template_name VARCHAR(128) NOT NULL,
+    placeholder TEXT NOT NULL,
+    notification_status notification_status NOT NULL DEFAULT 'PENDING',
+    attempts INT NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP(0) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_error TEXT,
+    sent_at TIMESTAMP(0),
+    created_at TIMESTAMP(0) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP(0) NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_notification_queue_due ON notification_queue (next_attempt_at)
+WHERE notification_status = 'PENDING';
+CREATE INDEX IF NOT EXISTS idx_notification_queue_reference ON notification_queue (reference);
@@ -0,0 +1,5 @@
+CREATE TABLE IF NOT EXISTS notification_contact (
+    user_id INT PRIMARY KEY,
+    email VARCHAR(255) NOT NULL,
+    updated_at TIMESTAMP(0) NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,10 @@
+ALTER TABLE notification_queue
+    DROP COLUMN IF EXISTS channel,
+    DROP COLUMN IF EXISTS amount,
+    DROP COLUMN IF EXISTS event,
+    DROP COLUMN IF EXISTS user_id;
+
+-- enum values can't be dropped, SKIPPED rows are kept as DEAD
+UPDATE notification_queue SET notification_status = 'DEAD' WHERE notification_status = 'SKIPPED';
+
+DROP TABLE IF EXISTS notification_preference;
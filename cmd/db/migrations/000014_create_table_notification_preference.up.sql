@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS notification_preference (
+    user_id INT PRIMARY KEY,
+    disabled_events TEXT[] NOT NULL DEFAULT '{}',
+    channels TEXT[] NOT NULL DEFAULT '{email}',
+    -- minutes after midnight in timezone, both NULL when there are no quiet hours
+    quiet_start SMALLINT,
+    quiet_end SMALLINT,
+    timezone VARCHAR(64) NOT NULL DEFAULT 'UTC',
+    min_amount DECIMAL(10, 2) NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP(0) NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TYPE notification_status ADD VALUE IF NOT EXISTS 'SKIPPED';
+
+ALTER TABLE notification_queue
+    ADD COLUMN IF NOT EXISTS user_id INT,
+    ADD COLUMN IF NOT EXISTS event VARCHAR(64),
+    ADD COLUMN IF NOT EXISTS amount DECIMAL(10, 2),
+    ADD COLUMN IF NOT EXISTS channel VARCHAR(16) NOT NULL DEFAULT 'email';
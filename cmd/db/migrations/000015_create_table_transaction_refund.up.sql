@@ -0,0 +1,9 @@
+-- a purchase is refunded at most once, refunds created before this table are
+-- not linked
+CREATE TABLE IF NOT EXISTS transaction_refund (
+    id SERIAL PRIMARY KEY,
+    transaction_reference VARCHAR(255) NOT NULL UNIQUE,
+    refund_reference VARCHAR(255) NOT NULL UNIQUE,
+    amount DECIMAL(10, 2) NOT NULL,
+    created_at TIMESTAMP(0) NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
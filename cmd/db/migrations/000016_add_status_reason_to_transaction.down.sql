@@ -0,0 +1 @@
+ALTER TABLE transaction DROP COLUMN IF EXISTS status_reason;
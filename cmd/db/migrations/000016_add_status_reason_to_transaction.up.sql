@@ -0,0 +1,3 @@
+-- why the status was last changed, e.g. a cancel or force reason. Kept out of
+-- additional_info, which the reasons would overflow.
+ALTER TABLE transaction ADD COLUMN IF NOT EXISTS status_reason VARCHAR(1000);
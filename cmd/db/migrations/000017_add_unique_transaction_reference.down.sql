@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_transaction_reference;
+CREATE INDEX IF NOT EXISTS idx_transaction_reference ON transaction (reference);
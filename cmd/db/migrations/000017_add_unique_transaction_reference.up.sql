@@ -0,0 +1,4 @@
+-- the wallet's idempotency keys are built from the reference, two
+-- transactions must never share one
+DROP INDEX IF EXISTS idx_transaction_reference;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_transaction_reference ON transaction (reference);
@@ -0,0 +1 @@
+UPDATE reward SET credit_reference = NULL WHERE reward_status = 'PENDING';
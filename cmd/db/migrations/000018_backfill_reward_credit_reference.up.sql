@@ -0,0 +1,5 @@
+-- rewards get the reference of their cashback transaction when they accrue,
+-- give the ones still waiting to be credited one as well
+UPDATE reward
+SET credit_reference = user_id || 'CASHBACK' || to_char(CURRENT_TIMESTAMP, 'YYYYMMDDHH24MISS') || upper(substr(md5(random()::text || id::text), 1, 12))
+WHERE reward_status = 'PENDING' AND credit_reference IS NULL;
@@ -0,0 +1 @@
+-- the backfilled contacts can't be told apart from the remembered ones
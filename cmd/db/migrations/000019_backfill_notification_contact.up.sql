@@ -0,0 +1,8 @@
+-- contacts are only remembered from requests made after notification_contact
+-- was added, take the latest email notified to each user before that
+INSERT INTO notification_contact (user_id, email)
+SELECT DISTINCT ON (user_id) user_id, recipient
+FROM notification_queue
+WHERE user_id IS NOT NULL AND channel = 'email' AND recipient <> ''
+ORDER BY user_id, id DESC
+ON CONFLICT (user_id) DO NOTHING;
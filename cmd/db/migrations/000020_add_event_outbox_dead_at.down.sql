@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_event_outbox_unpublished;
+CREATE INDEX IF NOT EXISTS idx_event_outbox_unpublished ON event_outbox (id)
+WHERE published_at IS NULL;
+
+ALTER TABLE event_outbox DROP COLUMN IF EXISTS dead_at;
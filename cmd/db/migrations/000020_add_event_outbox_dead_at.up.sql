@@ -0,0 +1,7 @@
+-- an event that keeps failing is set aside instead of holding back the later
+-- events of its reference forever
+ALTER TABLE event_outbox ADD COLUMN IF NOT EXISTS dead_at TIMESTAMP(0);
+
+DROP INDEX IF EXISTS idx_event_outbox_unpublished;
+CREATE INDEX IF NOT EXISTS idx_event_outbox_unpublished ON event_outbox (id)
+WHERE published_at IS NULL AND dead_at IS NULL;
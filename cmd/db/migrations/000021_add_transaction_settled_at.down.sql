@@ -0,0 +1,5 @@
+DROP TRIGGER IF EXISTS transaction_settled_at ON transaction;
+DROP FUNCTION IF EXISTS set_transaction_settled_at();
+
+DROP INDEX IF EXISTS idx_transaction_settled_at;
+ALTER TABLE transaction DROP COLUMN IF EXISTS settled_at;
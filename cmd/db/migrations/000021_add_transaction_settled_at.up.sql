@@ -0,0 +1,24 @@
+-- when the transaction reached SUCCESS, i.e. when the wallet was moved.
+-- Reconciliation matches wallet entries on it, a topup retried to SUCCESS days
+-- after it was created is settled in the window its wallet entry falls in.
+ALTER TABLE transaction ADD COLUMN IF NOT EXISTS settled_at TIMESTAMP(0);
+
+-- the last update is the closest to the settlement of the existing rows
+UPDATE transaction SET settled_at = updated_at
+WHERE transaction_status IN ('SUCCESS', 'REVERSED') AND settled_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_transaction_settled_at ON transaction (settled_at);
+
+-- set by the database so every insert, copy and status update of a
+-- transaction reaching SUCCESS stamps it, whichever query wrote it
+CREATE OR REPLACE FUNCTION set_transaction_settled_at() RETURNS trigger AS $$
+BEGIN
+    IF NEW.transaction_status = 'SUCCESS' AND NEW.settled_at IS NULL THEN
+        NEW.settled_at := CURRENT_TIMESTAMP;
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER transaction_settled_at BEFORE INSERT OR UPDATE OF transaction_status ON transaction
+FOR EACH ROW EXECUTE FUNCTION set_transaction_settled_at();
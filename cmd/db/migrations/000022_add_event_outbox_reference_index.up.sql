@@ -0,0 +1 @@
+CREATE INDEX IF NOT EXISTS idx_event_outbox_reference ON event_outbox (reference);
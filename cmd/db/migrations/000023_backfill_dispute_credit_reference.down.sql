@@ -0,0 +1 @@
+UPDATE dispute SET credit_reference = NULL WHERE dispute_status = 'OPENED';
@@ -0,0 +1,5 @@
+-- disputes get the reference of their provisional credit when they open,
+-- give the ones still waiting to be credited one as well
+UPDATE dispute
+SET credit_reference = user_id || 'DISPUTE_CREDIT' || to_char(CURRENT_TIMESTAMP, 'YYYYMMDDHH24MISS') || upper(substr(md5(random()::text || id::text), 1, 12))
+WHERE dispute_status = 'OPENED' AND credit_reference IS NULL;
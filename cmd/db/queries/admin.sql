@@ -0,0 +1,23 @@
+-- name: SearchTransactions :many
+SELECT id, user_id, amount, transaction_type, transaction_status, reference, description, additional_info, created_at, updated_at, status_reason, settled_at
+FROM transaction
+WHERE (sqlc.arg(user_id)::int = 0 OR user_id = sqlc.arg(user_id))
+AND (sqlc.arg(reference)::text = '' OR reference ILIKE '%' || sqlc.arg(reference) || '%')
+AND (sqlc.arg(transaction_type)::text = '' OR transaction_type::text = sqlc.arg(transaction_type))
+AND (sqlc.arg(transaction_status)::text = '' OR transaction_status::text = sqlc.arg(transaction_status))
+AND (sqlc.arg(created_from)::timestamp IS NULL OR created_at >= sqlc.arg(created_from))
+AND (sqlc.arg(created_to)::timestamp IS NULL OR created_at < sqlc.arg(created_to))
+ORDER BY id DESC
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: CreateAdminAuditLog :exec
+INSERT INTO admin_audit_log (admin_id, admin_email, action, reference, reason, detail, succeeded, error)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8);
+
+-- name: GetAdminAuditLogs :many
+SELECT id, admin_id, admin_email, action, reference, reason, detail, succeeded, error, created_at
+FROM admin_audit_log
+WHERE (sqlc.arg(reference)::text = '' OR reference = sqlc.arg(reference))
+AND (sqlc.arg(admin_id)::int = 0 OR admin_id = sqlc.arg(admin_id))
+ORDER BY id DESC
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
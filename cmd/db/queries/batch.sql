@@ -0,0 +1,19 @@
+-- name: CreateTransactionBatch :one
+INSERT INTO transaction_batch (batch_id, user_id, all_or_nothing, total_items, created_items, rejected_items, batch_status)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING id, batch_id, user_id, all_or_nothing, total_items, created_items, rejected_items, batch_status, created_at, updated_at;
+
+-- name: CreateTransactionBatchItems :copyfrom
+INSERT INTO transaction_batch_item (batch_id, item_index, reference, item_status, error)
+VALUES ($1, $2, $3, $4, $5);
+
+-- name: GetTransactionBatchByBatchIdAndUserId :one
+SELECT id, batch_id, user_id, all_or_nothing, total_items, created_items, rejected_items, batch_status, created_at, updated_at
+FROM transaction_batch
+WHERE batch_id = $1 AND user_id = $2;
+
+-- name: GetTransactionBatchItems :many
+SELECT id, batch_id, item_index, reference, item_status, error, created_at
+FROM transaction_batch_item
+WHERE batch_id = $1
+ORDER BY item_index;
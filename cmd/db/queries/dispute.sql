@@ -0,0 +1,59 @@
+-- name: CreateDispute :one
+INSERT INTO dispute (transaction_reference, user_id, amount, reason, dispute_status, credit_reference, deadline_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING id, transaction_reference, user_id, amount, reason, dispute_status, credit_reference, resolution, deadline_at, resolved_at, created_at, updated_at;
+
+-- name: GetDisputeByIdAndUserId :one
+SELECT id, transaction_reference, user_id, amount, reason, dispute_status, credit_reference, resolution, deadline_at, resolved_at, created_at, updated_at
+FROM dispute
+WHERE id = $1 AND user_id = $2;
+
+-- name: GetDisputeByIdForUpdate :one
+SELECT id, transaction_reference, user_id, amount, reason, dispute_status, credit_reference, resolution, deadline_at, resolved_at, created_at, updated_at
+FROM dispute
+WHERE id = $1
+FOR UPDATE;
+
+-- name: GetDisputesByUserId :many
+SELECT id, transaction_reference, user_id, amount, reason, dispute_status, credit_reference, resolution, deadline_at, resolved_at, created_at, updated_at
+FROM dispute
+WHERE user_id = $1
+ORDER BY created_at DESC
+LIMIT $2 OFFSET $3;
+
+-- name: CountActiveDisputesByTransactionReference :one
+SELECT COUNT(*)
+FROM dispute
+WHERE transaction_reference = $1 AND dispute_status IN ('OPENED', 'UNDER_REVIEW');
+
+-- name: GetOpenedDisputes :many
+SELECT id, transaction_reference, user_id, amount, reason, dispute_status, credit_reference, resolution, deadline_at, resolved_at, created_at, updated_at
+FROM dispute
+WHERE dispute_status = 'OPENED'
+ORDER BY created_at
+LIMIT $1
+FOR UPDATE SKIP LOCKED;
+
+-- name: GetOverdueDisputes :many
+SELECT id, transaction_reference, user_id, amount, reason, dispute_status, credit_reference, resolution, deadline_at, resolved_at, created_at, updated_at
+FROM dispute
+WHERE dispute_status = 'UNDER_REVIEW' AND deadline_at <= $1
+ORDER BY deadline_at
+LIMIT $2
+FOR UPDATE SKIP LOCKED;
+
+-- name: UpdateDisputeStatus :one
+UPDATE dispute SET dispute_status = $2, credit_reference = $3, resolution = $4, resolved_at = $5, updated_at = CURRENT_TIMESTAMP
+WHERE id = $1
+RETURNING dispute_status;
+
+-- name: CreateDisputeEvidence :one
+INSERT INTO dispute_evidence (dispute_id, author, message, attachment_name, attachment_url)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING id, dispute_id, author, message, attachment_name, attachment_url, created_at;
+
+-- name: GetDisputeEvidence :many
+SELECT id, dispute_id, author, message, attachment_name, attachment_url, created_at
+FROM dispute_evidence
+WHERE dispute_id = $1
+ORDER BY created_at, id;
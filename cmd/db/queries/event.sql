@@ -0,0 +1,47 @@
+-- name: CreateOutboxEvent :exec
+INSERT INTO event_outbox (reference, event_type, payload, user_id)
+VALUES ($1, $2, $3, $4);
+
+-- name: CreateOutboxEvents :copyfrom
+INSERT INTO event_outbox (reference, event_type, payload, user_id)
+VALUES ($1, $2, $3, $4);
+
+-- name: TryLockEventRelay :one
+-- only one relay publishes at a time, which keeps the events of a reference in order
+SELECT pg_try_advisory_xact_lock(7301);
+
+-- name: GetUnpublishedEvents :many
+SELECT id, reference, event_type, payload, attempts, last_error, published_at, created_at, user_id, dead_at
+FROM event_outbox
+WHERE published_at IS NULL AND dead_at IS NULL
+ORDER BY id
+LIMIT $1;
+
+-- name: MarkEventPublished :exec
+UPDATE event_outbox
+SET published_at = CURRENT_TIMESTAMP, attempts = attempts + 1, last_error = NULL
+WHERE id = $1;
+
+-- name: MarkEventFailed :exec
+-- an event failing max_attempts times is moved out of the relay's way
+UPDATE event_outbox
+SET attempts = attempts + 1, last_error = sqlc.arg(last_error),
+    dead_at = CASE WHEN attempts + 1 >= sqlc.arg(max_attempts)::int THEN CURRENT_TIMESTAMP END
+WHERE id = sqlc.arg(id);
+
+-- name: GetUserEventsAfter :many
+-- events written within the overlap are read again, an event whose tx commits
+-- late can have a lower id than one already read. The seen ids are skipped.
+SELECT id, reference, event_type, payload, attempts, last_error, published_at, created_at, user_id, dead_at
+FROM event_outbox
+WHERE user_id = sqlc.arg(user_id) AND event_type = sqlc.arg(event_type)
+AND (id > sqlc.arg(after_id) OR created_at >= CURRENT_TIMESTAMP - make_interval(secs => sqlc.arg(overlap_seconds)::int))
+AND NOT (id = ANY(sqlc.arg(seen)::bigint[]))
+ORDER BY id
+LIMIT sqlc.arg('limit');
+
+-- name: GetEventsByReference :many
+SELECT id, reference, event_type, payload, attempts, last_error, published_at, created_at, user_id, dead_at
+FROM event_outbox
+WHERE reference = $1
+ORDER BY id;
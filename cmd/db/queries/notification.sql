@@ -0,0 +1,50 @@
+-- name: UpsertNotificationContact :exec
+INSERT INTO notification_contact (user_id, email)
+VALUES ($1, $2)
+ON CONFLICT (user_id) DO UPDATE
+SET email = EXCLUDED.email, updated_at = CURRENT_TIMESTAMP;
+
+-- name: EnqueueNotification :execrows
+INSERT INTO notification_queue (reference, recipient, template_name, placeholder, user_id, event, amount, channel)
+SELECT sqlc.arg(reference)::text, COALESCE(c.email, ''), sqlc.arg(template_name)::text, sqlc.arg(placeholder)::text, u.user_id, sqlc.arg(event)::text, sqlc.arg(amount)::decimal, ch.channel
+FROM (SELECT sqlc.arg(user_id)::int AS user_id) u
+LEFT JOIN notification_contact c ON c.user_id = u.user_id
+LEFT JOIN notification_preference p ON p.user_id = u.user_id
+CROSS JOIN LATERAL unnest(COALESCE(p.channels, ARRAY['email'])) AS ch(channel)
+WHERE ch.channel <> 'email' OR c.email IS NOT NULL;
+
+-- name: GetDueNotifications :many
+SELECT id, reference, recipient, template_name, placeholder, notification_status, attempts, next_attempt_at, last_error, sent_at, created_at, updated_at, user_id, event, amount, channel
+FROM notification_queue
+WHERE notification_status = 'PENDING' AND next_attempt_at <= $1
+ORDER BY next_attempt_at
+LIMIT $2
+FOR UPDATE SKIP LOCKED;
+
+-- name: LeaseNotification :exec
+UPDATE notification_queue
+SET next_attempt_at = $2, updated_at = CURRENT_TIMESTAMP
+WHERE id = $1;
+
+-- name: UpdateNotification :exec
+UPDATE notification_queue
+SET notification_status = $2, attempts = $3, next_attempt_at = $4, last_error = $5, sent_at = $6, updated_at = CURRENT_TIMESTAMP
+WHERE id = $1;
+
+-- name: GetNotificationPreference :one
+SELECT user_id, disabled_events, channels, quiet_start, quiet_end, timezone, min_amount, updated_at
+FROM notification_preference
+WHERE user_id = $1;
+
+-- name: UpsertNotificationPreference :one
+INSERT INTO notification_preference (user_id, disabled_events, channels, quiet_start, quiet_end, timezone, min_amount)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+ON CONFLICT (user_id) DO UPDATE
+SET disabled_events = EXCLUDED.disabled_events,
+    channels = EXCLUDED.channels,
+    quiet_start = EXCLUDED.quiet_start,
+    quiet_end = EXCLUDED.quiet_end,
+    timezone = EXCLUDED.timezone,
+    min_amount = EXCLUDED.min_amount,
+    updated_at = CURRENT_TIMESTAMP
+RETURNING user_id, disabled_events, channels, quiet_start, quiet_end, timezone, min_amount, updated_at;
@@ -0,0 +1,6 @@
+-- name: GetQueueDepths :one
+-- jobs waiting for the background workers, retries scheduled later included
+SELECT
+    (SELECT COUNT(*) FROM notification_queue WHERE notification_status = 'PENDING')::bigint AS notifications,
+    (SELECT COUNT(*) FROM webhook_delivery WHERE delivery_status = 'PENDING')::bigint AS webhook_deliveries,
+    (SELECT COUNT(*) FROM event_outbox WHERE published_at IS NULL AND dead_at IS NULL)::bigint AS events;
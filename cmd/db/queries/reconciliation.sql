@@ -0,0 +1,42 @@
+-- name: CreateReconciliationRun :one
+INSERT INTO reconciliation_run (window_start, window_end, reconciliation_status)
+VALUES ($1, $2, $3)
+RETURNING id, window_start, window_end, reconciliation_status, matched_count, discrepancy_count, error, created_at, finished_at;
+
+-- name: FinishReconciliationRun :one
+UPDATE reconciliation_run
+SET reconciliation_status = $2, matched_count = $3, discrepancy_count = $4, error = $5, finished_at = CURRENT_TIMESTAMP
+WHERE id = $1
+RETURNING id, window_start, window_end, reconciliation_status, matched_count, discrepancy_count, error, created_at, finished_at;
+
+-- name: CreateReconciliationDiscrepancies :copyfrom
+INSERT INTO reconciliation_discrepancy (run_id, category, reference, transaction_amount, wallet_amount, transaction_status, suggested_repair)
+VALUES ($1, $2, $3, $4, $5, $6, $7);
+
+-- name: TryLockReconciliation :one
+-- only one instance reconciles at a time, so every window is reconciled and reported once
+SELECT pg_try_advisory_xact_lock(7302);
+
+-- name: GetLastReconciledWindowEnd :one
+SELECT window_end
+FROM reconciliation_run
+WHERE reconciliation_status = 'COMPLETED'
+ORDER BY window_end DESC
+LIMIT 1;
+
+-- name: GetReconciliationRun :one
+SELECT id, window_start, window_end, reconciliation_status, matched_count, discrepancy_count, error, created_at, finished_at
+FROM reconciliation_run
+WHERE id = $1;
+
+-- name: GetReconciliationRuns :many
+SELECT id, window_start, window_end, reconciliation_status, matched_count, discrepancy_count, error, created_at, finished_at
+FROM reconciliation_run
+ORDER BY created_at DESC
+LIMIT $1 OFFSET $2;
+
+-- name: GetReconciliationDiscrepancies :many
+SELECT id, run_id, category, reference, transaction_amount, wallet_amount, transaction_status, suggested_repair, created_at
+FROM reconciliation_discrepancy
+WHERE run_id = $1
+ORDER BY id;
@@ -0,0 +1,9 @@
+-- name: CreateTransactionRefund :one
+INSERT INTO transaction_refund (transaction_reference, refund_reference, amount)
+VALUES ($1, $2, $3)
+RETURNING id, transaction_reference, refund_reference, amount, created_at;
+
+-- name: CountTransactionRefundsByReference :one
+SELECT COUNT(*)
+FROM transaction_refund
+WHERE transaction_reference = $1;
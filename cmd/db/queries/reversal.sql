@@ -0,0 +1,9 @@
+-- name: CreateTransactionReversal :one
+INSERT INTO transaction_reversal (transaction_reference, reversal_reference, amount)
+VALUES ($1, $2, $3)
+RETURNING id, transaction_reference, reversal_reference, amount, created_at;
+
+-- name: GetTransactionReversalByReference :one
+SELECT id, transaction_reference, reversal_reference, amount, created_at
+FROM transaction_reversal
+WHERE transaction_reference = $1;
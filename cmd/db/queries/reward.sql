@@ -0,0 +1,44 @@
+-- name: GetActiveCampaigns :many
+SELECT id, name, percentage, max_per_user, merchant_categories, starts_at, ends_at, is_active, created_at, updated_at
+FROM reward_campaign
+WHERE is_active = TRUE AND starts_at <= $1 AND ends_at >= $1
+ORDER BY id;
+
+-- name: LockUserCampaignRewards :exec
+SELECT pg_advisory_xact_lock(sqlc.arg(campaign_id)::int, sqlc.arg(user_id)::int);
+
+-- name: SumUserCampaignRewards :one
+SELECT COALESCE(SUM(amount), 0)::DECIMAL(10, 2) AS total
+FROM reward
+WHERE campaign_id = $1 AND user_id = $2 AND reward_status IN ('PENDING', 'CREDITED');
+
+-- name: CreateReward :one
+INSERT INTO reward (campaign_id, user_id, transaction_reference, credit_reference, amount, reward_status, clear_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING id, campaign_id, user_id, transaction_reference, credit_reference, amount, reward_status, clear_at, created_at, updated_at;
+
+-- name: GetRewardsByTransactionReference :many
+SELECT id, campaign_id, user_id, transaction_reference, credit_reference, amount, reward_status, clear_at, created_at, updated_at
+FROM reward
+WHERE transaction_reference = $1
+FOR UPDATE;
+
+-- name: GetDueRewards :many
+SELECT id, campaign_id, user_id, transaction_reference, credit_reference, amount, reward_status, clear_at, created_at, updated_at
+FROM reward
+WHERE reward_status = 'PENDING' AND clear_at <= $1
+ORDER BY clear_at
+LIMIT $2
+FOR UPDATE SKIP LOCKED;
+
+-- name: UpdateRewardStatus :one
+UPDATE reward SET reward_status = $2, credit_reference = $3, updated_at = CURRENT_TIMESTAMP
+WHERE id = $1
+RETURNING reward_status;
+
+-- name: GetRewardsByUserId :many
+SELECT id, campaign_id, user_id, transaction_reference, credit_reference, amount, reward_status, clear_at, created_at, updated_at
+FROM reward
+WHERE user_id = $1
+ORDER BY created_at DESC
+LIMIT $2 OFFSET $3;
@@ -4,11 +4,11 @@ VALUES ($1, $2, $3, $4, $5, $6, $7)
 RETURNING reference, transaction_status;
 
 -- name: GetTransactionByReference :one
-SELECT id, user_id, amount, transaction_type, transaction_status, reference, description, additional_info, created_at, updated_at
+SELECT id, user_id, amount, transaction_type, transaction_status, reference, description, additional_info, created_at, updated_at, status_reason, settled_at
 FROM transaction WHERE reference = $1;
 
 -- name: UpdateTransactionStatusByReference :one
-UPDATE transaction SET transaction_status = $2, additional_info = $3, updated_at = CURRENT_TIMESTAMP
+UPDATE transaction SET transaction_status = $2, additional_info = $3, status_reason = $4, updated_at = CURRENT_TIMESTAMP
 WHERE reference = $1
 RETURNING transaction_status;
 
@@ -19,6 +19,37 @@ ORDER BY created_at DESC
 LIMIT $2 OFFSET $3;
 
 -- name: GetTransactionByReferenceAndUserId :one
-SELECT id, user_id, amount, transaction_type, transaction_status, reference, description, additional_info, created_at, updated_at
+SELECT id, user_id, amount, transaction_type, transaction_status, reference, description, additional_info, created_at, updated_at, status_reason, settled_at
 FROM transaction 
 WHERE reference = $1 AND user_id = $2;
+
+-- name: GetTransactionByReferenceForUpdate :one
+SELECT id, user_id, amount, transaction_type, transaction_status, reference, description, additional_info, created_at, updated_at, status_reason, settled_at
+FROM transaction WHERE reference = $1
+FOR UPDATE;
+
+-- name: CreateTransactions :copyfrom
+INSERT INTO transaction (user_id, amount, transaction_type, transaction_status, reference, description, additional_info)
+VALUES ($1, $2, $3, $4, $5, $6, $7);
+
+-- name: GetTransactionsBySettledAt :many
+SELECT id, user_id, amount, transaction_type, transaction_status, reference, description, additional_info, created_at, updated_at, status_reason, settled_at
+FROM transaction
+WHERE settled_at >= $1 AND settled_at < $2
+ORDER BY settled_at;
+
+-- name: GetTransactionsByReferences :many
+SELECT id, user_id, amount, transaction_type, transaction_status, reference, description, additional_info, created_at, updated_at, status_reason, settled_at
+FROM transaction
+WHERE reference = ANY(sqlc.arg(references)::text[]);
+
+-- name: GetUserTransactionsAfter :many
+SELECT id, user_id, amount, transaction_type, transaction_status, reference, description, additional_info, created_at, updated_at, status_reason, settled_at
+FROM transaction
+WHERE user_id = sqlc.arg(user_id) AND id > sqlc.arg(after_id)
+AND (sqlc.arg(transaction_type)::text = '' OR transaction_type::text = sqlc.arg(transaction_type))
+AND (sqlc.arg(transaction_status)::text = '' OR transaction_status::text = sqlc.arg(transaction_status))
+AND (sqlc.arg(created_from)::timestamp IS NULL OR created_at >= sqlc.arg(created_from))
+AND (sqlc.arg(created_to)::timestamp IS NULL OR created_at < sqlc.arg(created_to))
+ORDER BY id
+LIMIT sqlc.arg('limit');
@@ -0,0 +1,91 @@
+-- name: CreateWebhookEndpoint :one
+INSERT INTO webhook_endpoint (user_id, url, secret)
+VALUES ($1, $2, $3)
+RETURNING id, user_id, url, secret, is_active, consecutive_failures, disabled_at, created_at, updated_at;
+
+-- name: GetWebhookEndpointsByUserId :many
+SELECT id, user_id, url, secret, is_active, consecutive_failures, disabled_at, created_at, updated_at
+FROM webhook_endpoint
+WHERE user_id = $1
+ORDER BY id;
+
+-- name: GetWebhookEndpointByIdAndUserId :one
+SELECT id, user_id, url, secret, is_active, consecutive_failures, disabled_at, created_at, updated_at
+FROM webhook_endpoint
+WHERE id = $1 AND user_id = $2;
+
+-- name: DeleteWebhookEndpoint :execrows
+DELETE FROM webhook_endpoint
+WHERE id = $1 AND user_id = $2;
+
+-- name: EnableWebhookEndpoint :one
+UPDATE webhook_endpoint
+SET is_active = TRUE, consecutive_failures = 0, disabled_at = NULL, updated_at = CURRENT_TIMESTAMP
+WHERE id = $1 AND user_id = $2
+RETURNING id, user_id, url, secret, is_active, consecutive_failures, disabled_at, created_at, updated_at;
+
+-- name: RecordWebhookEndpointSuccess :exec
+UPDATE webhook_endpoint
+SET consecutive_failures = 0, updated_at = CURRENT_TIMESTAMP
+WHERE id = $1;
+
+-- name: RecordWebhookEndpointFailure :one
+UPDATE webhook_endpoint
+SET consecutive_failures = consecutive_failures + 1,
+    is_active = consecutive_failures + 1 < sqlc.arg(disable_after)::int,
+    disabled_at = CASE WHEN consecutive_failures + 1 >= sqlc.arg(disable_after)::int THEN CURRENT_TIMESTAMP ELSE disabled_at END,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = sqlc.arg(id)
+RETURNING is_active;
+
+-- name: EnqueueWebhookDeliveries :execrows
+INSERT INTO webhook_delivery (endpoint_id, event, reference, payload)
+SELECT id, sqlc.arg(event)::text, sqlc.arg(reference)::text, sqlc.arg(payload)::text
+FROM webhook_endpoint
+WHERE user_id = sqlc.arg(user_id) AND is_active = TRUE;
+
+-- name: CreateWebhookDelivery :one
+INSERT INTO webhook_delivery (endpoint_id, event, reference, payload)
+VALUES ($1, $2, $3, $4)
+RETURNING id, endpoint_id, event, reference, payload, delivery_status, attempts, next_attempt_at, last_status_code, last_error, delivered_at, created_at, updated_at;
+
+-- name: GetDueWebhookDeliveries :many
+SELECT d.id, d.endpoint_id, d.event, d.reference, d.payload, d.attempts, e.url, e.secret
+FROM webhook_delivery d
+JOIN webhook_endpoint e ON e.id = d.endpoint_id
+WHERE d.delivery_status = 'PENDING' AND d.next_attempt_at <= $1 AND e.is_active = TRUE
+ORDER BY d.next_attempt_at
+LIMIT $2
+FOR UPDATE OF d SKIP LOCKED;
+
+-- name: LeaseWebhookDelivery :exec
+UPDATE webhook_delivery
+SET next_attempt_at = $2, updated_at = CURRENT_TIMESTAMP
+WHERE id = $1;
+
+-- name: UpdateWebhookDelivery :exec
+UPDATE webhook_delivery
+SET delivery_status = $2, attempts = $3, next_attempt_at = $4, last_status_code = $5, last_error = $6, delivered_at = $7, updated_at = CURRENT_TIMESTAMP
+WHERE id = $1;
+
+-- name: GetWebhookDeliveriesByEndpointId :many
+SELECT id, endpoint_id, event, reference, payload, delivery_status, attempts, next_attempt_at, last_status_code, last_error, delivered_at, created_at, updated_at
+FROM webhook_delivery
+WHERE endpoint_id = $1
+ORDER BY created_at DESC
+LIMIT $2 OFFSET $3;
+
+-- name: GetWebhookDeliveryByIdAndEndpointId :one
+SELECT id, endpoint_id, event, reference, payload, delivery_status, attempts, next_attempt_at, last_status_code, last_error, delivered_at, created_at, updated_at
+FROM webhook_delivery
+WHERE id = $1 AND endpoint_id = $2;
+
+-- name: CreateWebhookDeliveryAttempt :exec
+INSERT INTO webhook_delivery_attempt (delivery_id, status_code, error, duration_ms)
+VALUES ($1, $2, $3, $4);
+
+-- name: GetWebhookDeliveryAttempts :many
+SELECT id, delivery_id, status_code, error, duration_ms, created_at
+FROM webhook_delivery_attempt
+WHERE delivery_id = $1
+ORDER BY id;
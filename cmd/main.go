@@ -1,14 +1,32 @@
 package main
 
 import (
+	"context"
+	"log"
+	"os/signal"
+	"syscall"
+	"time"
+
 	"github.com/ArdiSasongko/EwalletProjects-transaction/cmd/api"
 )
 
 func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	app, err := api.SetupApplication()
+	if err != nil {
+		log.Fatalf("failed to setup application: %v", err)
+	}
+
 	// setup grpc
-	//go api.SetupGRPC()
+	go api.SetupGRPC(app)
 
 	// setup http
-	api.SetupHTTP()
+	go api.SetupHTTP(app)
+
+	<-ctx.Done()
+	stop()
 
+	api.Shutdown(app, 10*time.Second)
 }
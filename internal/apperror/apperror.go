@@ -0,0 +1,126 @@
+// Package apperror defines the domain errors returned by the services. Each
+// error carries a stable machine-readable code clients can match on, a message
+// that is safe to show them and the underlying cause, which is only logged.
+package apperror
+
+import (
+	"errors"
+	"fmt"
+)
+
+type Kind int
+
+const (
+	// KindInternal is any failure the caller can't do anything about.
+	KindInternal Kind = iota
+	KindInvalidInput
+	KindNotFound
+	KindInvalidTransition
+	KindLimitExceeded
+	KindUpstreamUnavailable
+	KindConflict
+)
+
+func (k Kind) String() string {
+	switch k {
+	case KindInvalidInput:
+		return "invalid input"
+	case KindNotFound:
+		return "not found"
+	case KindInvalidTransition:
+		return "invalid transition"
+	case KindLimitExceeded:
+		return "limit exceeded"
+	case KindUpstreamUnavailable:
+		return "upstream unavailable"
+	case KindConflict:
+		return "conflict"
+	default:
+		return "internal"
+	}
+}
+
+type Error struct {
+	Kind Kind
+	// Code is stable across releases, the message is not.
+	Code    string
+	Message string
+	Err     error
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s :%v", e.Message, e.Err)
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is matches any error of the same kind and code, so errors.Is still finds a
+// sentinel after Wrap or Messagef made a copy of it.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Kind == e.Kind && t.Code == e.Code
+}
+
+// Wrap returns a copy of e caused by err.
+func (e *Error) Wrap(err error) *Error {
+	c := *e
+	c.Err = err
+	return &c
+}
+
+// Messagef returns a copy of e with a more specific message.
+func (e *Error) Messagef(format string, args ...any) *Error {
+	c := *e
+	c.Message = fmt.Sprintf(format, args...)
+	return &c
+}
+
+// ErrValidation is returned for a payload that failed validation, the
+// validator errors are kept as its cause.
+var ErrValidation = InvalidInput("validation_failed", "one or more fields failed validation")
+
+func New(kind Kind, code, message string) *Error {
+	return &Error{
+		Kind:    kind,
+		Code:    code,
+		Message: message,
+	}
+}
+
+func InvalidInput(code, message string) *Error {
+	return New(KindInvalidInput, code, message)
+}
+
+func NotFound(code, message string) *Error {
+	return New(KindNotFound, code, message)
+}
+
+func InvalidTransition(code, message string) *Error {
+	return New(KindInvalidTransition, code, message)
+}
+
+func LimitExceeded(code, message string) *Error {
+	return New(KindLimitExceeded, code, message)
+}
+
+func UpstreamUnavailable(code, message string) *Error {
+	return New(KindUpstreamUnavailable, code, message)
+}
+
+func Conflict(code, message string) *Error {
+	return New(KindConflict, code, message)
+}
+
+// As returns the domain error anywhere in err's chain.
+func As(err error) (*Error, bool) {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr, true
+	}
+	return nil, false
+}
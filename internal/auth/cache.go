@@ -0,0 +1,225 @@
+package auth
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/model"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// TokenHash is the cache key of a token, raw tokens are never kept.
+func TokenHash(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// TokenExpiry reads the exp claim without checking the signature, it only
+// bounds how long an already validated token is cached.
+func TokenExpiry(token string) (time.Time, bool) {
+	claims := jwt.MapClaims{}
+	if _, _, err := jwt.NewParser().ParseUnverified(token, claims); err != nil {
+		return time.Time{}, false
+	}
+
+	exp, err := claims.GetExpirationTime()
+	if err != nil || exp == nil {
+		return time.Time{}, false
+	}
+	return exp.Time, true
+}
+
+type cacheEntry struct {
+	hash      string
+	data      model.TokenResponse
+	expiresAt time.Time
+}
+
+type TokenCacheStats struct {
+	Entries     int
+	Hits        uint64
+	Misses      uint64
+	Evictions   uint64
+	Revocations uint64
+}
+
+// HitRate is the share of lookups served from the cache.
+func (s TokenCacheStats) HitRate() float64 {
+	total := s.Hits + s.Misses
+	if total == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(total)
+}
+
+// TokenCache keeps validated identities by token hash for at most ttl and
+// never past the token's expiry. When full, the least recently used entry is
+// evicted.
+type TokenCache struct {
+	maxEntries int
+	ttl        time.Duration
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	order   *list.List
+	byUser  map[int32]map[string]struct{}
+	// generation changes on every revocation, see Set
+	generation uint64
+	// suspended stops caching while revocations can't be received
+	suspended bool
+
+	hits        atomic.Uint64
+	misses      atomic.Uint64
+	evictions   atomic.Uint64
+	revocations atomic.Uint64
+}
+
+func NewTokenCache(maxEntries int, ttl time.Duration) *TokenCache {
+	return &TokenCache{
+		maxEntries: maxEntries,
+		ttl:        ttl,
+		entries:    map[string]*list.Element{},
+		order:      list.New(),
+		byUser:     map[int32]map[string]struct{}{},
+	}
+}
+
+func (c *TokenCache) Get(hash string) (model.TokenResponse, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.entries[hash]
+	if !ok {
+		c.misses.Add(1)
+		return model.TokenResponse{}, false
+	}
+
+	entry := el.Value.(*cacheEntry)
+	if time.Now().After(entry.expiresAt) {
+		c.remove(el)
+		c.misses.Add(1)
+		return model.TokenResponse{}, false
+	}
+
+	c.order.MoveToFront(el)
+	c.hits.Add(1)
+	return entry.data, true
+}
+
+// Generation is read before validating a token and handed back to Set.
+func (c *TokenCache) Generation() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.generation
+}
+
+// Set caches a validated identity. It is dropped if a revocation arrived since
+// generation was read, the validation may have raced with it.
+func (c *TokenCache) Set(hash string, data model.TokenResponse, tokenExpiry time.Time, generation uint64) {
+	expiresAt := time.Now().Add(c.ttl)
+	if !tokenExpiry.IsZero() && tokenExpiry.Before(expiresAt) {
+		expiresAt = tokenExpiry
+	}
+	if !expiresAt.After(time.Now()) {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if generation != c.generation || c.suspended {
+		return
+	}
+
+	if el, ok := c.entries[hash]; ok {
+		c.remove(el)
+	}
+
+	c.entries[hash] = c.order.PushFront(&cacheEntry{
+		hash:      hash,
+		data:      data,
+		expiresAt: expiresAt,
+	})
+	if c.byUser[data.UserID] == nil {
+		c.byUser[data.UserID] = map[string]struct{}{}
+	}
+	c.byUser[data.UserID][hash] = struct{}{}
+
+	for c.order.Len() > c.maxEntries {
+		c.remove(c.order.Back())
+		c.evictions.Add(1)
+	}
+}
+
+// Revoke evicts one token.
+func (c *TokenCache) Revoke(hash string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.generation++
+	c.revocations.Add(1)
+	if el, ok := c.entries[hash]; ok {
+		c.remove(el)
+	}
+}
+
+// RevokeUser evicts every token of a user.
+func (c *TokenCache) RevokeUser(userID int32) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.generation++
+	c.revocations.Add(1)
+	for hash := range c.byUser[userID] {
+		c.remove(c.entries[hash])
+	}
+}
+
+// Suspend drops every entry and stops caching until Resume, a token revoked
+// meanwhile would otherwise be served from the cache.
+func (c *TokenCache) Suspend() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.generation++
+	c.suspended = true
+	c.entries = map[string]*list.Element{}
+	c.order.Init()
+	c.byUser = map[int32]map[string]struct{}{}
+}
+
+// Resume caches validated tokens again.
+func (c *TokenCache) Resume() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.suspended = false
+}
+
+func (c *TokenCache) remove(el *list.Element) {
+	entry := c.order.Remove(el).(*cacheEntry)
+	delete(c.entries, entry.hash)
+
+	delete(c.byUser[entry.data.UserID], entry.hash)
+	if len(c.byUser[entry.data.UserID]) == 0 {
+		delete(c.byUser, entry.data.UserID)
+	}
+}
+
+func (c *TokenCache) Stats() TokenCacheStats {
+	c.mu.Lock()
+	entries := c.order.Len()
+	c.mu.Unlock()
+
+	return TokenCacheStats{
+		Entries:     entries,
+		Hits:        c.hits.Load(),
+		Misses:      c.misses.Load(),
+		Evictions:   c.evictions.Load(),
+		Revocations: c.revocations.Load(),
+	}
+}
@@ -0,0 +1,219 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrKeyUnavailable is returned when the key set has no key for a token,
+// callers may fall back to remote validation.
+var ErrKeyUnavailable = errors.New("signing key unavailable")
+
+// minRefreshInterval limits refreshes triggered by unknown key ids, so tokens
+// with made up ids can't make us fetch the JWKS on every request.
+const minRefreshInterval = time.Minute
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Crv string `json:"crv"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+type jwks struct {
+	Keys []jwk `json:"keys"`
+}
+
+// KeySet holds the public keys of a JWKS document read from a file or an
+// HTTP(S) URL. Refresh replaces the keys, so a rotated key is picked up on the
+// next refresh, or right away when a token names a key id we don't know.
+type KeySet struct {
+	source     string
+	httpClient *http.Client
+
+	mu          sync.RWMutex
+	keys        map[string]crypto.PublicKey
+	refreshedAt time.Time
+}
+
+func NewKeySet(source string) *KeySet {
+	return &KeySet{
+		source: source,
+		httpClient: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+		keys: map[string]crypto.PublicKey{},
+	}
+}
+
+func (k *KeySet) read(ctx context.Context) ([]byte, error) {
+	if !strings.HasPrefix(k.source, "http://") && !strings.HasPrefix(k.source, "https://") {
+		return os.ReadFile(strings.TrimPrefix(k.source, "file://"))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, k.source, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := k.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("got status %d", resp.StatusCode)
+	}
+
+	return io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+}
+
+// Refresh reads the JWKS document and replaces the keys. Keys that are not
+// for signatures or of an unsupported type are skipped.
+func (k *KeySet) Refresh(ctx context.Context) error {
+	k.mu.Lock()
+	k.refreshedAt = time.Now()
+	k.mu.Unlock()
+
+	body, err := k.read(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to read jwks :%w", err)
+	}
+
+	var doc jwks
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return fmt.Errorf("failed to decode jwks :%w", err)
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(doc.Keys))
+	for _, key := range doc.Keys {
+		if key.Use != "" && key.Use != "sig" {
+			continue
+		}
+
+		pub, err := key.publicKey()
+		if err != nil {
+			continue
+		}
+		keys[key.Kid] = pub
+	}
+
+	if len(keys) == 0 {
+		return fmt.Errorf("jwks has no usable signing key")
+	}
+
+	k.mu.Lock()
+	k.keys = keys
+	k.mu.Unlock()
+
+	return nil
+}
+
+// Key returns the key with the given id, refreshing the set once if it is
+// unknown.
+func (k *KeySet) Key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	k.mu.RLock()
+	key, ok := k.keys[kid]
+	stale := time.Since(k.refreshedAt) > minRefreshInterval
+	k.mu.RUnlock()
+
+	if ok {
+		return key, nil
+	}
+
+	if stale {
+		if err := k.Refresh(ctx); err != nil {
+			return nil, fmt.Errorf("%w :%v", ErrKeyUnavailable, err)
+		}
+
+		k.mu.RLock()
+		key, ok = k.keys[kid]
+		k.mu.RUnlock()
+		if ok {
+			return key, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w, kid %q", ErrKeyUnavailable, kid)
+}
+
+func decodeSegment(s string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+}
+
+func (key jwk) publicKey() (crypto.PublicKey, error) {
+	switch key.Kty {
+	case "RSA":
+		n, err := decodeSegment(key.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeSegment(key.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch key.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %s", key.Crv)
+		}
+		x, err := decodeSegment(key.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeSegment(key.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{
+			Curve: curve,
+			X:     new(big.Int).SetBytes(x),
+			Y:     new(big.Int).SetBytes(y),
+		}, nil
+	case "OKP":
+		if key.Crv != "Ed25519" {
+			return nil, fmt.Errorf("unsupported curve %s", key.Crv)
+		}
+		x, err := decodeSegment(key.X)
+		if err != nil {
+			return nil, err
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("invalid ed25519 key size")
+		}
+		return ed25519.PublicKey(x), nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %s", key.Kty)
+	}
+}
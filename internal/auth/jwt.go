@@ -23,7 +23,11 @@ type AuthJwt struct {
 }
 
 func NewJwt(secret, aud, iss string) *AuthJwt {
-	return &AuthJwt{}
+	return &AuthJwt{
+		secret: secret,
+		aud:    aud,
+		iss:    iss,
+	}
 }
 
 func (a *AuthJwt) GenerateToken(id int32, tokenTime string) (string, error) {
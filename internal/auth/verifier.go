@@ -0,0 +1,110 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/model"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Verifier checks access tokens signed by the user service against its JWKS,
+// without a call to the user service.
+type Verifier struct {
+	keys   *KeySet
+	parser *jwt.Parser
+}
+
+// NewVerifier validates the signature, exp and nbf of every token, and iss and
+// aud when they are set.
+func NewVerifier(keys *KeySet, iss, aud string) *Verifier {
+	options := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512", "EdDSA"}),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(30 * time.Second),
+	}
+	if iss != "" {
+		options = append(options, jwt.WithIssuer(iss))
+	}
+	if aud != "" {
+		options = append(options, jwt.WithAudience(aud))
+	}
+
+	return &Verifier{
+		keys:   keys,
+		parser: jwt.NewParser(options...),
+	}
+}
+
+// Verify returns the identity in a valid token. An error wrapping
+// ErrKeyUnavailable means the token could not be checked locally.
+func (v *Verifier) Verify(ctx context.Context, token string) (model.TokenResponse, error) {
+	claims := jwt.MapClaims{}
+	if _, err := v.parser.ParseWithClaims(token, claims, func(t *jwt.Token) (any, error) {
+		kid, _ := t.Header["kid"].(string)
+		return v.keys.Key(ctx, kid)
+	}); err != nil {
+		return model.TokenResponse{}, fmt.Errorf("invalid token :%w", err)
+	}
+
+	userID, err := subject(claims["sub"])
+	if err != nil {
+		return model.TokenResponse{}, err
+	}
+
+	email, _ := claims["email"].(string)
+	role, _ := claims["role"].(string)
+
+	return model.TokenResponse{
+		UserID: userID,
+		Email:  email,
+		Role:   role,
+		Scopes: scopes(claims),
+	}, nil
+}
+
+// subject reads the user id, issued as a number or a numeric string. Ids
+// outside 1..MaxInt32 don't name a user and are rejected rather than wrapped.
+func subject(sub any) (int32, error) {
+	var id int64
+	switch s := sub.(type) {
+	case float64:
+		if s != math.Trunc(s) || s < 1 || s > math.MaxInt32 {
+			return 0, fmt.Errorf("invalid token subject")
+		}
+		id = int64(s)
+	case string:
+		parsed, err := strconv.ParseInt(s, 10, 32)
+		if err != nil {
+			return 0, fmt.Errorf("invalid token subject")
+		}
+		id = parsed
+	default:
+		return 0, fmt.Errorf("invalid token subject")
+	}
+
+	if id < 1 {
+		return 0, fmt.Errorf("invalid token subject")
+	}
+	return int32(id), nil
+}
+
+// scopes reads a space separated "scope" claim or a "scopes" array.
+func scopes(claims jwt.MapClaims) []string {
+	if scope, ok := claims["scope"].(string); ok {
+		return strings.Fields(scope)
+	}
+
+	list, _ := claims["scopes"].([]any)
+	resp := make([]string, 0, len(list))
+	for _, s := range list {
+		if scope, ok := s.(string); ok {
+			resp = append(resp, scope)
+		}
+	}
+	return resp
+}
@@ -0,0 +1,282 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	testKid = "test-key"
+	testIss = "user-service"
+	testAud = "transaction-service"
+)
+
+// newTestVerifier writes a JWKS with a single ES256 key and returns a verifier
+// reading it, with the key to sign tokens.
+func newTestVerifier(t *testing.T) (*Verifier, *ecdsa.PrivateKey) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	doc, err := json.Marshal(jwks{Keys: []jwk{{
+		Kty: "EC",
+		Kid: testKid,
+		Use: "sig",
+		Crv: "P-256",
+		X:   base64.RawURLEncoding.EncodeToString(key.PublicKey.X.FillBytes(make([]byte, 32))),
+		Y:   base64.RawURLEncoding.EncodeToString(key.PublicKey.Y.FillBytes(make([]byte, 32))),
+	}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(path, doc, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	keys := NewKeySet(path)
+	if err := keys.Refresh(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	return NewVerifier(keys, testIss, testAud), key
+}
+
+// validClaims are accepted by the test verifier, tests change them to break
+// one check at a time.
+func validClaims() jwt.MapClaims {
+	now := time.Now()
+	return jwt.MapClaims{
+		"sub":   "7",
+		"email": "owner@example.com",
+		"role":  "user",
+		"scope": "transaction:read transaction:write",
+		"iss":   testIss,
+		"aud":   testAud,
+		"iat":   now.Unix(),
+		"nbf":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	}
+}
+
+func sign(t *testing.T, method jwt.SigningMethod, key any, kid string, claims jwt.MapClaims) string {
+	t.Helper()
+
+	token := jwt.NewWithClaims(method, claims)
+	token.Header["kid"] = kid
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed
+}
+
+func TestVerify(t *testing.T) {
+	v, key := newTestVerifier(t)
+	now := time.Now()
+
+	tests := []struct {
+		name    string
+		token   func() string
+		wantErr bool
+	}{
+		{
+			name:  "valid",
+			token: func() string { return sign(t, jwt.SigningMethodES256, key, testKid, validClaims()) },
+		},
+		{
+			name: "hmac signed",
+			token: func() string {
+				return sign(t, jwt.SigningMethodHS256, []byte("shared-secret"), testKid, validClaims())
+			},
+			wantErr: true,
+		},
+		{
+			name: "unsigned",
+			token: func() string {
+				return sign(t, jwt.SigningMethodNone, jwt.UnsafeAllowNoneSignatureType, testKid, validClaims())
+			},
+			wantErr: true,
+		},
+		{
+			name: "signed by another key",
+			token: func() string {
+				other, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+				if err != nil {
+					t.Fatal(err)
+				}
+				return sign(t, jwt.SigningMethodES256, other, testKid, validClaims())
+			},
+			wantErr: true,
+		},
+		{
+			name: "expired within the leeway",
+			token: func() string {
+				claims := validClaims()
+				claims["exp"] = now.Add(-10 * time.Second).Unix()
+				return sign(t, jwt.SigningMethodES256, key, testKid, claims)
+			},
+		},
+		{
+			name: "expired past the leeway",
+			token: func() string {
+				claims := validClaims()
+				claims["exp"] = now.Add(-time.Minute).Unix()
+				return sign(t, jwt.SigningMethodES256, key, testKid, claims)
+			},
+			wantErr: true,
+		},
+		{
+			name: "without expiry",
+			token: func() string {
+				claims := validClaims()
+				delete(claims, "exp")
+				return sign(t, jwt.SigningMethodES256, key, testKid, claims)
+			},
+			wantErr: true,
+		},
+		{
+			name: "not yet valid within the leeway",
+			token: func() string {
+				claims := validClaims()
+				claims["nbf"] = now.Add(10 * time.Second).Unix()
+				return sign(t, jwt.SigningMethodES256, key, testKid, claims)
+			},
+		},
+		{
+			name: "not yet valid past the leeway",
+			token: func() string {
+				claims := validClaims()
+				claims["nbf"] = now.Add(time.Minute).Unix()
+				return sign(t, jwt.SigningMethodES256, key, testKid, claims)
+			},
+			wantErr: true,
+		},
+		{
+			name: "wrong issuer",
+			token: func() string {
+				claims := validClaims()
+				claims["iss"] = "someone-else"
+				return sign(t, jwt.SigningMethodES256, key, testKid, claims)
+			},
+			wantErr: true,
+		},
+		{
+			name: "wrong audience",
+			token: func() string {
+				claims := validClaims()
+				claims["aud"] = "wallet-service"
+				return sign(t, jwt.SigningMethodES256, key, testKid, claims)
+			},
+			wantErr: true,
+		},
+		{
+			name: "audience among others",
+			token: func() string {
+				claims := validClaims()
+				claims["aud"] = []string{"wallet-service", testAud}
+				return sign(t, jwt.SigningMethodES256, key, testKid, claims)
+			},
+		},
+		{
+			name: "subject out of range",
+			token: func() string {
+				claims := validClaims()
+				claims["sub"] = "2147483648"
+				return sign(t, jwt.SigningMethodES256, key, testKid, claims)
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			identity, err := v.Verify(context.Background(), tt.token())
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Verify() = %+v, want an error", identity)
+				}
+				if errors.Is(err, ErrKeyUnavailable) {
+					t.Errorf("Verify() error = %v, must not fall back to remote validation", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Verify() error = %v", err)
+			}
+			if identity.UserID != 7 || identity.Email != "owner@example.com" || identity.Role != "user" {
+				t.Errorf("Verify() = %+v, want user 7 owner@example.com", identity)
+			}
+			if len(identity.Scopes) != 2 {
+				t.Errorf("Scopes = %v, want 2 scopes", identity.Scopes)
+			}
+		})
+	}
+}
+
+func TestVerifyUnknownKid(t *testing.T) {
+	v, key := newTestVerifier(t)
+
+	_, err := v.Verify(context.Background(), sign(t, jwt.SigningMethodES256, key, "rotated-away", validClaims()))
+	if !errors.Is(err, ErrKeyUnavailable) {
+		t.Fatalf("Verify() error = %v, want %v", err, ErrKeyUnavailable)
+	}
+}
+
+func TestSubject(t *testing.T) {
+	tests := []struct {
+		name    string
+		sub     any
+		want    int32
+		wantErr bool
+	}{
+		{name: "number", sub: float64(7), want: 7},
+		{name: "string", sub: "7", want: 7},
+		{name: "max int32", sub: float64(math.MaxInt32), want: math.MaxInt32},
+		{name: "max int32 string", sub: "2147483647", want: math.MaxInt32},
+		{name: "zero", sub: float64(0), wantErr: true},
+		{name: "zero string", sub: "0", wantErr: true},
+		{name: "negative", sub: float64(-3), wantErr: true},
+		{name: "negative string", sub: "-3", wantErr: true},
+		{name: "overflow", sub: float64(math.MaxInt32 + 1), wantErr: true},
+		{name: "overflow string", sub: "2147483648", wantErr: true},
+		{name: "huge", sub: 1e20, wantErr: true},
+		{name: "fraction", sub: 7.5, wantErr: true},
+		{name: "not a number", sub: "abc", wantErr: true},
+		{name: "missing", sub: nil, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := subject(tt.sub)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("subject(%v) = %d, want an error", tt.sub, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("subject(%v) error = %v", tt.sub, err)
+			}
+			if got != tt.want {
+				t.Errorf("subject(%v) = %d, want %d", tt.sub, got, tt.want)
+			}
+		})
+	}
+}
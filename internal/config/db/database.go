@@ -5,10 +5,21 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/tracing"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	_ "github.com/lib/pq"
 )
 
+// enumTypes are registered on every new connection because CopyFrom can only
+// encode enum columns whose type is known to pgx.
+var enumTypes = []string{
+	"transaction_type",
+	"transaction_status",
+	"batch_item_status",
+	"discrepancy_category",
+}
+
 func New(addr string, maxOpenConns, maxIdleConns int, maxIdleTime string) (*pgxpool.Pool, error) {
 	ctx := context.Background()
 	config, err := pgxpool.ParseConfig(addr)
@@ -24,6 +35,18 @@ func New(addr string, maxOpenConns, maxIdleConns int, maxIdleTime string) (*pgxp
 		return nil, fmt.Errorf("unable to parse max idle time: %v", err)
 	}
 	config.MaxConnIdleTime = duration
+	config.ConnConfig.Tracer = tracing.QueryTracer{}
+
+	config.AfterConnect = func(ctx context.Context, conn *pgx.Conn) error {
+		for _, name := range enumTypes {
+			t, err := conn.LoadType(ctx, name)
+			if err != nil {
+				return fmt.Errorf("unable to load type %s: %v", name, err)
+			}
+			conn.TypeMap().RegisterType(t)
+		}
+		return nil
+	}
 
 	dbpool, err := pgxpool.NewWithConfig(ctx, config)
 	if err != nil {
@@ -27,3 +27,17 @@ func GetEnvInt(key string, fallback int) int {
 
 	return valInt
 }
+
+func GetEnvBool(key string, fallback bool) bool {
+	val, ok := os.LookupEnv(key)
+	if !ok {
+		return fallback
+	}
+
+	valBool, err := strconv.ParseBool(val)
+	if err != nil {
+		return fallback
+	}
+
+	return valBool
+}
@@ -0,0 +1,91 @@
+package event
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+type Handler func(context.Context, Message) error
+
+type subscription struct {
+	id      int
+	subject string
+	handler Handler
+}
+
+// Broker is an in-process Publisher. Handlers are called synchronously in
+// subscription order, so messages reach every subscriber in publish order.
+// It backs the event stream when no external broker is configured and in
+// tests.
+type Broker struct {
+	mu     sync.RWMutex
+	nextID int
+	subs   []subscription
+}
+
+func NewBroker() *Broker {
+	return &Broker{}
+}
+
+// Subscribe registers a handler for a subject. A subject ending in ".>"
+// matches every subject with that prefix, as in NATS. The returned func
+// removes the subscription.
+func (b *Broker) Subscribe(subject string, handler Handler) func() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.nextID++
+	id := b.nextID
+	b.subs = append(b.subs, subscription{
+		id:      id,
+		subject: subject,
+		handler: handler,
+	})
+
+	return func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		for i := range b.subs {
+			if b.subs[i].id == id {
+				b.subs = append(b.subs[:i], b.subs[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+func subjectMatches(pattern, subject string) bool {
+	if prefix, ok := strings.CutSuffix(pattern, ">"); ok {
+		return strings.HasPrefix(subject, prefix)
+	}
+	return pattern == subject
+}
+
+// Publish delivers the message to every matching subscriber and fails on the
+// first handler error, so the outbox retries the message.
+func (b *Broker) Publish(ctx context.Context, msg Message) error {
+	b.mu.RLock()
+	subs := make([]subscription, len(b.subs))
+	copy(subs, b.subs)
+	b.mu.RUnlock()
+
+	for _, sub := range subs {
+		if !subjectMatches(sub.subject, msg.Subject) {
+			continue
+		}
+		if err := sub.handler(ctx, msg); err != nil {
+			return fmt.Errorf("subscriber %s failed :%w", sub.subject, err)
+		}
+	}
+
+	return nil
+}
+
+func (b *Broker) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.subs = nil
+	return nil
+}
@@ -0,0 +1,25 @@
+package event
+
+import (
+	"context"
+)
+
+const (
+	TypeTransactionCreated  = "transaction.created.v1"
+	TypeStatusChanged       = "transaction.status_changed.v1"
+	TypeTransactionRefunded = "transaction.refunded.v1"
+)
+
+// Message is an encoded event ready to be handed to a broker. Key is the
+// transaction reference, brokers must keep messages of one key in order.
+type Message struct {
+	ID      string
+	Subject string
+	Key     string
+	Data    []byte
+}
+
+type Publisher interface {
+	Publish(context.Context, Message) error
+	Close() error
+}
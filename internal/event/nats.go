@@ -0,0 +1,49 @@
+package event
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/nats-io/nats.go"
+)
+
+// NATSPublisher publishes on a NATS connection. Messages carry the event id in
+// the Nats-Msg-Id header so a JetStream stream bound to the subjects drops
+// the duplicates an outbox retry can produce.
+type NATSPublisher struct {
+	conn *nats.Conn
+}
+
+func NewNATSPublisher(url string) (*NATSPublisher, error) {
+	conn, err := nats.Connect(url, nats.Name("transaction-service"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect nats :%w", err)
+	}
+
+	return &NATSPublisher{
+		conn: conn,
+	}, nil
+}
+
+// Publish waits for the server to acknowledge the message. A single
+// connection keeps publish order, which is what orders a reference's events.
+func (p *NATSPublisher) Publish(ctx context.Context, msg Message) error {
+	m := nats.NewMsg(msg.Subject)
+	m.Header.Set(nats.MsgIdHdr, msg.ID)
+	m.Header.Set("Event-Key", msg.Key)
+	m.Data = msg.Data
+
+	if err := p.conn.PublishMsg(m); err != nil {
+		return fmt.Errorf("failed to publish event :%w", err)
+	}
+
+	if err := p.conn.FlushWithContext(ctx); err != nil {
+		return fmt.Errorf("failed to flush nats connection :%w", err)
+	}
+
+	return nil
+}
+
+func (p *NATSPublisher) Close() error {
+	return p.conn.Drain()
+}
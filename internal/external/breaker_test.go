@@ -0,0 +1,83 @@
+package external
+
+import (
+	"testing"
+	"time"
+)
+
+// expire ends the cooldown without waiting for it.
+func (b *breaker) expire() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.openUntil = time.Now().Add(-time.Millisecond)
+}
+
+func TestBreakerCycle(t *testing.T) {
+	tests := []struct {
+		name      string
+		probe     func(b *breaker)
+		wantOpen  bool
+		wantAllow bool
+	}{
+		{name: "probe success closes", probe: (*breaker).success, wantOpen: false, wantAllow: true},
+		{name: "probe failure reopens", probe: (*breaker).failure, wantOpen: true, wantAllow: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := newBreaker(2, time.Hour)
+
+			for i := 0; i < 2; i++ {
+				if !b.allow() {
+					t.Fatalf("call %d refused before the threshold", i+1)
+				}
+				b.failure()
+			}
+			if !b.open() {
+				t.Fatal("circuit closed after threshold failures")
+			}
+			if b.allow() {
+				t.Fatal("open circuit let a call through during the cooldown")
+			}
+
+			b.expire()
+			if !b.allow() {
+				t.Fatal("half-open circuit refused the probe")
+			}
+			if b.allow() {
+				t.Fatal("half-open circuit let a second call through while probing")
+			}
+
+			tt.probe(b)
+			if got := b.open(); got != tt.wantOpen {
+				t.Errorf("open() = %v, want %v", got, tt.wantOpen)
+			}
+			if got := b.allow(); got != tt.wantAllow {
+				t.Errorf("allow() after probe = %v, want %v", got, tt.wantAllow)
+			}
+		})
+	}
+}
+
+func TestBreakerSuccessResetsFailures(t *testing.T) {
+	b := newBreaker(2, time.Hour)
+
+	b.failure()
+	b.success()
+	b.failure()
+	if b.open() {
+		t.Fatal("failures separated by a success opened the circuit")
+	}
+}
+
+func TestBreakerDisabled(t *testing.T) {
+	b := newBreaker(0, time.Hour)
+
+	for i := 0; i < 10; i++ {
+		b.failure()
+	}
+	if b.open() || !b.allow() {
+		t.Fatal("breaker with threshold 0 opened")
+	}
+}
@@ -2,10 +2,14 @@ package external
 
 import (
 	"context"
-	"net/http"
+	"errors"
 	"time"
 
+	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/auth"
+	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/external/proto/notification"
+	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/external/proto/token"
 	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/model"
+	"google.golang.org/grpc"
 )
 
 type External struct {
@@ -15,20 +19,82 @@ type External struct {
 	Wallet interface {
 		Credit(context.Context, WalletRequest, string) (*WalletResponse, error)
 		Debit(context.Context, WalletRequest, string) (*WalletResponse, error)
+		ListEntries(context.Context, time.Time, time.Time, string) ([]WalletEntry, error)
+		Stats() WalletStats
+	}
+	Webhook interface {
+		Send(context.Context, WebhookRequest) (int, error)
 	}
 	Validation interface {
 		ValidateToken(context.Context, string) (model.TokenResponse, error)
 	}
+
+	tokenClient token.TokenServiceClient
+	conns       []*grpc.ClientConn
+}
+
+type Config struct {
+	UserService  GRPCClientConfig
+	NotifService GRPCClientConfig
+	Wallet       WalletClientConfig
+	// AuthMode is one of AuthModeRemote, AuthModeLocal or AuthModeHybrid.
+	AuthMode string
+	// Verifier checks tokens locally, without it every token is validated by
+	// the user service.
+	Verifier *auth.Verifier
+	// TokenCache, when set, caches validated tokens.
+	TokenCache *auth.TokenCache
 }
 
-func NewExternal() External {
-	return External{
-		Notif: &notif{},
-		Wallet: &wallet{
-			httpClient: &http.Client{
-				Timeout: 10 * time.Second,
-			},
+// NewExternal creates the clients of the other services. The gRPC
+// connections are shared by every call until Close.
+func NewExternal(cfg Config) (External, error) {
+	userConn, err := newClientConn("token.TokenService", cfg.UserService)
+	if err != nil {
+		return External{}, err
+	}
+
+	notifConn, err := newClientConn("notification.NotificationService", cfg.NotifService)
+	if err != nil {
+		userConn.Close()
+		return External{}, err
+	}
+
+	tokenClient := token.NewTokenServiceClient(userConn)
+	external := External{
+		Notif: &notif{
+			client:  notification.NewNotificationServiceClient(notifConn),
+			timeout: cfg.NotifService.Timeout,
 		},
-		Validation: &Validation{},
+		Wallet:  newWallet(cfg.Wallet),
+		Webhook: newWebhook(10 * time.Second),
+		Validation: &Validation{
+			client:   tokenClient,
+			timeout:  cfg.UserService.Timeout,
+			mode:     cfg.AuthMode,
+			verifier: cfg.Verifier,
+		},
+		tokenClient: tokenClient,
+		conns:       []*grpc.ClientConn{userConn, notifConn},
+	}
+
+	if cfg.TokenCache != nil {
+		external.Validation = &cachedValidation{
+			next:  external.Validation,
+			cache: cfg.TokenCache,
+		}
+	}
+
+	return external, nil
+}
+
+// Close closes the gRPC connections, calls in flight fail.
+func (e External) Close() error {
+	var errs []error
+	for _, conn := range e.conns {
+		if err := conn.Close(); err != nil {
+			errs = append(errs, err)
+		}
 	}
+	return errors.Join(errs...)
 }
@@ -0,0 +1,125 @@
+package external
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"time"
+
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/keepalive"
+)
+
+type TLSConfig struct {
+	Enabled bool
+	// CAFile verifies the server, the system pool is used when empty.
+	CAFile string
+	// CertFile and KeyFile present a client certificate for mTLS.
+	CertFile   string
+	KeyFile    string
+	ServerName string
+}
+
+type GRPCClientConfig struct {
+	Addr string
+	// Timeout is the deadline of every call, streams are not bounded by it.
+	Timeout          time.Duration
+	KeepaliveTime    time.Duration
+	KeepaliveTimeout time.Duration
+	// MaxAttempts is how many times a call failing with UNAVAILABLE is tried.
+	MaxAttempts int
+	TLS         TLSConfig
+}
+
+func (c TLSConfig) credentials() (credentials.TransportCredentials, error) {
+	if !c.Enabled {
+		return insecure.NewCredentials(), nil
+	}
+
+	config := &tls.Config{
+		ServerName: c.ServerName,
+		MinVersion: tls.VersionTLS12,
+	}
+
+	if c.CAFile != "" {
+		ca, err := os.ReadFile(c.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read ca file :%w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(ca) {
+			return nil, fmt.Errorf("no certificate found in %s", c.CAFile)
+		}
+		config.RootCAs = pool
+	}
+
+	if c.CertFile != "" || c.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate :%w", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+
+	return credentials.NewTLS(config), nil
+}
+
+// retryServiceConfig retries the calls of service that fail with
+// UNAVAILABLE, which the server never started processing.
+func retryServiceConfig(service string, maxAttempts int) string {
+	if maxAttempts < 2 {
+		return ""
+	}
+	// gRPC caps attempts at 5
+	maxAttempts = min(maxAttempts, 5)
+
+	return fmt.Sprintf(`{
+		"methodConfig": [{
+			"name": [{"service": %q}],
+			"retryPolicy": {
+				"maxAttempts": %d,
+				"initialBackoff": "0.1s",
+				"maxBackoff": "1s",
+				"backoffMultiplier": 2,
+				"retryableStatusCodes": ["UNAVAILABLE"]
+			}
+		}]
+	}`, service, maxAttempts)
+}
+
+// newClientConn creates a long lived connection to service. It connects
+// lazily, so a service that is down at startup doesn't stop us from starting.
+func newClientConn(service string, cfg GRPCClientConfig) (*grpc.ClientConn, error) {
+	if cfg.Addr == "" {
+		return nil, fmt.Errorf("%s address is empty", service)
+	}
+
+	creds, err := cfg.TLS.credentials()
+	if err != nil {
+		return nil, err
+	}
+
+	options := []grpc.DialOption{
+		grpc.WithTransportCredentials(creds),
+		grpc.WithKeepaliveParams(keepalive.ClientParameters{
+			Time:                cfg.KeepaliveTime,
+			Timeout:             cfg.KeepaliveTimeout,
+			PermitWithoutStream: true,
+		}),
+		grpc.WithStatsHandler(otelgrpc.NewClientHandler()),
+	}
+	if serviceConfig := retryServiceConfig(service, cfg.MaxAttempts); serviceConfig != "" {
+		options = append(options, grpc.WithDefaultServiceConfig(serviceConfig))
+	}
+
+	conn, err := grpc.NewClient(cfg.Addr, options...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s client :%w", service, err)
+	}
+
+	return conn, nil
+}
@@ -3,42 +3,44 @@ package external
 import (
 	"context"
 	"fmt"
+	"time"
 
-	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/env"
 	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/external/proto/notification"
-	"github.com/joho/godotenv"
-	"google.golang.org/grpc"
+	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/metrics"
 )
 
 type NotifRequest struct {
 	Recipient    string
 	TemplateName string
 	Placeholder  map[string]string
+	// Channel is "email", "push" or "sms", empty means email.
+	Channel string
 }
 
 type notif struct {
+	client  notification.NotificationServiceClient
+	timeout time.Duration
 }
 
 func (n *notif) SendNotification(ctx context.Context, req NotifRequest) error {
-	if err := godotenv.Load(); err != nil {
-		return err
-	}
-	// connection to grpc
-	conn, err := grpc.Dial(env.GetEnvString("NOTIF_SERVICE", ""), grpc.WithInsecure())
-	if err != nil {
-		return fmt.Errorf("failed to dial user service grpc :%w", err)
-	}
-	defer conn.Close()
+	start := time.Now()
+	err := n.send(ctx, req)
+	metrics.ObserveClient(metrics.ClientNotification, "send", metrics.Result(err), start)
+	return err
+}
 
-	client := notification.NewNotificationServiceClient(conn)
+func (n *notif) send(ctx context.Context, req NotifRequest) error {
+	ctx, cancel := context.WithTimeout(ctx, n.timeout)
+	defer cancel()
 
 	request := &notification.SendNotificationRequest{
 		Recipient:    req.Recipient,
 		TemplateName: req.TemplateName,
 		Placeholder:  req.Placeholder,
+		Channel:      req.Channel,
 	}
 
-	resp, err := client.SendNotification(ctx, request)
+	resp, err := n.client.SendNotification(ctx, request)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,98 @@
+package external
+
+import (
+	"context"
+	"time"
+
+	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/auth"
+	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/external/proto/token"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	revocationReconnectDelay = 5 * time.Second
+	// revocationStableAfter is how long a stream stays open before it is
+	// trusted, a user service without the rpc fails it right away
+	revocationStableAfter = 5 * time.Second
+)
+
+// WatchRevocations evicts tokens from the cache as the user service revokes
+// them, until ctx is cancelled. After a reconnect the stream resumes from the
+// last revocation seen, so tokens revoked meanwhile are evicted too. The cache
+// fails closed: it is suspended until the stream is up and whenever it is
+// lost, including when the user service doesn't implement it.
+func (e External) WatchRevocations(ctx context.Context, cache *auth.TokenCache, logger *logrus.Logger) {
+	since := time.Now()
+
+	for {
+		cache.Suspend()
+		err := watchRevocations(ctx, e.tokenClient, cache, &since, logger)
+		if ctx.Err() != nil {
+			return
+		}
+		logger.WithError(err).Errorf("token revocation stream lost, token cache suspended, retrying in %v", revocationReconnectDelay)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(revocationReconnectDelay):
+		}
+	}
+}
+
+func watchRevocations(ctx context.Context, client token.TokenServiceClient, cache *auth.TokenCache, since *time.Time, logger *logrus.Logger) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stream, err := client.WatchRevocations(ctx, &token.WatchRevocationsRequest{
+		Since: timestamppb.New(*since),
+	})
+	if err != nil {
+		return err
+	}
+
+	// an open stream only reports failures on Recv, so it is received on its
+	// own goroutine while this one resumes the cache once it looks healthy
+	revocations := make(chan *token.Revocation)
+	errs := make(chan error, 1)
+	go func() {
+		for {
+			revocation, err := stream.Recv()
+			if err != nil {
+				errs <- err
+				return
+			}
+			select {
+			case revocations <- revocation:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	stable := time.NewTimer(revocationStableAfter)
+	defer stable.Stop()
+
+	for {
+		select {
+		case err := <-errs:
+			return err
+
+		case <-stable.C:
+			cache.Resume()
+			logger.Info("token revocation stream connected, token cache resumed")
+
+		case revocation := <-revocations:
+			if revocation.TokenHash != "" {
+				cache.Revoke(revocation.TokenHash)
+			} else {
+				cache.RevokeUser(revocation.UserId)
+			}
+
+			if revokedAt := revocation.RevokedAt.AsTime(); revokedAt.After(*since) {
+				*since = revokedAt
+			}
+		}
+	}
+}
@@ -2,31 +2,81 @@ package external
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"time"
 
+	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/apperror"
+	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/auth"
 	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/external/proto/token"
+	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/metrics"
 	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/model"
-	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
-type Validation struct{}
+// Token validation modes.
+const (
+	// AuthModeRemote asks the user service about every token.
+	AuthModeRemote = "remote"
+	// AuthModeLocal verifies tokens against the JWKS only.
+	AuthModeLocal = "local"
+	// AuthModeHybrid verifies tokens against the JWKS, then asks the user
+	// service whether a valid token has been revoked.
+	AuthModeHybrid = "hybrid"
+)
+
+// ErrTokenServiceUnavailable is returned when the user service could not be
+// asked about a token, which says nothing about the token itself.
+var ErrTokenServiceUnavailable = apperror.UpstreamUnavailable("upstream_unavailable", "authentication service unavailable, try again later")
+
+type Validation struct {
+	client   token.TokenServiceClient
+	timeout  time.Duration
+	mode     string
+	verifier *auth.Verifier
+}
 
 func (u *Validation) ValidateToken(ctx context.Context, tokenReq string) (model.TokenResponse, error) {
-	// connection to grpc
-	conn, err := grpc.Dial("localhost:5000", grpc.WithInsecure())
-	if err != nil {
-		return model.TokenResponse{}, fmt.Errorf("failed to dial user service grpc :%w", err)
+	if u.verifier == nil || u.mode == AuthModeRemote {
+		return u.validateRemote(ctx, tokenReq)
 	}
-	defer conn.Close()
 
-	client := token.NewTokenServiceClient(conn)
+	data, err := u.verifier.Verify(ctx, tokenReq)
+	switch {
+	case errors.Is(err, auth.ErrKeyUnavailable):
+		// the JWKS can't vouch for the token, let the user service decide
+		return u.validateRemote(ctx, tokenReq)
+	case err != nil:
+		return model.TokenResponse{}, err
+	case u.mode == AuthModeHybrid:
+		// a bad token is rejected above without a round trip
+		return u.validateRemote(ctx, tokenReq)
+	}
+
+	return data, nil
+}
+
+func (u *Validation) validateRemote(ctx context.Context, tokenReq string) (model.TokenResponse, error) {
+	start := time.Now()
+	data, err := u.validate(ctx, tokenReq)
+	metrics.ObserveClient(metrics.ClientUserService, "validate_token", metrics.Result(err), start)
+	return data, err
+}
+
+func (u *Validation) validate(ctx context.Context, tokenReq string) (model.TokenResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, u.timeout)
+	defer cancel()
 
 	req := token.TokenRequest{
 		Token: tokenReq,
 	}
 
-	response, err := client.Validate(ctx, &req)
+	response, err := u.client.Validate(ctx, &req)
 	if err != nil {
+		if code := status.Code(err); code == codes.Unavailable || code == codes.DeadlineExceeded {
+			return model.TokenResponse{}, ErrTokenServiceUnavailable.Wrap(err)
+		}
 		return model.TokenResponse{}, fmt.Errorf("failed to validate token : %w", err)
 	}
 
@@ -37,5 +87,33 @@ func (u *Validation) ValidateToken(ctx context.Context, tokenReq string) (model.
 	return model.TokenResponse{
 		UserID: response.Data.Id,
 		Email:  response.Data.Email,
+		Role:   response.Data.Role,
+		Scopes: response.Data.Scopes,
 	}, nil
 }
+
+// cachedValidation answers repeated validations of a token from the cache.
+// Failed validations are not cached.
+type cachedValidation struct {
+	next interface {
+		ValidateToken(context.Context, string) (model.TokenResponse, error)
+	}
+	cache *auth.TokenCache
+}
+
+func (v *cachedValidation) ValidateToken(ctx context.Context, tokenReq string) (model.TokenResponse, error) {
+	hash := auth.TokenHash(tokenReq)
+	if data, ok := v.cache.Get(hash); ok {
+		return data, nil
+	}
+
+	generation := v.cache.Generation()
+	data, err := v.next.ValidateToken(ctx, tokenReq)
+	if err != nil {
+		return model.TokenResponse{}, err
+	}
+
+	expiry, _ := auth.TokenExpiry(tokenReq)
+	v.cache.Set(hash, data, expiry, generation)
+	return data, nil
+}
@@ -4,14 +4,21 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
-	"log"
+	"math/rand/v2"
+	"net"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
-	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/env"
-	"github.com/joho/godotenv"
+	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/metrics"
+	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/tracing"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 )
 
 type WalletResponse struct {
@@ -21,116 +28,404 @@ type WalletResponse struct {
 	CreatedAt time.Time `json:"created_at"`
 }
 
+// WalletRequest moves money in the wallet of UserID. Movements are
+// authenticated with the service token, which the wallet trusts to act for
+// any user, so the wallet moved never depends on whose token the caller holds.
 type WalletRequest struct {
+	UserID    int32   `json:"user_id"`
 	Amount    float64 `json:"amount"`
 	Reference string  `json:"reference"`
 	Status    string  `json:"status"`
 }
 
+// WalletEntry is a single movement recorded by the wallet service.
+type WalletEntry struct {
+	Reference string    `json:"reference"`
+	Amount    float64   `json:"amount"`
+	Type      string    `json:"type"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Wallet operations, used in errors and stats.
+const (
+	WalletOpCredit      = "credit"
+	WalletOpDebit       = "debit"
+	WalletOpListEntries = "list_entries"
+)
+
+type WalletClientConfig struct {
+	// BaseURL is the wallet service address with its base path.
+	BaseURL string
+	// Timeout bounds a single attempt.
+	Timeout     time.Duration
+	MaxAttempts int
+	RetryBase   time.Duration
+	RetryMax    time.Duration
+	// BreakerThreshold consecutive failures open the circuit for
+	// BreakerCooldown, 0 disables the breaker.
+	BreakerThreshold int
+	BreakerCooldown  time.Duration
+}
+
+// WalletErrorKind tells the caller what a failed wallet call means for the
+// money.
+type WalletErrorKind int
+
+const (
+	// WalletErrorTerminal means the wallet rejected the call, retrying it
+	// won't help and nothing was applied.
+	WalletErrorTerminal WalletErrorKind = iota
+	// WalletErrorRetryable means the call was not applied and can be tried
+	// again later.
+	WalletErrorRetryable
+	// WalletErrorUnknownOutcome means the wallet may have applied the call.
+	// Retrying with the same reference is safe, the idempotency key makes the
+	// wallet return the first result.
+	WalletErrorUnknownOutcome
+)
+
+func (k WalletErrorKind) String() string {
+	switch k {
+	case WalletErrorRetryable:
+		return "retryable"
+	case WalletErrorUnknownOutcome:
+		return "unknown outcome"
+	default:
+		return "terminal"
+	}
+}
+
+// ErrCircuitOpen is returned without calling the wallet while it keeps
+// failing.
+var ErrCircuitOpen = errors.New("wallet service circuit open")
+
+type WalletError struct {
+	Op   string
+	Kind WalletErrorKind
+	// StatusCode is 0 when no response was received.
+	StatusCode int
+	Message    string
+	Err        error
+}
+
+func (e *WalletError) Error() string {
+	if e.StatusCode != 0 {
+		return fmt.Sprintf("wallet %s failed (%s), status %d :%s", e.Op, e.Kind, e.StatusCode, e.Message)
+	}
+	return fmt.Sprintf("wallet %s failed (%s) :%v", e.Op, e.Kind, e.Err)
+}
+
+func (e *WalletError) Unwrap() error {
+	return e.Err
+}
+
+// WalletErrorKindOf returns the kind of a wallet error anywhere in err's chain,
+// any other error is terminal.
+func WalletErrorKindOf(err error) WalletErrorKind {
+	var walletErr *WalletError
+	if errors.As(err, &walletErr) {
+		return walletErr.Kind
+	}
+	return WalletErrorTerminal
+}
+
+// WalletOperationStats counts the calls of one operation since startup.
+type WalletOperationStats struct {
+	Calls          uint64
+	Attempts       uint64
+	Succeeded      uint64
+	Failed         uint64
+	UnknownOutcome uint64
+	// Rejected calls were refused by the open circuit.
+	Rejected     uint64
+	TotalLatency time.Duration
+}
+
+type WalletStats struct {
+	Operations  map[string]WalletOperationStats
+	CircuitOpen bool
+}
+
 type wallet struct {
 	httpClient *http.Client
+	cfg        WalletClientConfig
+	breaker    *breaker
+
+	mu    sync.Mutex
+	stats map[string]*WalletOperationStats
 }
 
-func (w *wallet) Credit(ctx context.Context, reqData WalletRequest, token string) (*WalletResponse, error) {
-	if err := godotenv.Load(); err != nil {
-		return nil, err
+func newWallet(cfg WalletClientConfig) *wallet {
+	return &wallet{
+		httpClient: &http.Client{
+			Timeout: cfg.Timeout,
+			// a span per attempt, carrying the trace context to the wallet
+			Transport: otelhttp.NewTransport(http.DefaultTransport),
+		},
+		cfg:     cfg,
+		breaker: newBreaker(cfg.BreakerThreshold, cfg.BreakerCooldown),
+		stats:   map[string]*WalletOperationStats{},
 	}
+}
 
-	url := env.GetEnvString("WALLET_SERVICE", "") + env.GetEnvString("WALLET_BASE_PATH", "") + "/credit"
+// idempotencyKey is the same for every retry of an operation on a reference,
+// the status is part of it since a reference can be credited and later
+// debited back. References are unique per transaction, so two transactions
+// never share a key.
+func idempotencyKey(op string, reqData WalletRequest) string {
+	return fmt.Sprintf("%s-%s-%s", op, reqData.Reference, strings.ToLower(reqData.Status))
+}
+
+func (w *wallet) Credit(ctx context.Context, reqData WalletRequest, token string) (*WalletResponse, error) {
+	return w.move(ctx, WalletOpCredit, "/credit", reqData, token)
+}
+
+func (w *wallet) Debit(ctx context.Context, reqData WalletRequest, token string) (*WalletResponse, error) {
+	return w.move(ctx, WalletOpDebit, "/debit", reqData, token)
+}
 
+func (w *wallet) move(ctx context.Context, op, path string, reqData WalletRequest, token string) (*WalletResponse, error) {
 	payload := WalletRequest{
+		UserID:    reqData.UserID,
 		Amount:    reqData.Amount,
 		Reference: reqData.Reference,
 		Status:    reqData.Status,
 	}
 
-	log.Println(reqData.Status, reqData.Amount)
-	log.Println(url)
-
 	jsonData, err := json.Marshal(payload)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal wallet payload :%w", err)
 	}
 
-	req, err := http.NewRequestWithContext(ctx, "PUT", url, bytes.NewBuffer(jsonData))
+	body, err := w.do(ctx, op, http.MethodPut, path, jsonData, idempotencyKey(op, reqData), token)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request ;%w", err)
+		return nil, err
 	}
 
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+token)
-
-	resp, err := w.httpClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create wallet :%w", err)
+	var walletResp WalletResponse
+	if err := json.Unmarshal(body, &walletResp); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response :%w", err)
 	}
-	defer resp.Body.Close()
+	return &walletResp, nil
+}
 
-	body, err := io.ReadAll(resp.Body)
+func (w *wallet) ListEntries(ctx context.Context, from, to time.Time, token string) ([]WalletEntry, error) {
+	query := url.Values{}
+	query.Set("from", from.Format(time.RFC3339))
+	query.Set("to", to.Format(time.RFC3339))
+
+	body, err := w.do(ctx, WalletOpListEntries, http.MethodGet, "/entries?"+query.Encode(), nil, "", token)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response body :%w", err)
+		return nil, err
 	}
 
-	log.Println("response body", string(body))
-	if resp.StatusCode != http.StatusCreated {
-		return nil, fmt.Errorf("wallet service returned error :%s", string(body))
+	var entries []WalletEntry
+	if err := json.Unmarshal(body, &entries); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response :%w", err)
 	}
+	return entries, nil
+}
 
-	var walletResp WalletResponse
-	if err := json.Unmarshal(body, &walletResp); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal response :%w", err)
+func (w *wallet) Stats() WalletStats {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	stats := WalletStats{
+		Operations:  make(map[string]WalletOperationStats, len(w.stats)),
+		CircuitOpen: w.breaker.open(),
 	}
-	return &walletResp, nil
+	for op, s := range w.stats {
+		stats.Operations[op] = *s
+	}
+	return stats
 }
 
-func (w *wallet) Debit(ctx context.Context, reqData WalletRequest, token string) (*WalletResponse, error) {
-	if err := godotenv.Load(); err != nil {
-		return nil, err
+func (w *wallet) record(op string, update func(*WalletOperationStats)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	s, ok := w.stats[op]
+	if !ok {
+		s = &WalletOperationStats{}
+		w.stats[op] = s
 	}
+	update(s)
+}
 
-	url := env.GetEnvString("WALLET_SERVICE", "") + env.GetEnvString("WALLET_BASE_PATH", "") + "/debit"
+// do sends the request until it succeeds, fails terminally or runs out of
+// attempts, and returns the response body. Once an attempt had an unknown
+// outcome the call stays unknown unless a later attempt gets an answer.
+func (w *wallet) do(ctx context.Context, op, method, path string, data []byte, key, token string) ([]byte, error) {
+	ctx, span := tracing.Start(ctx, "wallet."+op)
+	start := time.Now()
+	w.record(op, func(s *WalletOperationStats) { s.Calls++ })
 
-	payload := WalletRequest{
-		Amount:    reqData.Amount,
-		Reference: reqData.Reference,
-		Status:    reqData.Status,
+	var (
+		lastErr *WalletError
+		unknown bool
+	)
+	for attempt := 1; ; attempt++ {
+		if !w.breaker.allow() {
+			w.record(op, func(s *WalletOperationStats) { s.Rejected++ })
+			lastErr = &WalletError{Op: op, Kind: WalletErrorRetryable, Err: ErrCircuitOpen}
+			break
+		}
+
+		w.record(op, func(s *WalletOperationStats) { s.Attempts++ })
+		body, retryAfter, err := w.attempt(ctx, op, method, path, data, key, token)
+		if err == nil {
+			w.breaker.success()
+			w.record(op, func(s *WalletOperationStats) {
+				s.Succeeded++
+				s.TotalLatency += time.Since(start)
+			})
+			metrics.ObserveClient(metrics.ClientWallet, op, metrics.ResultSuccess, start)
+			tracing.End(span, nil)
+			return body, nil
+		}
+
+		lastErr = err
+		if err.Kind == WalletErrorTerminal {
+			// the wallet answered, it is up
+			w.breaker.success()
+			unknown = false
+			break
+		}
+		w.breaker.failure()
+		if err.Kind == WalletErrorUnknownOutcome {
+			unknown = true
+		}
+
+		if attempt >= w.cfg.MaxAttempts || ctx.Err() != nil {
+			break
+		}
+
+		delay := w.backoff(attempt)
+		if retryAfter > 0 {
+			delay = min(retryAfter, w.cfg.RetryMax)
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+		case <-timer.C:
+		}
+		if ctx.Err() != nil {
+			break
+		}
 	}
 
-	log.Println(reqData.Status, reqData.Amount)
-	//log.Println(url)
+	if unknown && lastErr.Kind == WalletErrorRetryable {
+		lastErr.Kind = WalletErrorUnknownOutcome
+	}
 
-	jsonData, err := json.Marshal(payload)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal wallet payload :%w", err)
+	w.record(op, func(s *WalletOperationStats) {
+		s.Failed++
+		if lastErr.Kind == WalletErrorUnknownOutcome {
+			s.UnknownOutcome++
+		}
+		s.TotalLatency += time.Since(start)
+	})
+	metrics.ObserveClient(metrics.ClientWallet, op, walletResult(lastErr), start)
+	tracing.End(span, lastErr)
+	return nil, lastErr
+}
+
+// walletResult labels a failed call in the metrics.
+func walletResult(err *WalletError) string {
+	switch {
+	case errors.Is(err, ErrCircuitOpen):
+		return "rejected"
+	case err.Kind == WalletErrorUnknownOutcome:
+		return "unknown_outcome"
+	default:
+		return metrics.ResultError
+	}
+}
+
+// attempt sends the request once. retryAfter is the delay the wallet asked
+// for, if any.
+func (w *wallet) attempt(ctx context.Context, op, method, path string, data []byte, key, token string) ([]byte, time.Duration, *WalletError) {
+	var reqBody io.Reader
+	if data != nil {
+		reqBody = bytes.NewReader(data)
 	}
 
-	req, err := http.NewRequestWithContext(ctx, "PUT", url, bytes.NewBuffer(jsonData))
+	req, err := http.NewRequestWithContext(ctx, method, w.cfg.BaseURL+path, reqBody)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request ;%w", err)
+		return nil, 0, &WalletError{Op: op, Kind: WalletErrorTerminal, Err: fmt.Errorf("failed to create request :%w", err)}
 	}
 
-	req.Header.Set("Content-Type", "application/json")
+	if data != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if key != "" {
+		req.Header.Set("Idempotency-Key", key)
+	}
 	req.Header.Set("Authorization", "Bearer "+token)
 
 	resp, err := w.httpClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create wallet :%w", err)
+		return nil, 0, &WalletError{Op: op, Kind: transportErrorKind(err), Err: err}
 	}
 	defer resp.Body.Close()
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response body :%w", err)
+		return nil, 0, &WalletError{Op: op, Kind: WalletErrorUnknownOutcome, StatusCode: resp.StatusCode, Err: fmt.Errorf("failed to read response body :%w", err)}
 	}
 
-	log.Println("response body", string(body))
-	if resp.StatusCode != http.StatusCreated {
-		return nil, fmt.Errorf("wallet service returned error :%s", string(body))
+	if resp.StatusCode >= 200 && resp.StatusCode <= 299 {
+		return body, 0, nil
 	}
 
-	var walletResp WalletResponse
-	if err := json.Unmarshal(body, &walletResp); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal response :%w", err)
+	return nil, retryAfter(resp.Header.Get("Retry-After")), &WalletError{
+		Op:         op,
+		Kind:       statusErrorKind(resp.StatusCode),
+		StatusCode: resp.StatusCode,
+		Message:    string(body),
 	}
-	return &walletResp, nil
+}
+
+// transportErrorKind is retryable only when the request never reached the
+// wallet.
+func transportErrorKind(err error) WalletErrorKind {
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "dial" {
+		return WalletErrorRetryable
+	}
+	return WalletErrorUnknownOutcome
+}
+
+func statusErrorKind(code int) WalletErrorKind {
+	switch code {
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+		return WalletErrorRetryable
+	case http.StatusRequestTimeout, http.StatusInternalServerError, http.StatusBadGateway, http.StatusGatewayTimeout:
+		return WalletErrorUnknownOutcome
+	default:
+		return WalletErrorTerminal
+	}
+}
+
+func retryAfter(header string) time.Duration {
+	seconds, err := strconv.Atoi(header)
+	if err != nil || seconds < 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// backoff doubles the delay after every attempt with full jitter, so clients
+// that failed together don't retry together.
+func (w *wallet) backoff(attempt int) time.Duration {
+	delay := w.cfg.RetryBase << (attempt - 1)
+	if delay <= 0 || delay > w.cfg.RetryMax {
+		delay = w.cfg.RetryMax
+	}
+	if delay <= 0 {
+		return 0
+	}
+	return rand.N(delay)
 }
@@ -0,0 +1,200 @@
+package external
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// walletServer answers the calls with statuses in order, the last one is
+// repeated, and records the idempotency key of every call.
+type walletServer struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	statuses []int
+	keys     []string
+}
+
+func newWalletServer(t *testing.T, statuses ...int) *walletServer {
+	s := &walletServer{statuses: statuses}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		status := s.statuses[min(len(s.keys), len(s.statuses)-1)]
+		s.keys = append(s.keys, r.Header.Get("Idempotency-Key"))
+		s.mu.Unlock()
+
+		w.WriteHeader(status)
+		if status == http.StatusOK {
+			io.WriteString(w, `{"user_id": 7, "amount": 5000, "reference": "REF"}`)
+		}
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *walletServer) calls() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]string(nil), s.keys...)
+}
+
+func testWallet(baseURL string, maxAttempts, breakerThreshold int) *wallet {
+	return newWallet(WalletClientConfig{
+		BaseURL:          baseURL,
+		Timeout:          time.Second,
+		MaxAttempts:      maxAttempts,
+		RetryBase:        time.Millisecond,
+		RetryMax:         2 * time.Millisecond,
+		BreakerThreshold: breakerThreshold,
+		BreakerCooldown:  time.Hour,
+	})
+}
+
+func TestStatusErrorKind(t *testing.T) {
+	tests := []struct {
+		code int
+		want WalletErrorKind
+	}{
+		{http.StatusTooManyRequests, WalletErrorRetryable},
+		{http.StatusServiceUnavailable, WalletErrorRetryable},
+		{http.StatusRequestTimeout, WalletErrorUnknownOutcome},
+		{http.StatusInternalServerError, WalletErrorUnknownOutcome},
+		{http.StatusBadGateway, WalletErrorUnknownOutcome},
+		{http.StatusGatewayTimeout, WalletErrorUnknownOutcome},
+		{http.StatusBadRequest, WalletErrorTerminal},
+		{http.StatusUnauthorized, WalletErrorTerminal},
+		{http.StatusConflict, WalletErrorTerminal},
+		{http.StatusUnprocessableEntity, WalletErrorTerminal},
+	}
+
+	for _, tt := range tests {
+		if got := statusErrorKind(tt.code); got != tt.want {
+			t.Errorf("statusErrorKind(%d) = %s, want %s", tt.code, got, tt.want)
+		}
+	}
+}
+
+func TestTransportErrorKind(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want WalletErrorKind
+	}{
+		{name: "dial", err: &net.OpError{Op: "dial", Err: errors.New("connection refused")}, want: WalletErrorRetryable},
+		{name: "read", err: &net.OpError{Op: "read", Err: errors.New("connection reset by peer")}, want: WalletErrorUnknownOutcome},
+		{name: "write", err: &net.OpError{Op: "write", Err: errors.New("broken pipe")}, want: WalletErrorUnknownOutcome},
+		{name: "eof", err: io.ErrUnexpectedEOF, want: WalletErrorUnknownOutcome},
+		{name: "timeout", err: context.DeadlineExceeded, want: WalletErrorUnknownOutcome},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := transportErrorKind(tt.err); got != tt.want {
+				t.Errorf("transportErrorKind() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWalletRetries(t *testing.T) {
+	tests := []struct {
+		name         string
+		statuses     []int
+		wantAttempts int
+		wantErr      bool
+		wantKind     WalletErrorKind
+	}{
+		{name: "success", statuses: []int{200}, wantAttempts: 1},
+		{name: "unavailable then success", statuses: []int{503, 200}, wantAttempts: 2},
+		{name: "server error then success", statuses: []int{500, 200}, wantAttempts: 2},
+		{name: "rate limited until out of attempts", statuses: []int{429}, wantAttempts: 3, wantErr: true, wantKind: WalletErrorRetryable},
+		{name: "unknown outcome is kept", statuses: []int{502, 503}, wantAttempts: 3, wantErr: true, wantKind: WalletErrorUnknownOutcome},
+		{name: "rejected is not retried", statuses: []int{400}, wantAttempts: 1, wantErr: true, wantKind: WalletErrorTerminal},
+		{name: "answer after unknown outcome", statuses: []int{504, 422}, wantAttempts: 2, wantErr: true, wantKind: WalletErrorTerminal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newWalletServer(t, tt.statuses...)
+			w := testWallet(srv.URL, 3, 0)
+
+			_, err := w.Credit(context.Background(), WalletRequest{
+				UserID:    7,
+				Amount:    5000,
+				Reference: "REF",
+				Status:    "SUCCESS",
+			}, "token")
+
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("Credit() succeeded, want an error")
+				}
+				if got := WalletErrorKindOf(err); got != tt.wantKind {
+					t.Errorf("error kind = %s, want %s", got, tt.wantKind)
+				}
+			} else if err != nil {
+				t.Fatalf("Credit() error = %v", err)
+			}
+
+			keys := srv.calls()
+			if len(keys) != tt.wantAttempts {
+				t.Fatalf("attempts = %d, want %d", len(keys), tt.wantAttempts)
+			}
+			for i, key := range keys {
+				if key != "credit-REF-success" {
+					t.Errorf("attempt %d Idempotency-Key = %q, want %q", i+1, key, "credit-REF-success")
+				}
+			}
+		})
+	}
+}
+
+func TestWalletDialFailureIsRetryable(t *testing.T) {
+	// a closed listener's address refuses the connection
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+
+	w := testWallet("http://"+addr, 2, 0)
+	_, err = w.Debit(context.Background(), WalletRequest{UserID: 7, Amount: 5000, Reference: "REF", Status: "REVERSED"}, "token")
+	if got := WalletErrorKindOf(err); got != WalletErrorRetryable {
+		t.Fatalf("error kind = %s, want %s (err %v)", got, WalletErrorRetryable, err)
+	}
+	if got := w.Stats().Operations[WalletOpDebit].Attempts; got != 2 {
+		t.Errorf("attempts = %d, want 2", got)
+	}
+}
+
+func TestWalletBreakerRejectsWithoutCalling(t *testing.T) {
+	srv := newWalletServer(t, http.StatusServiceUnavailable)
+	w := testWallet(srv.URL, 1, 2)
+
+	req := WalletRequest{UserID: 7, Amount: 5000, Reference: "REF", Status: "SUCCESS"}
+	for i := 0; i < 2; i++ {
+		if _, err := w.Credit(context.Background(), req, "token"); err == nil {
+			t.Fatalf("call %d succeeded against a failing wallet", i+1)
+		}
+	}
+
+	_, err := w.Credit(context.Background(), req, "token")
+	if !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("error = %v, want %v", err, ErrCircuitOpen)
+	}
+	if got := len(srv.calls()); got != 2 {
+		t.Errorf("wallet calls = %d, want 2, the open circuit must not call it", got)
+	}
+	if !w.Stats().CircuitOpen {
+		t.Error("Stats().CircuitOpen = false, want true")
+	}
+}
@@ -0,0 +1,135 @@
+package external
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// ErrWebhookAddress is returned when a webhook URL leads inside the network,
+// e.g. to loopback, a private range or the cloud metadata service.
+var ErrWebhookAddress = errors.New("webhook address not allowed")
+
+// sharedAddressSpace is the carrier-grade NAT range, not public but not
+// reported as private either.
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+// WebhookRequest is a single signed delivery to a registered endpoint.
+type WebhookRequest struct {
+	URL        string
+	Secret     string
+	DeliveryID int32
+	Event      string
+	Payload    []byte
+}
+
+type webhook struct {
+	httpClient *http.Client
+}
+
+// newWebhook returns a client that only reaches public addresses. The check
+// runs on the address dialed, after the host is resolved for the delivery, so
+// a name resolving to an internal address later is refused as well. Redirects
+// are not followed, the redirect response is the result of the delivery.
+func newWebhook(timeout time.Duration) *webhook {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: controlWebhookDial,
+	}
+
+	return &webhook{
+		httpClient: &http.Client{
+			Timeout: timeout,
+			// no proxy, it would be the address dialed instead of the endpoint
+			Transport: &http.Transport{
+				DialContext:         dialer.DialContext,
+				ForceAttemptHTTP2:   true,
+				MaxIdleConns:        100,
+				IdleConnTimeout:     90 * time.Second,
+				TLSHandshakeTimeout: timeout,
+			},
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+	}
+}
+
+func controlWebhookDial(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+
+	addr, err := netip.ParseAddr(host)
+	if err != nil || !publicAddress(addr) {
+		return fmt.Errorf("%w :%s", ErrWebhookAddress, host)
+	}
+	return nil
+}
+
+// publicAddress reports whether addr is a public unicast address. Loopback,
+// link-local (which holds the metadata service), multicast and unspecified
+// addresses aren't global unicast.
+func publicAddress(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	return addr.IsGlobalUnicast() && !addr.IsPrivate() && !sharedAddressSpace.Contains(addr)
+}
+
+// SignWebhook returns the signature header value for a payload. The receiver
+// recomputes HMAC-SHA256 over "<timestamp>.<body>" with its secret and rejects
+// timestamps that are too old to prevent replays.
+func SignWebhook(secret string, timestamp int64, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return fmt.Sprintf("t=%d,v1=%s", timestamp, hex.EncodeToString(mac.Sum(nil)))
+}
+
+// Send posts the payload and returns the status code of the response, which is
+// 0 when no response was received. Any non 2xx response is an error.
+func (w *webhook) Send(ctx context.Context, reqData WebhookRequest) (int, error) {
+	// endpoints registered before https was required fail until they are
+	// replaced
+	if u, err := url.Parse(reqData.URL); err != nil || u.Scheme != "https" {
+		return 0, fmt.Errorf("%w :only https endpoints are delivered to", ErrWebhookAddress)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", reqData.URL, bytes.NewReader(reqData.Payload))
+	if err != nil {
+		return 0, fmt.Errorf("failed to create request ;%w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Webhook-Id", strconv.Itoa(int(reqData.DeliveryID)))
+	req.Header.Set("X-Webhook-Event", reqData.Event)
+	req.Header.Set("X-Webhook-Signature", SignWebhook(reqData.Secret, time.Now().Unix(), reqData.Payload))
+
+	resp, err := w.httpClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to deliver webhook :%w", err)
+	}
+	defer resp.Body.Close()
+
+	// only a short excerpt is kept in the delivery log
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("endpoint returned status %d :%s", resp.StatusCode, string(body))
+	}
+
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,234 @@
+package handler
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/model"
+	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/service"
+	"github.com/gofiber/fiber/v2"
+)
+
+type AdminHandler struct {
+	service service.Service
+}
+
+// queryTime reads an optional RFC 3339 query parameter.
+func queryTime(ctx *fiber.Ctx, key string) (time.Time, error) {
+	raw := ctx.Query(key)
+	if raw == "" {
+		return time.Time{}, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%s must be an RFC 3339 time", key)
+	}
+	return t, nil
+}
+
+func (h *AdminHandler) SearchTransactions(ctx *fiber.Ctx) error {
+	data := ctx.Locals("token").(model.TokenResponse)
+	payload := new(model.AdminSearchTransactions)
+	limit := ctx.QueryInt("limit", 20)
+	offset := ctx.QueryInt("offset", 1)
+
+	from, err := queryTime(ctx, "from")
+	if err != nil {
+		return ErrInvalidQuery.Messagef("%v", err)
+	}
+
+	to, err := queryTime(ctx, "to")
+	if err != nil {
+		return ErrInvalidQuery.Messagef("%v", err)
+	}
+
+	payload.Admin = data
+	payload.UserID = int32(ctx.QueryInt("user_id", 0))
+	payload.Reference = ctx.Query("reference")
+	payload.TransactionType = ctx.Query("transaction_type")
+	payload.TransactionStatus = ctx.Query("transaction_status")
+	payload.From = from
+	payload.To = to
+	payload.Limit = int32(limit)
+	payload.Offset = int32(offset)
+
+	resp, err := h.service.Admin.SearchTransactions(ctx.UserContext(), payload)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "ok",
+		"data":    resp,
+	})
+}
+
+func (h *AdminHandler) GetTransaction(ctx *fiber.Ctx) error {
+	data := ctx.Locals("token").(model.TokenResponse)
+	payload := &model.AdminGetTransaction{
+		Admin:     data,
+		Reference: ctx.Params("reference"),
+	}
+
+	resp, err := h.service.Admin.GetTransaction(ctx.UserContext(), payload)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "ok",
+		"data":    resp,
+	})
+}
+
+func (h *AdminHandler) ForceStatus(ctx *fiber.Ctx) error {
+	data := ctx.Locals("token").(model.TokenResponse)
+	payload := new(model.AdminStatusPayload)
+
+	if err := ctx.BodyParser(payload); err != nil {
+		return ErrInvalidBody.Wrap(err)
+	}
+
+	payload.Admin = data
+	payload.Reference = ctx.Params("reference")
+
+	if err := payload.Validate(); err != nil {
+		return validationError(err)
+	}
+
+	resp, err := h.service.Admin.ForceStatus(ctx.UserContext(), payload)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "ok",
+		"data":    resp,
+	})
+}
+
+func (h *AdminHandler) Refund(ctx *fiber.Ctx) error {
+	data := ctx.Locals("token").(model.TokenResponse)
+	payload := new(model.AdminRefundPayload)
+
+	if err := ctx.BodyParser(payload); err != nil {
+		return ErrInvalidBody.Wrap(err)
+	}
+
+	payload.Admin = data
+	payload.Reference = ctx.Params("reference")
+
+	if err := payload.Validate(); err != nil {
+		return validationError(err)
+	}
+
+	resp, err := h.service.Admin.Refund(ctx.UserContext(), payload)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "ok",
+		"data":    resp,
+	})
+}
+
+func (h *AdminHandler) ResolveDispute(ctx *fiber.Ctx) error {
+	data := ctx.Locals("token").(model.TokenResponse)
+	payload := new(model.AdminResolveDisputePayload)
+
+	id, err := ctx.ParamsInt("id")
+	if err != nil {
+		return ErrInvalidParam.Messagef("dispute id must be a number")
+	}
+
+	if err := ctx.BodyParser(payload); err != nil {
+		return ErrInvalidBody.Wrap(err)
+	}
+
+	payload.Admin = data
+	payload.DisputeID = int32(id)
+
+	if err := payload.Validate(); err != nil {
+		return validationError(err)
+	}
+
+	resp, err := h.service.Admin.ResolveDispute(ctx.UserContext(), payload)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "ok",
+		"data":    resp,
+	})
+}
+
+func (h *AdminHandler) GetReconciliationReport(ctx *fiber.Ctx) error {
+	data := ctx.Locals("token").(model.TokenResponse)
+
+	id, err := ctx.ParamsInt("id")
+	if err != nil {
+		return ErrInvalidParam.Messagef("report id must be a number")
+	}
+
+	payload := &model.AdminGetReconciliationReport{
+		Admin:    data,
+		ReportID: int32(id),
+	}
+
+	resp, err := h.service.Admin.GetReconciliationReport(ctx.UserContext(), payload)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "ok",
+		"data":    resp,
+	})
+}
+
+func (h *AdminHandler) GetReconciliationReports(ctx *fiber.Ctx) error {
+	data := ctx.Locals("token").(model.TokenResponse)
+	payload := new(model.AdminGetReconciliationReports)
+	limit := ctx.QueryInt("limit", 5)
+	offset := ctx.QueryInt("offset", 1)
+
+	payload.Admin = data
+	payload.Limit = int32(limit)
+	payload.Offset = int32(offset)
+
+	resp, err := h.service.Admin.GetReconciliationReports(ctx.UserContext(), payload)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "ok",
+		"data":    resp,
+	})
+}
+
+func (h *AdminHandler) GetAuditLogs(ctx *fiber.Ctx) error {
+	data := ctx.Locals("token").(model.TokenResponse)
+	payload := new(model.GetAdminAuditLogs)
+	limit := ctx.QueryInt("limit", 20)
+	offset := ctx.QueryInt("offset", 1)
+
+	payload.Admin = data
+	payload.Reference = ctx.Query("reference")
+	payload.AdminID = int32(ctx.QueryInt("admin_id", 0))
+	payload.Limit = int32(limit)
+	payload.Offset = int32(offset)
+
+	resp, err := h.service.Admin.GetAuditLogs(ctx.UserContext(), payload)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "ok",
+		"data":    resp,
+	})
+}
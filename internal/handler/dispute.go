@@ -0,0 +1,112 @@
+package handler
+
+import (
+	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/model"
+	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/service"
+	"github.com/gofiber/fiber/v2"
+)
+
+type DisputeHandler struct {
+	service service.Service
+}
+
+func (h *DisputeHandler) Open(ctx *fiber.Ctx) error {
+	data := ctx.Locals("token").(model.TokenResponse)
+	payload := new(model.DisputePayload)
+
+	if err := ctx.BodyParser(payload); err != nil {
+		return ErrInvalidBody.Wrap(err)
+	}
+
+	payload.Reference = ctx.Params("reference")
+	payload.UserID = data.UserID
+
+	if err := payload.Validate(); err != nil {
+		return validationError(err)
+	}
+
+	resp, err := h.service.Dispute.Open(ctx.UserContext(), payload)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "ok",
+		"data":    resp,
+	})
+}
+
+func (h *DisputeHandler) AddEvidence(ctx *fiber.Ctx) error {
+	data := ctx.Locals("token").(model.TokenResponse)
+	payload := new(model.DisputeEvidencePayload)
+
+	id, err := ctx.ParamsInt("id")
+	if err != nil {
+		return ErrInvalidParam.Messagef("dispute id must be a number")
+	}
+
+	if err := ctx.BodyParser(payload); err != nil {
+		return ErrInvalidBody.Wrap(err)
+	}
+
+	payload.DisputeID = int32(id)
+	payload.UserID = data.UserID
+
+	if err := payload.Validate(); err != nil {
+		return validationError(err)
+	}
+
+	resp, err := h.service.Dispute.AddEvidence(ctx.UserContext(), payload)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "ok",
+		"data":    resp,
+	})
+}
+
+func (h *DisputeHandler) GetDispute(ctx *fiber.Ctx) error {
+	data := ctx.Locals("token").(model.TokenResponse)
+	payload := new(model.GetDispute)
+
+	id, err := ctx.ParamsInt("id")
+	if err != nil {
+		return ErrInvalidParam.Messagef("dispute id must be a number")
+	}
+
+	payload.UserID = data.UserID
+	payload.DisputeID = int32(id)
+
+	resp, err := h.service.Dispute.GetDispute(ctx.UserContext(), payload)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "ok",
+		"data":    resp,
+	})
+}
+
+func (h *DisputeHandler) GetDisputes(ctx *fiber.Ctx) error {
+	data := ctx.Locals("token").(model.TokenResponse)
+	payload := new(model.GetDisputes)
+	limit := ctx.QueryInt("limit", 5)
+	offset := ctx.QueryInt("offset", 1)
+
+	payload.UserID = data.UserID
+	payload.Limit = int32(limit)
+	payload.Offset = int32(offset)
+
+	resp, err := h.service.Dispute.GetDisputes(ctx.UserContext(), payload)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "ok",
+		"data":    resp,
+	})
+}
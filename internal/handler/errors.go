@@ -0,0 +1,93 @@
+package handler
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/apperror"
+	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/model"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+)
+
+const problemContentType = "application/problem+json"
+
+// problemTypeBase prefixes the code of a problem to make its type URI.
+const problemTypeBase = "/problems/"
+
+var errorStatus = map[apperror.Kind]int{
+	apperror.KindInvalidInput:        fiber.StatusBadRequest,
+	apperror.KindNotFound:            fiber.StatusNotFound,
+	apperror.KindInvalidTransition:   fiber.StatusUnprocessableEntity,
+	apperror.KindLimitExceeded:       fiber.StatusRequestEntityTooLarge,
+	apperror.KindUpstreamUnavailable: fiber.StatusServiceUnavailable,
+	apperror.KindConflict:            fiber.StatusConflict,
+}
+
+var (
+	ErrInvalidBody      = apperror.InvalidInput("invalid_body", "request body invalid")
+	ErrMissingReference = apperror.InvalidInput("missing_reference", "params not be empty")
+	ErrInvalidParam     = apperror.InvalidInput("invalid_param", "path parameter invalid")
+	ErrInvalidQuery     = apperror.InvalidInput("invalid_query", "query parameter invalid")
+)
+
+// validationError keeps the validator errors in the chain so ErrorHandler can
+// list every failed field in the client's language.
+func validationError(err error) error {
+	return apperror.ErrValidation.Wrap(err)
+}
+
+// statusCode turns a status text into a machine-readable code, e.g.
+// "method_not_allowed".
+func statusCode(status int) string {
+	return strings.ReplaceAll(strings.ToLower(utils.StatusMessage(status)), " ", "_")
+}
+
+// locale picks the language of the validation messages from Accept-Language.
+func locale(ctx *fiber.Ctx) string {
+	if l := ctx.AcceptsLanguages(model.Locales...); l != "" {
+		return l
+	}
+	return model.Locales[0]
+}
+
+// ErrorHandler writes every error returned by a handler as problem+json.
+// Domain errors are mapped to their status and code, anything else is logged
+// and answered with a generic 500 so database and upstream errors never reach
+// the client.
+func ErrorHandler(ctx *fiber.Ctx, err error) error {
+	problem := model.Problem{
+		Status:   fiber.StatusInternalServerError,
+		Code:     "internal_error",
+		Detail:   "internal server error",
+		Instance: ctx.OriginalURL(),
+	}
+
+	var fiberErr *fiber.Error
+	if appErr, ok := apperror.As(err); ok {
+		if status, ok := errorStatus[appErr.Kind]; ok {
+			problem.Status = status
+			problem.Code = appErr.Code
+			problem.Detail = appErr.Message
+		}
+	} else if errors.As(err, &fiberErr) {
+		problem.Status = fiberErr.Code
+		problem.Code = statusCode(fiberErr.Code)
+		problem.Detail = fiberErr.Message
+	}
+
+	problem.Type = problemTypeBase + problem.Code
+	problem.Title = utils.StatusMessage(problem.Status)
+
+	lang := locale(ctx)
+	problem.Errors = model.FieldErrors(err, lang)
+
+	if problem.Status >= fiber.StatusInternalServerError {
+		log.WithError(err).Errorf("internal server error, method: %v, path: %v", ctx.Method(), ctx.Path())
+	} else {
+		log.WithError(err).Errorf("bad request error, method: %v, path: %v", ctx.Method(), ctx.Path())
+	}
+
+	ctx.Set(fiber.HeaderContentLanguage, lang)
+	return ctx.Status(problem.Status).JSON(problem, problemContentType)
+}
@@ -3,9 +3,7 @@ package handler
 import (
 	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/external"
 	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/service"
-	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/storage/sqlc"
 	"github.com/gofiber/fiber/v2"
-	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 type Handlers struct {
@@ -14,6 +12,7 @@ type Handlers struct {
 	}
 	Middleware interface {
 		AuthMiddleware() fiber.Handler
+		Authorize() fiber.Handler
 	}
 	Transaction interface {
 		Create(*fiber.Ctx) error
@@ -21,12 +20,50 @@ type Handlers struct {
 		GetTransaction(*fiber.Ctx) error
 		GetTransactions(*fiber.Ctx) error
 		Refund(*fiber.Ctx) error
+		Cancel(*fiber.Ctx) error
+		CreateBatch(*fiber.Ctx) error
+		GetBatch(*fiber.Ctx) error
+	}
+	Reward interface {
+		GetRewards(*fiber.Ctx) error
+	}
+	Dispute interface {
+		Open(*fiber.Ctx) error
+		AddEvidence(*fiber.Ctx) error
+		GetDispute(*fiber.Ctx) error
+		GetDisputes(*fiber.Ctx) error
+	}
+	Webhook interface {
+		Register(*fiber.Ctx) error
+		GetEndpoints(*fiber.Ctx) error
+		DeleteEndpoint(*fiber.Ctx) error
+		EnableEndpoint(*fiber.Ctx) error
+		GetDeliveries(*fiber.Ctx) error
+		GetDelivery(*fiber.Ctx) error
+		Redeliver(*fiber.Ctx) error
+	}
+	Notification interface {
+		GetPreferences(*fiber.Ctx) error
+		UpdatePreferences(*fiber.Ctx) error
+	}
+	Admin interface {
+		SearchTransactions(*fiber.Ctx) error
+		GetTransaction(*fiber.Ctx) error
+		ForceStatus(*fiber.Ctx) error
+		Refund(*fiber.Ctx) error
+		ResolveDispute(*fiber.Ctx) error
+		GetReconciliationReport(*fiber.Ctx) error
+		GetReconciliationReports(*fiber.Ctx) error
+		GetAuditLogs(*fiber.Ctx) error
+	}
+	Stream interface {
+		Events(*fiber.Ctx) error
+		Upgrade(*fiber.Ctx) error
+		WebSocket() fiber.Handler
 	}
 }
 
-func NewHandler(q *sqlc.Queries, db *pgxpool.Pool) Handlers {
-	service := service.NewService(q, db)
-	external := external.NewExternal()
+func NewHandler(service service.Service, external external.External) Handlers {
 	return Handlers{
 		Health: &HealthHandler{},
 		Middleware: &MiddlewareHandler{
@@ -35,5 +72,23 @@ func NewHandler(q *sqlc.Queries, db *pgxpool.Pool) Handlers {
 		Transaction: &TransactionHandler{
 			service: service,
 		},
+		Reward: &RewardHandler{
+			service: service,
+		},
+		Dispute: &DisputeHandler{
+			service: service,
+		},
+		Webhook: &WebhookHandler{
+			service: service,
+		},
+		Notification: &NotificationHandler{
+			service: service,
+		},
+		Admin: &AdminHandler{
+			service: service,
+		},
+		Stream: &StreamHandler{
+			service: service,
+		},
 	}
 }
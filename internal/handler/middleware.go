@@ -3,7 +3,10 @@ package handler
 import (
 	"strings"
 
+	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/apperror"
 	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/external"
+	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/model"
+	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/policy"
 	"github.com/gofiber/fiber/v2"
 )
 
@@ -15,33 +18,46 @@ func (h *MiddlewareHandler) AuthMiddleware() fiber.Handler {
 	return func(ctx *fiber.Ctx) error {
 		authToken := ctx.Get("Authorization")
 		if authToken == "" {
-			return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"error": "missing authorization headers",
-			})
+			return fiber.NewError(fiber.StatusUnauthorized, "missing authorization headers")
 		}
 
-		rContext := ctx.Context()
+		rContext := ctx.UserContext()
 
 		parts := strings.Split(authToken, " ")
 		if len(parts) != 2 || parts[0] != "Bearer" {
-			return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"error": "authorization headers are malformed",
-			})
+			return fiber.NewError(fiber.StatusUnauthorized, "authorization headers are malformed")
 		}
 
 		token := parts[1]
 
 		userID, err := h.external.Validation.ValidateToken(rContext, token)
 		if err != nil {
-			return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"error": err.Error(),
-			})
+			// an unreachable user service is answered as such, any other
+			// failure only tells the client the token was refused
+			if _, ok := apperror.As(err); ok {
+				return err
+			}
+			log.WithError(err).Warn("token validation failed")
+			return fiber.NewError(fiber.StatusUnauthorized, "invalid or expired token")
 		}
 
 		log.Println(userID)
 
 		ctx.Locals("token", userID)
-		ctx.Locals("valid", token)
+		return ctx.Next()
+	}
+}
+
+// Authorize checks the identity set by AuthMiddleware against the scopes the
+// policy requires for the matched route. It must be registered on the route
+// itself, not on a group, so the route is known when it runs.
+func (h *MiddlewareHandler) Authorize() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		data := ctx.Locals("token").(model.TokenResponse)
+		route := ctx.Route().Method + " " + ctx.Route().Path
+		if !policy.Allowed(data, route) {
+			return fiber.NewError(fiber.StatusForbidden, "insufficient scope")
+		}
 		return ctx.Next()
 	}
 }
@@ -0,0 +1,52 @@
+package handler
+
+import (
+	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/model"
+	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/service"
+	"github.com/gofiber/fiber/v2"
+)
+
+type NotificationHandler struct {
+	service service.Service
+}
+
+func (h *NotificationHandler) GetPreferences(ctx *fiber.Ctx) error {
+	data := ctx.Locals("token").(model.TokenResponse)
+	payload := new(model.GetNotificationPreference)
+	payload.UserID = data.UserID
+
+	resp, err := h.service.Notification.GetPreferences(ctx.UserContext(), payload)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "ok",
+		"data":    resp,
+	})
+}
+
+func (h *NotificationHandler) UpdatePreferences(ctx *fiber.Ctx) error {
+	data := ctx.Locals("token").(model.TokenResponse)
+	payload := new(model.NotificationPreferencePayload)
+
+	if err := ctx.BodyParser(payload); err != nil {
+		return ErrInvalidBody.Wrap(err)
+	}
+
+	payload.UserID = data.UserID
+
+	if err := payload.Validate(); err != nil {
+		return validationError(err)
+	}
+
+	resp, err := h.service.Notification.UpdatePreferences(ctx.UserContext(), payload)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "ok",
+		"data":    resp,
+	})
+}
@@ -0,0 +1,104 @@
+package protohandler
+
+import (
+	"context"
+	"strings"
+
+	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/apperror"
+	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/external"
+	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/model"
+	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/policy"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+type contextKey string
+
+const tokenKey contextKey = "token"
+
+// tokenFromMetadata reads the bearer token the same way AuthMiddleware reads
+// the Authorization header.
+func tokenFromMetadata(ctx context.Context) (string, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return "", status.Error(codes.Unauthenticated, "missing authorization metadata")
+	}
+
+	values := md.Get("authorization")
+	if len(values) == 0 || values[0] == "" {
+		return "", status.Error(codes.Unauthenticated, "missing authorization metadata")
+	}
+
+	parts := strings.Split(values[0], " ")
+	if len(parts) != 2 || parts[0] != "Bearer" {
+		return "", status.Error(codes.Unauthenticated, "authorization metadata is malformed")
+	}
+
+	return parts[1], nil
+}
+
+// authenticate validates the caller's token and checks it against the scopes
+// the policy requires for method.
+func authenticate(ctx context.Context, validation external.External, method string) (context.Context, error) {
+	token, err := tokenFromMetadata(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := validation.Validation.ValidateToken(ctx, token)
+	if err != nil {
+		if _, ok := apperror.As(err); ok {
+			return nil, statusError(ctx, err, method)
+		}
+		log.WithError(err).Warn("token validation failed")
+		return nil, status.Error(codes.Unauthenticated, "invalid or expired token")
+	}
+
+	if !policy.Allowed(data, method) {
+		return nil, status.Error(codes.PermissionDenied, "insufficient scope")
+	}
+
+	ctx = context.WithValue(ctx, tokenKey, data)
+	return ctx, nil
+}
+
+// AuthInterceptor validates the bearer token of every unary call with the
+// user service and authorizes it, like AuthMiddleware and Authorize do for
+// HTTP.
+func AuthInterceptor(external external.External) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := authenticate(ctx, external, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// authStream carries the authenticated context into a streaming handler.
+type authStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authStream) Context() context.Context {
+	return s.ctx
+}
+
+// AuthStreamInterceptor is AuthInterceptor for streaming calls.
+func AuthStreamInterceptor(external external.External) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := authenticate(ss.Context(), external, info.FullMethod)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &authStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+func tokenData(ctx context.Context) model.TokenResponse {
+	data, _ := ctx.Value(tokenKey).(model.TokenResponse)
+	return data
+}
@@ -0,0 +1,84 @@
+package protohandler
+
+import (
+	"context"
+	"strings"
+
+	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/apperror"
+	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/model"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+)
+
+// errorDomain is the ErrorInfo domain of the errors returned by this service.
+const errorDomain = "transaction"
+
+var errorCode = map[apperror.Kind]codes.Code{
+	apperror.KindInvalidInput:        codes.InvalidArgument,
+	apperror.KindNotFound:            codes.NotFound,
+	apperror.KindInvalidTransition:   codes.FailedPrecondition,
+	apperror.KindLimitExceeded:       codes.ResourceExhausted,
+	apperror.KindUpstreamUnavailable: codes.Unavailable,
+	apperror.KindConflict:            codes.Aborted,
+}
+
+// locale reads the accept-language metadata the way Fiber reads the
+// Accept-Language header, ignoring quality values.
+func locale(ctx context.Context) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, value := range md.Get("accept-language") {
+		for _, tag := range strings.Split(value, ",") {
+			tag, _, _ = strings.Cut(strings.TrimSpace(tag), ";")
+			for _, l := range model.Locales {
+				if strings.EqualFold(tag, l) || strings.HasPrefix(strings.ToLower(tag), l+"-") {
+					return l
+				}
+			}
+		}
+	}
+	return model.Locales[0]
+}
+
+// statusError is the gRPC counterpart of handler.ErrorHandler. Domain errors
+// keep their message and carry the code in an ErrorInfo detail and the
+// translated field errors in a BadRequest detail, like the problem+json
+// response. Anything else becomes a generic Internal.
+func statusError(ctx context.Context, err error, method string) error {
+	var code codes.Code
+	appErr, ok := apperror.As(err)
+	if ok {
+		code, ok = errorCode[appErr.Kind]
+	}
+	if !ok {
+		log.WithError(err).Errorf("internal server error, method: %s", method)
+		return status.Error(codes.Internal, "internal server error")
+	}
+	log.WithError(err).Errorf("bad request error, method: %s", method)
+
+	details := []protoadapt.MessageV1{
+		&errdetails.ErrorInfo{
+			Reason: appErr.Code,
+			Domain: errorDomain,
+		},
+	}
+
+	if fields := model.FieldErrors(err, locale(ctx)); len(fields) > 0 {
+		badRequest := &errdetails.BadRequest{}
+		for _, f := range fields {
+			badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+				Field:       f.Field,
+				Description: f.Message,
+			})
+		}
+		details = append(details, badRequest)
+	}
+
+	st, detailErr := status.New(code, appErr.Message).WithDetails(details...)
+	if detailErr != nil {
+		return status.Error(code, appErr.Message)
+	}
+	return st.Err()
+}
@@ -0,0 +1,118 @@
+package protohandler
+
+import (
+	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/model"
+	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/policy"
+	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/proto/transaction"
+	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/storage/sqlc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func listedTransaction(tsx sqlc.Transaction) *transaction.Transaction {
+	amount, _ := tsx.Amount.Float64Value()
+	return &transaction.Transaction{
+		Reference:         tsx.Reference,
+		Amount:            amount.Float64,
+		TransactionType:   string(tsx.TransactionType),
+		TransactionStatus: string(tsx.TransactionStatus),
+		Description:       tsx.Description.String,
+		AdditionalInfo:    tsx.AdditionalInfo.String,
+		CreatedAt:         toTimestamp(tsx.CreatedAt.Time),
+		UpdatedAt:         toTimestamp(tsx.UpdatedAt.Time),
+	}
+}
+
+func (s *TransactionServer) ListTransactions(req *transaction.ListTransactionsRequest, stream grpc.ServerStreamingServer[transaction.Transaction]) error {
+	ctx := stream.Context()
+	data := tokenData(ctx)
+	payload := &model.ListTransactions{
+		UserID:            data.UserID,
+		TransactionType:   req.TransactionType,
+		TransactionStatus: req.TransactionStatus,
+	}
+	if req.UserId != 0 && req.UserId != data.UserID {
+		if !policy.HasScope(data, policy.ScopeAdmin) {
+			return status.Error(codes.PermissionDenied, "listing another user's transactions needs the admin scope")
+		}
+		payload.UserID = req.UserId
+	}
+	if req.From != nil {
+		payload.From = req.From.AsTime()
+	}
+	if req.To != nil {
+		payload.To = req.To.AsTime()
+	}
+
+	if !payload.From.IsZero() && !payload.To.IsZero() && !payload.From.Before(payload.To) {
+		return status.Error(codes.InvalidArgument, "from must be before to")
+	}
+
+	// Send blocks while the client's flow control window is full, which in
+	// turn stops the next page from being read ahead
+	err := s.service.Stream.ListTransactions(ctx, payload, func(tsx sqlc.Transaction) error {
+		return stream.Send(listedTransaction(tsx))
+	})
+	if err != nil {
+		if ctx.Err() != nil {
+			return status.FromContextError(ctx.Err()).Err()
+		}
+		return statusError(ctx, err, "ListTransactions")
+	}
+
+	return nil
+}
+
+func (s *TransactionServer) WatchTransactions(req *transaction.WatchTransactionsRequest, stream grpc.ServerStreamingServer[transaction.TransactionStatusEvent]) error {
+	ctx := stream.Context()
+	data := tokenData(ctx)
+
+	if req.AfterId < 0 {
+		return status.Error(codes.InvalidArgument, "after id must be a positive number")
+	}
+	position := &model.GetStatusEvents{
+		UserID:  data.UserID,
+		AfterID: req.AfterId,
+	}
+
+	notify, unsubscribe := s.service.Stream.Subscribe(data.UserID)
+	defer unsubscribe()
+
+	for {
+		events, err := s.service.Stream.GetStatusEvents(ctx, position)
+		if err != nil {
+			if ctx.Err() != nil {
+				return status.FromContextError(ctx.Err()).Err()
+			}
+			return statusError(ctx, err, "WatchTransactions")
+		}
+
+		// a slow client holds the loop in Send, it resumes from the outbox
+		// instead of buffering events in memory
+		for _, e := range events {
+			if err := stream.Send(&transaction.TransactionStatusEvent{
+				Id:              e.ID,
+				Reference:       e.Reference,
+				TransactionType: e.TransactionType,
+				PreviousStatus:  e.PreviousStatus,
+				Status:          e.Status,
+				Amount:          e.Amount,
+				OccurredAt:      toTimestamp(e.OccurredAt),
+			}); err != nil {
+				return err
+			}
+		}
+
+		// keep reading while there is a backlog
+		if len(events) > 0 {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return status.FromContextError(ctx.Err()).Err()
+		case <-notify:
+		}
+	}
+}
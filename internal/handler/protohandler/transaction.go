@@ -0,0 +1,231 @@
+package protohandler
+
+import (
+	"context"
+	"time"
+
+	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/apperror"
+	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/config/logger"
+	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/model"
+	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/proto/transaction"
+	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/service"
+	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/storage/sqlc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+var log = logger.NewLogger()
+
+type TransactionServer struct {
+	transaction.UnimplementedTransactionServiceServer
+	service service.Service
+}
+
+func NewTransactionServer(service service.Service) *TransactionServer {
+	return &TransactionServer{
+		service: service,
+	}
+}
+
+func toTimestamp(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
+
+func transactionResult(resp model.TransactionResponse) *transaction.TransactionResult {
+	return &transaction.TransactionResult{
+		WalletId:          resp.WalletID,
+		Reference:         resp.Reference,
+		ReversalReference: resp.ReversalReference,
+		Amount:            resp.Amount,
+		CreatedAt:         toTimestamp(resp.CreatedAt),
+		Status:            resp.Status,
+	}
+}
+
+func (s *TransactionServer) CreateTransaction(ctx context.Context, req *transaction.CreateTransactionRequest) (*transaction.CreateTransactionResponse, error) {
+	data := tokenData(ctx)
+	payload := &model.TransactionPayload{
+		UserID:          data.UserID,
+		Email:           data.Email,
+		Amount:          req.Amount,
+		TransactionType: req.TransactionType,
+		Description:     req.Description,
+		AdditionalInfo:  req.AdditionalInfo,
+	}
+
+	if err := payload.Validate(); err != nil {
+		return nil, statusError(ctx, apperror.ErrValidation.Wrap(err), "CreateTransaction")
+	}
+
+	resp, err := s.service.Transaction.Create(ctx, payload)
+	if err != nil {
+		return nil, statusError(ctx, err, "CreateTransaction")
+	}
+
+	return &transaction.CreateTransactionResponse{
+		Message: "ok",
+		Data: &transaction.CreatedTransaction{
+			Reference:         resp.Reference,
+			TransactionStatus: string(resp.TransactionStatus),
+		},
+	}, nil
+}
+
+func (s *TransactionServer) UpdateTransactionStatus(ctx context.Context, req *transaction.UpdateTransactionStatusRequest) (*transaction.TransactionResponse, error) {
+	payload := &model.TransactionUpdatePayload{
+		Reference:         req.Reference,
+		TransactionStatus: req.TransactionStatus,
+		AdditionalInfo:    req.AdditionalInfo,
+	}
+
+	if payload.Reference == "" {
+		return nil, status.Error(codes.InvalidArgument, "reference not be empty")
+	}
+
+	if err := payload.Validate(); err != nil {
+		return nil, statusError(ctx, apperror.ErrValidation.Wrap(err), "UpdateTransactionStatus")
+	}
+
+	resp, err := s.service.Transaction.UpdateTransaction(ctx, payload)
+	if err != nil {
+		return nil, statusError(ctx, err, "UpdateTransactionStatus")
+	}
+
+	return &transaction.TransactionResponse{
+		Message: "ok",
+		Data:    transactionResult(resp),
+	}, nil
+}
+
+func (s *TransactionServer) GetTransaction(ctx context.Context, req *transaction.GetTransactionRequest) (*transaction.GetTransactionResponse, error) {
+	data := tokenData(ctx)
+	payload := &model.GetTransaction{
+		UserID:    data.UserID,
+		Reference: req.Reference,
+	}
+
+	resp, err := s.service.Transaction.GetTransasction(ctx, payload)
+	if err != nil {
+		return nil, statusError(ctx, err, "GetTransaction")
+	}
+
+	amount, _ := resp.Amount.Float64Value()
+	return &transaction.GetTransactionResponse{
+		Message: "ok",
+		Data: &transaction.Transaction{
+			Reference:         resp.Reference,
+			Amount:            amount.Float64,
+			TransactionType:   string(resp.TransactionType),
+			TransactionStatus: string(resp.TransactionStatus),
+			Description:       resp.Description.String,
+			AdditionalInfo:    resp.AdditionalInfo.String,
+			CreatedAt:         toTimestamp(resp.CreatedAt.Time),
+			UpdatedAt:         toTimestamp(resp.UpdatedAt.Time),
+		},
+	}, nil
+}
+
+func (s *TransactionServer) GetTransactions(ctx context.Context, req *transaction.GetTransactionsRequest) (*transaction.GetTransactionsResponse, error) {
+	data := tokenData(ctx)
+	payload := &model.GetTransactions{
+		UserID: data.UserID,
+		Limit:  req.Limit,
+		Offset: req.Offset,
+	}
+
+	// same defaults as the limit and offset query params
+	if payload.Limit <= 0 {
+		payload.Limit = 5
+	}
+	if payload.Offset <= 0 {
+		payload.Offset = 1
+	}
+
+	resp, err := s.service.Transaction.GetTransactions(ctx, payload)
+	if err != nil {
+		return nil, statusError(ctx, err, "GetTransactions")
+	}
+
+	transactions := make([]*transaction.Transaction, 0, len(resp))
+	for _, tsx := range resp {
+		transactions = append(transactions, getTransactionsRow(tsx))
+	}
+
+	return &transaction.GetTransactionsResponse{
+		Message: "ok",
+		Data:    transactions,
+	}, nil
+}
+
+func getTransactionsRow(tsx sqlc.GetTransactionsRow) *transaction.Transaction {
+	amount, _ := tsx.Amount.Float64Value()
+	return &transaction.Transaction{
+		Reference:         tsx.Reference,
+		Amount:            amount.Float64,
+		TransactionType:   string(tsx.TransactionType),
+		TransactionStatus: string(tsx.TransactionStatus),
+		CreatedAt:         toTimestamp(tsx.CreatedAt.Time),
+	}
+}
+
+func (s *TransactionServer) RefundTransaction(ctx context.Context, req *transaction.RefundTransactionRequest) (*transaction.RefundTransactionResponse, error) {
+	data := tokenData(ctx)
+	payload := &model.TransactionRefundPayload{
+		Reference:      req.Reference,
+		Description:    req.Description,
+		AdditionalInfo: req.AdditionalInfo,
+		Email:          data.Email,
+		UserID:         data.UserID,
+	}
+
+	if err := payload.Validate(); err != nil {
+		return nil, statusError(ctx, apperror.ErrValidation.Wrap(err), "RefundTransaction")
+	}
+
+	resp, err := s.service.Transaction.CreateRefund(ctx, payload)
+	if err != nil {
+		return nil, statusError(ctx, err, "RefundTransaction")
+	}
+
+	return &transaction.RefundTransactionResponse{
+		Message: "ok",
+		Data: &transaction.Refund{
+			Reference:         resp.Reference,
+			TransactionStatus: resp.TransactionStatus,
+			Amount:            resp.Amount,
+			CreatedAt:         toTimestamp(resp.CreatedAt),
+		},
+	}, nil
+}
+
+func (s *TransactionServer) CancelTransaction(ctx context.Context, req *transaction.CancelTransactionRequest) (*transaction.TransactionResponse, error) {
+	data := tokenData(ctx)
+	payload := &model.TransactionCancelPayload{
+		Reference: req.Reference,
+		Reason:    req.Reason,
+		UserID:    data.UserID,
+		Email:     data.Email,
+	}
+
+	if payload.Reference == "" {
+		return nil, status.Error(codes.InvalidArgument, "reference not be empty")
+	}
+
+	if err := payload.Validate(); err != nil {
+		return nil, statusError(ctx, apperror.ErrValidation.Wrap(err), "CancelTransaction")
+	}
+
+	resp, err := s.service.Transaction.CancelTransaction(ctx, payload)
+	if err != nil {
+		return nil, statusError(ctx, err, "CancelTransaction")
+	}
+
+	return &transaction.TransactionResponse{
+		Message: "ok",
+		Data:    transactionResult(resp),
+	}, nil
+}
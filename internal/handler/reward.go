@@ -0,0 +1,32 @@
+package handler
+
+import (
+	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/model"
+	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/service"
+	"github.com/gofiber/fiber/v2"
+)
+
+type RewardHandler struct {
+	service service.Service
+}
+
+func (h *RewardHandler) GetRewards(ctx *fiber.Ctx) error {
+	data := ctx.Locals("token").(model.TokenResponse)
+	payload := new(model.GetRewards)
+	limit := ctx.QueryInt("limit", 5)
+	offset := ctx.QueryInt("offset", 1)
+
+	payload.UserID = data.UserID
+	payload.Limit = int32(limit)
+	payload.Offset = int32(offset)
+
+	resp, err := h.service.Reward.GetRewards(ctx.UserContext(), payload)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "ok",
+		"data":    resp,
+	})
+}
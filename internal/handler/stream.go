@@ -0,0 +1,165 @@
+package handler
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/model"
+	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/service"
+	"github.com/gofiber/contrib/websocket"
+	"github.com/gofiber/fiber/v2"
+)
+
+// streamHeartbeat keeps idle streams open through proxies and detects clients
+// that went away.
+const streamHeartbeat = 15 * time.Second
+
+type StreamHandler struct {
+	service service.Service
+}
+
+// lastEventID reads the resume point from the Last-Event-ID header sent by
+// EventSource on reconnect, or from the last_event_id query parameter.
+func lastEventID(ctx *fiber.Ctx) (int64, error) {
+	raw := ctx.Get("Last-Event-ID")
+	if raw == "" {
+		raw = ctx.Query("last_event_id")
+	}
+	if raw == "" {
+		return 0, nil
+	}
+
+	id, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil || id < 0 {
+		return 0, fmt.Errorf("last event id must be a positive number")
+	}
+	return id, nil
+}
+
+// pump sends the user's status changes after lastID, then waits for the hub
+// to signal new ones. Events committed late are sent when they show up, so
+// ids are not always increasing. It returns when send or ping fail or done is closed.
+func (h *StreamHandler) pump(userID int32, lastID int64, send func(model.TransactionStatusEvent) error, ping func() error, done <-chan struct{}) {
+	notify, unsubscribe := h.service.Stream.Subscribe(userID)
+	defer unsubscribe()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+
+	position := &model.GetStatusEvents{
+		UserID:  userID,
+		AfterID: lastID,
+	}
+	for {
+		events, err := h.service.Stream.GetStatusEvents(ctx, position)
+		if err != nil {
+			log.WithError(err).Errorf("failed to read stream events, user: %v", userID)
+			return
+		}
+
+		for _, e := range events {
+			if err := send(e); err != nil {
+				return
+			}
+		}
+
+		// keep reading while there is a backlog
+		if len(events) > 0 {
+			continue
+		}
+
+		select {
+		case <-done:
+			return
+		case <-notify:
+		case <-heartbeat.C:
+			if err := ping(); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func (h *StreamHandler) Events(ctx *fiber.Ctx) error {
+	data := ctx.Locals("token").(model.TokenResponse)
+
+	lastID, err := lastEventID(ctx)
+	if err != nil {
+		return ErrInvalidQuery.Messagef("%v", err)
+	}
+
+	ctx.Set("Content-Type", "text/event-stream")
+	ctx.Set("Cache-Control", "no-cache")
+	ctx.Set("Connection", "keep-alive")
+	ctx.Set("X-Accel-Buffering", "no")
+
+	ctx.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		send := func(e model.TransactionStatusEvent) error {
+			payload, err := json.Marshal(e)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(w, "id: %d\nevent: status_changed\ndata: %s\n\n", e.ID, payload)
+			return w.Flush()
+		}
+		ping := func() error {
+			fmt.Fprint(w, ": ping\n\n")
+			return w.Flush()
+		}
+
+		// a closed connection is noticed on the next write
+		h.pump(data.UserID, lastID, send, ping, nil)
+	})
+
+	return nil
+}
+
+// Upgrade rejects plain HTTP requests on the WebSocket route and keeps what
+// the socket needs from the request.
+func (h *StreamHandler) Upgrade(ctx *fiber.Ctx) error {
+	if !websocket.IsWebSocketUpgrade(ctx) {
+		return fiber.NewError(fiber.StatusUpgradeRequired, "websocket upgrade required")
+	}
+
+	lastID, err := lastEventID(ctx)
+	if err != nil {
+		return ErrInvalidQuery.Messagef("%v", err)
+	}
+
+	ctx.Locals("last_event_id", lastID)
+	return ctx.Next()
+}
+
+func (h *StreamHandler) WebSocket() fiber.Handler {
+	return websocket.New(func(conn *websocket.Conn) {
+		data := conn.Locals("token").(model.TokenResponse)
+		lastID := conn.Locals("last_event_id").(int64)
+
+		// the client never sends anything, reading only notices it closing
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			for {
+				if _, _, err := conn.ReadMessage(); err != nil {
+					return
+				}
+			}
+		}()
+
+		send := func(e model.TransactionStatusEvent) error {
+			return conn.WriteJSON(e)
+		}
+		ping := func() error {
+			return conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(streamHeartbeat))
+		}
+
+		h.pump(data.UserID, lastID, send, ping, done)
+	})
+}
@@ -1,10 +1,9 @@
 package handler
 
 import (
-	"fmt"
-
 	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/config/logger"
 	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/model"
+	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/policy"
 	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/service"
 	"github.com/gofiber/fiber/v2"
 )
@@ -19,29 +18,20 @@ func (h *TransactionHandler) Create(ctx *fiber.Ctx) error {
 	data := ctx.Locals("token").(model.TokenResponse)
 	payload := new(model.TransactionPayload)
 
-	payload.UserID = data.UserID
-
 	if err := ctx.BodyParser(payload); err != nil {
-		log.WithError(err).Errorf("bad request error, method: %v, path: %v", ctx.Method(), ctx.Path())
-		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return ErrInvalidBody.Wrap(err)
 	}
 
+	payload.UserID = data.UserID
+	payload.Email = data.Email
+
 	if err := payload.Validate(); err != nil {
-		errorValidate := fmt.Errorf("validate error")
-		log.WithError(errorValidate).Errorf("bad request error, method: %v, path: %v", ctx.Method(), ctx.Path())
-		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return validationError(err)
 	}
 
-	resp, err := h.service.Transaction.Create(ctx.Context(), payload)
+	resp, err := h.service.Transaction.Create(ctx.UserContext(), payload)
 	if err != nil {
-		log.WithError(err).Errorf("internal server error, method: %v, path: %v", ctx.Method(), ctx.Path())
-		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return err
 	}
 
 	return ctx.Status(fiber.StatusCreated).JSON(fiber.Map{
@@ -51,47 +41,29 @@ func (h *TransactionHandler) Create(ctx *fiber.Ctx) error {
 }
 
 func (h *TransactionHandler) Update(ctx *fiber.Ctx) error {
-	data := ctx.Locals("token").(model.TokenResponse)
 	payload := new(model.TransactionUpdatePayload)
-	token := ctx.Locals("valid").(string)
 
 	reference := ctx.Params("reference")
 	payload.Reference = reference
-	payload.Token = token
-	payload.Email = data.Email
 
 	if reference == "" {
-		errorValidate := fmt.Errorf("params not be empty")
-		log.WithError(errorValidate).Errorf("bad request error, method: %v, path: %v", ctx.Method(), ctx.Path())
-		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": errorValidate.Error(),
-		})
+		return ErrMissingReference
 	}
 
 	if err := ctx.BodyParser(payload); err != nil {
-		log.WithError(err).Errorf("bad request error, method: %v, path: %v", ctx.Method(), ctx.Path())
-		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return ErrInvalidBody.Wrap(err)
 	}
 
 	if err := payload.Validate(); err != nil {
-		errorValidate := fmt.Errorf("validate error")
-		log.WithError(errorValidate).Errorf("bad request error, method: %v, path: %v", ctx.Method(), ctx.Path())
-		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return validationError(err)
 	}
 
-	resp, err := h.service.Transaction.UpdateTransaction(ctx.Context(), payload)
+	resp, err := h.service.Transaction.UpdateTransaction(ctx.UserContext(), payload)
 	if err != nil {
-		log.WithError(err).Errorf("internal server error, method: %v, path: %v", ctx.Method(), ctx.Path())
-		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return err
 	}
 
-	return ctx.Status(fiber.StatusCreated).JSON(fiber.Map{
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
 		"message": "ok",
 		"data":    resp,
 	})
@@ -105,15 +77,12 @@ func (h *TransactionHandler) GetTransaction(ctx *fiber.Ctx) error {
 	payload.UserID = data.UserID
 	payload.Reference = reference
 
-	resp, err := h.service.Transaction.GetTransasction(ctx.Context(), payload)
+	resp, err := h.service.Transaction.GetTransasction(ctx.UserContext(), payload)
 	if err != nil {
-		log.WithError(err).Errorf("internal server error, method: %v, path: %v", ctx.Method(), ctx.Path())
-		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return err
 	}
 
-	return ctx.Status(fiber.StatusCreated).JSON(fiber.Map{
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
 		"message": "ok",
 		"data":    resp,
 	})
@@ -129,46 +98,35 @@ func (h *TransactionHandler) GetTransactions(ctx *fiber.Ctx) error {
 	payload.Limit = int32(limit)
 	payload.Offset = int32(offset)
 
-	resp, err := h.service.Transaction.GetTransactions(ctx.Context(), payload)
+	resp, err := h.service.Transaction.GetTransactions(ctx.UserContext(), payload)
 	if err != nil {
-		log.WithError(err).Errorf("internal server error, method: %v, path: %v", ctx.Method(), ctx.Path())
-		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return err
 	}
 
-	return ctx.Status(fiber.StatusCreated).JSON(fiber.Map{
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
 		"message": "ok",
 		"data":    resp,
 	})
 }
 
 func (h *TransactionHandler) Refund(ctx *fiber.Ctx) error {
+	data := ctx.Locals("token").(model.TokenResponse)
 	payload := new(model.TransactionRefundPayload)
-	token := ctx.Locals("valid").(string)
 
-	payload.Token = token
 	if err := ctx.BodyParser(payload); err != nil {
-		log.WithError(err).Errorf("bad request error, method: %v, path: %v", ctx.Method(), ctx.Path())
-		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return ErrInvalidBody.Wrap(err)
 	}
 
+	payload.UserID = data.UserID
+	payload.Email = data.Email
+
 	if err := payload.Validate(); err != nil {
-		errorValidate := fmt.Errorf("validate error")
-		log.WithError(errorValidate).Errorf("bad request error, method: %v, path: %v", ctx.Method(), ctx.Path())
-		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return validationError(err)
 	}
 
-	resp, err := h.service.Transaction.CreateRefund(ctx.Context(), payload)
+	resp, err := h.service.Transaction.CreateRefund(ctx.UserContext(), payload)
 	if err != nil {
-		log.WithError(err).Errorf("internal server error, method: %v, path: %v", ctx.Method(), ctx.Path())
-		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return err
 	}
 
 	return ctx.Status(fiber.StatusCreated).JSON(fiber.Map{
@@ -176,3 +134,82 @@ func (h *TransactionHandler) Refund(ctx *fiber.Ctx) error {
 		"data":    resp,
 	})
 }
+
+func (h *TransactionHandler) Cancel(ctx *fiber.Ctx) error {
+	data := ctx.Locals("token").(model.TokenResponse)
+	payload := new(model.TransactionCancelPayload)
+
+	reference := ctx.Params("reference")
+	if reference == "" {
+		return ErrMissingReference
+	}
+
+	// the body is optional, it only carries the cancellation reason
+	if len(ctx.Body()) > 0 {
+		if err := ctx.BodyParser(payload); err != nil {
+			return ErrInvalidBody.Wrap(err)
+		}
+	}
+
+	payload.Reference = reference
+	payload.UserID = data.UserID
+	payload.Email = data.Email
+
+	if err := payload.Validate(); err != nil {
+		return validationError(err)
+	}
+
+	resp, err := h.service.Transaction.CancelTransaction(ctx.UserContext(), payload)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "ok",
+		"data":    resp,
+	})
+}
+
+func (h *TransactionHandler) CreateBatch(ctx *fiber.Ctx) error {
+	data := ctx.Locals("token").(model.TokenResponse)
+	payload := new(model.TransactionBatchPayload)
+
+	if err := ctx.BodyParser(payload); err != nil {
+		return ErrInvalidBody.Wrap(err)
+	}
+
+	payload.UserID = data.UserID
+	payload.Payout = policy.HasScope(data, policy.ScopeTransactionPayout)
+
+	if err := payload.Validate(); err != nil {
+		return validationError(err)
+	}
+
+	resp, err := h.service.Transaction.CreateBatch(ctx.UserContext(), payload)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "ok",
+		"data":    resp,
+	})
+}
+
+func (h *TransactionHandler) GetBatch(ctx *fiber.Ctx) error {
+	data := ctx.Locals("token").(model.TokenResponse)
+	payload := new(model.GetTransactionBatch)
+
+	payload.UserID = data.UserID
+	payload.BatchID = ctx.Params("batch_id")
+
+	resp, err := h.service.Transaction.GetBatch(ctx.UserContext(), payload)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "ok",
+		"data":    resp,
+	})
+}
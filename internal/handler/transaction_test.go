@@ -0,0 +1,84 @@
+package handler
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/model"
+	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/service"
+	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/storage/sqlc"
+	"github.com/gofiber/fiber/v2"
+)
+
+// fakeTransactionService records the payload handed to Create.
+type fakeTransactionService struct {
+	created *model.TransactionPayload
+}
+
+func (f *fakeTransactionService) Create(_ context.Context, payload *model.TransactionPayload) (sqlc.CreateTransactionRow, error) {
+	f.created = payload
+	return sqlc.CreateTransactionRow{Reference: "TOPUP-REF", TransactionStatus: sqlc.TransactionStatusPENDING}, nil
+}
+
+func (f *fakeTransactionService) UpdateTransaction(context.Context, *model.TransactionUpdatePayload) (model.TransactionResponse, error) {
+	return model.TransactionResponse{}, nil
+}
+
+func (f *fakeTransactionService) GetTransasction(context.Context, *model.GetTransaction) (sqlc.Transaction, error) {
+	return sqlc.Transaction{}, nil
+}
+
+func (f *fakeTransactionService) GetTransactions(context.Context, *model.GetTransactions) ([]sqlc.GetTransactionsRow, error) {
+	return nil, nil
+}
+
+func (f *fakeTransactionService) CreateRefund(context.Context, *model.TransactionRefundPayload) (*model.RefundResponse, error) {
+	return nil, nil
+}
+
+func (f *fakeTransactionService) CancelTransaction(context.Context, *model.TransactionCancelPayload) (model.TransactionResponse, error) {
+	return model.TransactionResponse{}, nil
+}
+
+func (f *fakeTransactionService) CreateBatch(context.Context, *model.TransactionBatchPayload) (*model.TransactionBatchResponse, error) {
+	return nil, nil
+}
+
+func (f *fakeTransactionService) GetBatch(context.Context, *model.GetTransactionBatch) (*model.TransactionBatchResponse, error) {
+	return nil, nil
+}
+
+func TestCreateIgnoresBodyUserID(t *testing.T) {
+	fake := &fakeTransactionService{}
+	h := &TransactionHandler{service: service.Service{Transaction: fake}}
+
+	app := fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+	app.Post("/", func(ctx *fiber.Ctx) error {
+		ctx.Locals("token", model.TokenResponse{UserID: 7, Email: "owner@example.com"})
+		return ctx.Next()
+	}, h.Create)
+
+	body := `{"user_id": 99, "email": "other@example.com", "amount": 5000, "transaction_type": "TOPUP", "description": "topup wallet"}`
+	req := httptest.NewRequest(fiber.MethodPost, "/", strings.NewReader(body))
+	req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	if resp.StatusCode != fiber.StatusCreated {
+		t.Fatalf("status = %d, want %d", resp.StatusCode, fiber.StatusCreated)
+	}
+
+	if fake.created == nil {
+		t.Fatal("service was not called")
+	}
+	if fake.created.UserID != 7 {
+		t.Errorf("UserID = %d, want the token's 7", fake.created.UserID)
+	}
+	if fake.created.Email != "owner@example.com" {
+		t.Errorf("Email = %q, want the token's", fake.created.Email)
+	}
+}
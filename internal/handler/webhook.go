@@ -0,0 +1,182 @@
+package handler
+
+import (
+	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/model"
+	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/service"
+	"github.com/gofiber/fiber/v2"
+)
+
+type WebhookHandler struct {
+	service service.Service
+}
+
+func (h *WebhookHandler) Register(ctx *fiber.Ctx) error {
+	data := ctx.Locals("token").(model.TokenResponse)
+	payload := new(model.WebhookEndpointPayload)
+
+	if err := ctx.BodyParser(payload); err != nil {
+		return ErrInvalidBody.Wrap(err)
+	}
+
+	payload.UserID = data.UserID
+
+	if err := payload.Validate(); err != nil {
+		return validationError(err)
+	}
+
+	resp, err := h.service.Webhook.Register(ctx.UserContext(), payload)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "ok",
+		"data":    resp,
+	})
+}
+
+func (h *WebhookHandler) GetEndpoints(ctx *fiber.Ctx) error {
+	data := ctx.Locals("token").(model.TokenResponse)
+	payload := new(model.GetWebhookEndpoints)
+	payload.UserID = data.UserID
+
+	resp, err := h.service.Webhook.GetEndpoints(ctx.UserContext(), payload)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "ok",
+		"data":    resp,
+	})
+}
+
+func (h *WebhookHandler) DeleteEndpoint(ctx *fiber.Ctx) error {
+	data := ctx.Locals("token").(model.TokenResponse)
+	payload := new(model.GetWebhookEndpoint)
+
+	id, err := ctx.ParamsInt("id")
+	if err != nil {
+		return ErrInvalidParam.Messagef("webhook id must be a number")
+	}
+
+	payload.UserID = data.UserID
+	payload.EndpointID = int32(id)
+
+	if err := h.service.Webhook.DeleteEndpoint(ctx.UserContext(), payload); err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "ok",
+	})
+}
+
+func (h *WebhookHandler) EnableEndpoint(ctx *fiber.Ctx) error {
+	data := ctx.Locals("token").(model.TokenResponse)
+	payload := new(model.GetWebhookEndpoint)
+
+	id, err := ctx.ParamsInt("id")
+	if err != nil {
+		return ErrInvalidParam.Messagef("webhook id must be a number")
+	}
+
+	payload.UserID = data.UserID
+	payload.EndpointID = int32(id)
+
+	resp, err := h.service.Webhook.EnableEndpoint(ctx.UserContext(), payload)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "ok",
+		"data":    resp,
+	})
+}
+
+func (h *WebhookHandler) GetDeliveries(ctx *fiber.Ctx) error {
+	data := ctx.Locals("token").(model.TokenResponse)
+	payload := new(model.GetWebhookDeliveries)
+
+	id, err := ctx.ParamsInt("id")
+	if err != nil {
+		return ErrInvalidParam.Messagef("webhook id must be a number")
+	}
+
+	limit := ctx.QueryInt("limit", 5)
+	offset := ctx.QueryInt("offset", 1)
+
+	payload.UserID = data.UserID
+	payload.EndpointID = int32(id)
+	payload.Limit = int32(limit)
+	payload.Offset = int32(offset)
+
+	resp, err := h.service.Webhook.GetDeliveries(ctx.UserContext(), payload)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "ok",
+		"data":    resp,
+	})
+}
+
+func (h *WebhookHandler) GetDelivery(ctx *fiber.Ctx) error {
+	data := ctx.Locals("token").(model.TokenResponse)
+	payload := new(model.GetWebhookDelivery)
+
+	id, err := ctx.ParamsInt("id")
+	if err != nil {
+		return ErrInvalidParam.Messagef("webhook id must be a number")
+	}
+
+	deliveryID, err := ctx.ParamsInt("delivery_id")
+	if err != nil {
+		return ErrInvalidParam.Messagef("delivery id must be a number")
+	}
+
+	payload.UserID = data.UserID
+	payload.EndpointID = int32(id)
+	payload.DeliveryID = int32(deliveryID)
+
+	resp, err := h.service.Webhook.GetDelivery(ctx.UserContext(), payload)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "ok",
+		"data":    resp,
+	})
+}
+
+func (h *WebhookHandler) Redeliver(ctx *fiber.Ctx) error {
+	data := ctx.Locals("token").(model.TokenResponse)
+	payload := new(model.GetWebhookDelivery)
+
+	id, err := ctx.ParamsInt("id")
+	if err != nil {
+		return ErrInvalidParam.Messagef("webhook id must be a number")
+	}
+
+	deliveryID, err := ctx.ParamsInt("delivery_id")
+	if err != nil {
+		return ErrInvalidParam.Messagef("delivery id must be a number")
+	}
+
+	payload.UserID = data.UserID
+	payload.EndpointID = int32(id)
+	payload.DeliveryID = int32(deliveryID)
+
+	resp, err := h.service.Webhook.Redeliver(ctx.UserContext(), payload)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "ok",
+		"data":    resp,
+	})
+}
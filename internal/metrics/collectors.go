@@ -0,0 +1,109 @@
+package metrics
+
+import (
+	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/auth"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// poolCollector reads the stats of the database pool on every scrape.
+type poolCollector struct {
+	pool *pgxpool.Pool
+
+	acquiredConns     *prometheus.Desc
+	idleConns         *prometheus.Desc
+	constructingConns *prometheus.Desc
+	totalConns        *prometheus.Desc
+	maxConns          *prometheus.Desc
+	acquires          *prometheus.Desc
+	acquireDuration   *prometheus.Desc
+	emptyAcquires     *prometheus.Desc
+	canceledAcquires  *prometheus.Desc
+	newConns          *prometheus.Desc
+}
+
+// RegisterPool exports the stats of the pool created by db.New.
+func RegisterPool(pool *pgxpool.Pool) error {
+	return prometheus.Register(&poolCollector{
+		pool:              pool,
+		acquiredConns:     prometheus.NewDesc("db_pool_acquired_connections", "Connections in use.", nil, nil),
+		idleConns:         prometheus.NewDesc("db_pool_idle_connections", "Idle connections.", nil, nil),
+		constructingConns: prometheus.NewDesc("db_pool_constructing_connections", "Connections being opened.", nil, nil),
+		totalConns:        prometheus.NewDesc("db_pool_connections", "Open connections.", nil, nil),
+		maxConns:          prometheus.NewDesc("db_pool_max_connections", "Maximum size of the pool.", nil, nil),
+		acquires:          prometheus.NewDesc("db_pool_acquires_total", "Connections acquired from the pool.", nil, nil),
+		acquireDuration:   prometheus.NewDesc("db_pool_acquire_duration_seconds_total", "Time spent acquiring connections.", nil, nil),
+		emptyAcquires:     prometheus.NewDesc("db_pool_empty_acquires_total", "Acquires that waited for a connection because the pool was empty.", nil, nil),
+		canceledAcquires:  prometheus.NewDesc("db_pool_canceled_acquires_total", "Acquires canceled by their context.", nil, nil),
+		newConns:          prometheus.NewDesc("db_pool_new_connections_total", "Connections opened.", nil, nil),
+	})
+}
+
+func (c *poolCollector) Describe(ch chan<- *prometheus.Desc) {
+	prometheus.DescribeByCollect(c, ch)
+}
+
+func (c *poolCollector) Collect(ch chan<- prometheus.Metric) {
+	stat := c.pool.Stat()
+
+	ch <- prometheus.MustNewConstMetric(c.acquiredConns, prometheus.GaugeValue, float64(stat.AcquiredConns()))
+	ch <- prometheus.MustNewConstMetric(c.idleConns, prometheus.GaugeValue, float64(stat.IdleConns()))
+	ch <- prometheus.MustNewConstMetric(c.constructingConns, prometheus.GaugeValue, float64(stat.ConstructingConns()))
+	ch <- prometheus.MustNewConstMetric(c.totalConns, prometheus.GaugeValue, float64(stat.TotalConns()))
+	ch <- prometheus.MustNewConstMetric(c.maxConns, prometheus.GaugeValue, float64(stat.MaxConns()))
+	ch <- prometheus.MustNewConstMetric(c.acquires, prometheus.CounterValue, float64(stat.AcquireCount()))
+	ch <- prometheus.MustNewConstMetric(c.acquireDuration, prometheus.CounterValue, stat.AcquireDuration().Seconds())
+	ch <- prometheus.MustNewConstMetric(c.emptyAcquires, prometheus.CounterValue, float64(stat.EmptyAcquireCount()))
+	ch <- prometheus.MustNewConstMetric(c.canceledAcquires, prometheus.CounterValue, float64(stat.CanceledAcquireCount()))
+	ch <- prometheus.MustNewConstMetric(c.newConns, prometheus.CounterValue, float64(stat.NewConnsCount()))
+}
+
+// tokenCacheCollector reads the stats the token cache keeps since startup.
+type tokenCacheCollector struct {
+	cache *auth.TokenCache
+
+	entries     *prometheus.Desc
+	hits        *prometheus.Desc
+	misses      *prometheus.Desc
+	evictions   *prometheus.Desc
+	revocations *prometheus.Desc
+}
+
+func RegisterTokenCache(cache *auth.TokenCache) error {
+	return prometheus.Register(&tokenCacheCollector{
+		cache:       cache,
+		entries:     prometheus.NewDesc("token_cache_entries", "Validated tokens in the cache.", nil, nil),
+		hits:        prometheus.NewDesc("token_cache_hits_total", "Tokens found in the cache.", nil, nil),
+		misses:      prometheus.NewDesc("token_cache_misses_total", "Tokens validated because they were not in the cache.", nil, nil),
+		evictions:   prometheus.NewDesc("token_cache_evictions_total", "Tokens evicted to make room.", nil, nil),
+		revocations: prometheus.NewDesc("token_cache_revocations_total", "Revocations applied to the cache.", nil, nil),
+	})
+}
+
+func (c *tokenCacheCollector) Describe(ch chan<- *prometheus.Desc) {
+	prometheus.DescribeByCollect(c, ch)
+}
+
+func (c *tokenCacheCollector) Collect(ch chan<- prometheus.Metric) {
+	stats := c.cache.Stats()
+
+	ch <- prometheus.MustNewConstMetric(c.entries, prometheus.GaugeValue, float64(stats.Entries))
+	ch <- prometheus.MustNewConstMetric(c.hits, prometheus.CounterValue, float64(stats.Hits))
+	ch <- prometheus.MustNewConstMetric(c.misses, prometheus.CounterValue, float64(stats.Misses))
+	ch <- prometheus.MustNewConstMetric(c.evictions, prometheus.CounterValue, float64(stats.Evictions))
+	ch <- prometheus.MustNewConstMetric(c.revocations, prometheus.CounterValue, float64(stats.Revocations))
+}
+
+// RegisterWalletCircuit exports whether the wallet circuit breaker is open,
+// open reports it on every scrape.
+func RegisterWalletCircuit(open func() bool) error {
+	return prometheus.Register(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Name: "wallet_circuit_open",
+		Help: "1 while the wallet circuit breaker refuses calls.",
+	}, func() float64 {
+		if open() {
+			return 1
+		}
+		return 0
+	}))
+}
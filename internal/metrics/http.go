@@ -0,0 +1,56 @@
+package metrics
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// unmatchedRoute labels the requests no route matched, so probes for random
+// paths don't add a series each.
+const unmatchedRoute = "unmatched"
+
+var (
+	httpRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_requests_total",
+		Help: "HTTP requests, by method, route and status.",
+	}, []string{"method", "route", "status"})
+
+	httpRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_request_duration_seconds",
+		Help:    "Latency of the HTTP requests, by method, route and status.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+)
+
+// HTTP records every request under the route it matched, e.g.
+// /v1/dispute/:id, rather than its path. It has to be installed before the
+// routes. An error returned by the chain is handed to the app's error handler
+// here, so the status recorded is the one the client gets.
+func HTTP() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		start := time.Now()
+		own := ctx.Route()
+
+		if err := ctx.Next(); err != nil {
+			if err := ctx.App().Config().ErrorHandler(ctx, err); err != nil {
+				_ = ctx.SendStatus(fiber.StatusInternalServerError)
+			}
+		}
+
+		// without a matching route the context keeps the route of the last
+		// middleware, installed at the same path as this one
+		route := unmatchedRoute
+		if r := ctx.Route(); r.Path != own.Path {
+			route = r.Path
+		}
+
+		status := strconv.Itoa(ctx.Response().StatusCode())
+		httpRequests.WithLabelValues(ctx.Method(), route, status).Inc()
+		httpRequestDuration.WithLabelValues(ctx.Method(), route, status).Observe(time.Since(start).Seconds())
+		return nil
+	}
+}
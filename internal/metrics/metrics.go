@@ -0,0 +1,106 @@
+// Package metrics exposes the Prometheus metrics of the service. The metrics
+// are registered on the default registry when the package is loaded, callers
+// only record values.
+package metrics
+
+import (
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Clients of the other services.
+const (
+	ClientWallet       = "wallet"
+	ClientNotification = "notification"
+	ClientUserService  = "user_service"
+)
+
+// Results of a client call or a worker run.
+const (
+	ResultSuccess = "success"
+	ResultError   = "error"
+)
+
+var (
+	transactionsCreated = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "transactions_created_total",
+		Help: "Transactions created, by type and initial status.",
+	}, []string{"type", "status"})
+
+	transactionTransitions = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "transaction_transitions_total",
+		Help: "Transaction status changes, by type and new status.",
+	}, []string{"type", "status"})
+
+	transactionAmount = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "transaction_amount_total",
+		Help: "Sum of the amounts of the transactions entering a status, by type and status.",
+	}, []string{"type", "status"})
+
+	clientRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "client_request_duration_seconds",
+		Help:    "Latency of the calls to other services, retries included, by client, operation and result.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"client", "operation", "result"})
+
+	notificationsUnroutable = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "notifications_unroutable_total",
+		Help: "Notifications not queued because the owner only gets email and their email is unknown, by event.",
+	}, []string{"event"})
+
+	queueDepth = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "queue_depth",
+		Help: "Jobs waiting for a background worker, by queue.",
+	}, []string{"queue"})
+
+	workerRuns = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "worker_run_duration_seconds",
+		Help:    "Duration of the background worker runs, by worker and result.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"worker", "result"})
+)
+
+// Handler serves the metrics in the Prometheus text format.
+func Handler() fiber.Handler {
+	return adaptor.HTTPHandler(promhttp.Handler())
+}
+
+// Result labels a call by its error.
+func Result(err error) string {
+	if err != nil {
+		return ResultError
+	}
+	return ResultSuccess
+}
+
+func TransactionCreated(transactionType, status string, amount float64) {
+	transactionsCreated.WithLabelValues(transactionType, status).Inc()
+	transactionAmount.WithLabelValues(transactionType, status).Add(amount)
+}
+
+func TransactionTransitioned(transactionType, status string, amount float64) {
+	transactionTransitions.WithLabelValues(transactionType, status).Inc()
+	transactionAmount.WithLabelValues(transactionType, status).Add(amount)
+}
+
+func NotificationUnroutable(event string) {
+	notificationsUnroutable.WithLabelValues(event).Inc()
+}
+
+// ObserveClient records a call to another service that started at start.
+func ObserveClient(client, operation, result string, start time.Time) {
+	clientRequestDuration.WithLabelValues(client, operation, result).Observe(time.Since(start).Seconds())
+}
+
+func SetQueueDepth(queue string, depth int64) {
+	queueDepth.WithLabelValues(queue).Set(float64(depth))
+}
+
+func ObserveWorkerRun(worker string, start time.Time, err error) {
+	workerRuns.WithLabelValues(worker, Result(err)).Observe(time.Since(start).Seconds())
+}
@@ -0,0 +1,130 @@
+package model
+
+import "time"
+
+// Admin action names written to the audit log.
+const (
+	AdminActionSearchTransactions = "transaction.search"
+	AdminActionViewTransaction    = "transaction.view"
+	AdminActionForceStatus        = "transaction.force_status"
+	AdminActionRefund             = "transaction.refund"
+	AdminActionResolveDispute     = "dispute.resolve"
+	AdminActionViewReconciliation = "reconciliation.view"
+	AdminActionViewAuditLog       = "audit.view"
+)
+
+type AdminSearchTransactions struct {
+	Admin             TokenResponse `json:"-"`
+	UserID            int32         `json:"user_id,omitempty"`
+	Reference         string        `json:"reference,omitempty"`
+	TransactionType   string        `json:"transaction_type,omitempty"`
+	TransactionStatus string        `json:"transaction_status,omitempty"`
+	From              time.Time     `json:"from"`
+	To                time.Time     `json:"to"`
+	Limit             int32         `json:"limit"`
+	Offset            int32         `json:"offset"`
+}
+
+type AdminGetTransaction struct {
+	Admin     TokenResponse
+	Reference string
+}
+
+type AdminTransactionResponse struct {
+	ID                int32     `json:"id"`
+	UserID            int32     `json:"user_id"`
+	Reference         string    `json:"reference"`
+	Amount            float64   `json:"amount"`
+	TransactionType   string    `json:"transaction_type"`
+	TransactionStatus string    `json:"transaction_status"`
+	Description       string    `json:"description"`
+	AdditionalInfo    string    `json:"additional_info"`
+	StatusReason      string    `json:"status_reason,omitempty"`
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
+}
+
+// AdminTransactionEvent is one entry of a transaction's history, read from
+// the event outbox.
+type AdminTransactionEvent struct {
+	ID              int64      `json:"id"`
+	Type            string     `json:"type"`
+	PreviousStatus  string     `json:"previous_status,omitempty"`
+	Status          string     `json:"status,omitempty"`
+	RefundReference string     `json:"refund_reference,omitempty"`
+	OccurredAt      time.Time  `json:"occurred_at"`
+	PublishedAt     *time.Time `json:"published_at,omitempty"`
+}
+
+type AdminTransactionDetail struct {
+	Transaction AdminTransactionResponse `json:"transaction"`
+	History     []AdminTransactionEvent  `json:"history"`
+	AuditLog    []AdminAuditLogResponse  `json:"audit_log"`
+}
+
+type AdminStatusPayload struct {
+	Admin             TokenResponse `json:"-"`
+	Reference         string        `json:"reference"`
+	TransactionStatus string        `json:"transaction_status" validate:"required"`
+	Reason            string        `json:"reason" validate:"required,min=5,max=1000"`
+	AdditionalInfo    string        `json:"additional_info"`
+}
+
+func (u *AdminStatusPayload) Validate() error {
+	return Validate.Struct(u)
+}
+
+type AdminRefundPayload struct {
+	Admin          TokenResponse `json:"-"`
+	Reference      string        `json:"reference"`
+	Reason         string        `json:"reason" validate:"required,min=5,max=1000"`
+	Description    string        `json:"description"`
+	AdditionalInfo string        `json:"additional_info"`
+}
+
+func (u *AdminRefundPayload) Validate() error {
+	return Validate.Struct(u)
+}
+
+type AdminResolveDisputePayload struct {
+	Admin      TokenResponse `json:"-"`
+	DisputeID  int32         `json:"dispute_id"`
+	Outcome    string        `json:"outcome" validate:"required,oneof=WON LOST"`
+	Resolution string        `json:"resolution" validate:"required,min=5,max=1000"`
+}
+
+func (u *AdminResolveDisputePayload) Validate() error {
+	return Validate.Struct(u)
+}
+
+type AdminGetReconciliationReport struct {
+	Admin    TokenResponse
+	ReportID int32
+}
+
+type AdminGetReconciliationReports struct {
+	Admin  TokenResponse
+	Limit  int32
+	Offset int32
+}
+
+type GetAdminAuditLogs struct {
+	Admin     TokenResponse
+	Reference string
+	AdminID   int32
+	Limit     int32
+	Offset    int32
+}
+
+type AdminAuditLogResponse struct {
+	ID         int32     `json:"id"`
+	AdminID    int32     `json:"admin_id"`
+	AdminEmail string    `json:"admin_email"`
+	Action     string    `json:"action"`
+	Reference  string    `json:"reference,omitempty"`
+	Reason     string    `json:"reason,omitempty"`
+	Detail     string    `json:"detail,omitempty"`
+	Succeeded  bool      `json:"succeeded"`
+	Error      string    `json:"error,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
@@ -0,0 +1,47 @@
+package model
+
+import "time"
+
+type TransactionBatchPayload struct {
+	AllOrNothing bool                   `json:"all_or_nothing"`
+	Items        []TransactionBatchItem `json:"items" validate:"required,min=1"`
+	UserID       int32
+	// Payout lets the items name another user_id, items without one are the
+	// caller's.
+	Payout bool `json:"-"`
+}
+
+// TransactionBatchItem is a transaction of a batch. Unlike a single
+// transaction it can name its owner, which only a payout batch may set to
+// another user.
+type TransactionBatchItem struct {
+	TransactionPayload
+	UserID int32 `json:"user_id"`
+}
+
+func (u *TransactionBatchPayload) Validate() error {
+	return Validate.Struct(u)
+}
+
+type GetTransactionBatch struct {
+	UserID  int32
+	BatchID string
+}
+
+type TransactionBatchItemResult struct {
+	Index     int32  `json:"index"`
+	Reference string `json:"reference,omitempty"`
+	Status    string `json:"status"`
+	Error     string `json:"error,omitempty"`
+}
+
+type TransactionBatchResponse struct {
+	BatchID       string                       `json:"batch_id"`
+	Status        string                       `json:"status"`
+	AllOrNothing  bool                         `json:"all_or_nothing"`
+	TotalItems    int32                        `json:"total_items"`
+	CreatedItems  int32                        `json:"created_items"`
+	RejectedItems int32                        `json:"rejected_items"`
+	CreatedAt     time.Time                    `json:"created_at"`
+	Items         []TransactionBatchItemResult `json:"items"`
+}
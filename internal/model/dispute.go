@@ -0,0 +1,69 @@
+package model
+
+import "time"
+
+type DisputePayload struct {
+	Reference string `json:"reference"`
+	Reason    string `json:"reason" validate:"required,min=5,max=1000"`
+	UserID    int32
+}
+
+func (u *DisputePayload) Validate() error {
+	return Validate.Struct(u)
+}
+
+type DisputeEvidencePayload struct {
+	DisputeID      int32
+	Message        string `json:"message" validate:"required,max=2000"`
+	AttachmentName string `json:"attachment_name" validate:"omitempty,max=255"`
+	AttachmentURL  string `json:"attachment_url" validate:"omitempty,url,max=1024"`
+	UserID         int32
+}
+
+func (u *DisputeEvidencePayload) Validate() error {
+	return Validate.Struct(u)
+}
+
+type DisputeResolvePayload struct {
+	DisputeID  int32
+	Outcome    string `json:"outcome" validate:"required,oneof=WON LOST"`
+	Resolution string `json:"resolution" validate:"required,min=5,max=1000"`
+}
+
+func (u *DisputeResolvePayload) Validate() error {
+	return Validate.Struct(u)
+}
+
+type GetDispute struct {
+	UserID    int32
+	DisputeID int32
+}
+
+type GetDisputes struct {
+	UserID int32
+	Limit  int32
+	Offset int32
+}
+
+type DisputeEvidenceResponse struct {
+	ID             int32     `json:"id"`
+	Author         string    `json:"author"`
+	Message        string    `json:"message"`
+	AttachmentName string    `json:"attachment_name,omitempty"`
+	AttachmentURL  string    `json:"attachment_url,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+type DisputeResponse struct {
+	ID                   int32                     `json:"id"`
+	TransactionReference string                    `json:"transaction_reference"`
+	Amount               float64                   `json:"amount"`
+	Reason               string                    `json:"reason"`
+	Status               string                    `json:"status"`
+	CreditReference      string                    `json:"credit_reference,omitempty"`
+	Resolution           string                    `json:"resolution,omitempty"`
+	DeadlineAt           time.Time                 `json:"deadline_at"`
+	ResolvedAt           *time.Time                `json:"resolved_at,omitempty"`
+	CreatedAt            time.Time                 `json:"created_at"`
+	Evidence             []DisputeEvidenceResponse `json:"evidence,omitempty"`
+}
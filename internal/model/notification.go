@@ -0,0 +1,36 @@
+package model
+
+import "time"
+
+type QuietHours struct {
+	Start string `json:"start" validate:"required,datetime=15:04"`
+	End   string `json:"end" validate:"required,datetime=15:04"`
+	// Timezone is an IANA name, UTC when empty.
+	Timezone string `json:"timezone" validate:"omitempty,timezone"`
+}
+
+type NotificationPreferencePayload struct {
+	// DisabledEvents are "<type>.<status>" events that don't notify, e.g.
+	// "purchase.success".
+	DisabledEvents []string    `json:"disabled_events" validate:"omitempty,max=50,dive,max=64"`
+	Channels       []string    `json:"channels" validate:"required,dive,oneof=email push sms"`
+	QuietHours     *QuietHours `json:"quiet_hours"`
+	MinAmount      float64     `json:"min_amount" validate:"gte=0"`
+	UserID         int32       `json:"-"`
+}
+
+func (u *NotificationPreferencePayload) Validate() error {
+	return Validate.Struct(u)
+}
+
+type GetNotificationPreference struct {
+	UserID int32
+}
+
+type NotificationPreferenceResponse struct {
+	DisabledEvents []string    `json:"disabled_events"`
+	Channels       []string    `json:"channels"`
+	QuietHours     *QuietHours `json:"quiet_hours"`
+	MinAmount      float64     `json:"min_amount"`
+	UpdatedAt      *time.Time  `json:"updated_at,omitempty"`
+}
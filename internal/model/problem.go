@@ -0,0 +1,28 @@
+package model
+
+import "strings"
+
+// Problem is an RFC 7807 problem details object. Code is an extension member
+// that stays the same across releases, Errors lists the fields that failed
+// validation.
+type Problem struct {
+	Type     string       `json:"type"`
+	Title    string       `json:"title"`
+	Status   int          `json:"status"`
+	Detail   string       `json:"detail,omitempty"`
+	Instance string       `json:"instance,omitempty"`
+	Code     string       `json:"code"`
+	Errors   []FieldError `json:"errors,omitempty"`
+}
+
+// FieldErrorList carries field errors found outside the validator, e.g. by the
+// OpenAPI request validation. FieldErrors returns them as they are.
+type FieldErrorList []FieldError
+
+func (l FieldErrorList) Error() string {
+	msgs := make([]string, 0, len(l))
+	for _, f := range l {
+		msgs = append(msgs, f.Message)
+	}
+	return strings.Join(msgs, "; ")
+}
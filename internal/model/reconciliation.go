@@ -0,0 +1,30 @@
+package model
+
+import "time"
+
+type GetReconciliationReports struct {
+	Limit  int32
+	Offset int32
+}
+
+type ReconciliationDiscrepancy struct {
+	Category          string   `json:"category"`
+	Reference         string   `json:"reference"`
+	TransactionAmount *float64 `json:"transaction_amount,omitempty"`
+	WalletAmount      *float64 `json:"wallet_amount,omitempty"`
+	TransactionStatus string   `json:"transaction_status,omitempty"`
+	SuggestedRepair   string   `json:"suggested_repair"`
+}
+
+type ReconciliationReport struct {
+	ID               int32                       `json:"id"`
+	WindowStart      time.Time                   `json:"window_start"`
+	WindowEnd        time.Time                   `json:"window_end"`
+	Status           string                      `json:"status"`
+	MatchedCount     int32                       `json:"matched_count"`
+	DiscrepancyCount int32                       `json:"discrepancy_count"`
+	Error            string                      `json:"error,omitempty"`
+	CreatedAt        time.Time                   `json:"created_at"`
+	FinishedAt       *time.Time                  `json:"finished_at,omitempty"`
+	Discrepancies    []ReconciliationDiscrepancy `json:"discrepancies,omitempty"`
+}
@@ -0,0 +1,19 @@
+package model
+
+import "time"
+
+type GetRewards struct {
+	UserID int32
+	Limit  int32
+	Offset int32
+}
+
+type RewardResponse struct {
+	CampaignID           int32     `json:"campaign_id"`
+	TransactionReference string    `json:"transaction_reference"`
+	CreditReference      string    `json:"credit_reference,omitempty"`
+	Amount               float64   `json:"amount"`
+	Status               string    `json:"status"`
+	ClearAt              time.Time `json:"clear_at"`
+	CreatedAt            time.Time `json:"created_at"`
+}
@@ -0,0 +1,32 @@
+package model
+
+import "time"
+
+// GetStatusEvents is the position of a stream in a user's status changes,
+// reading events moves it past them.
+type GetStatusEvents struct {
+	UserID  int32
+	AfterID int64
+	// Seen are the ids sent that can still be read again, by the time they
+	// were written.
+	Seen map[int64]time.Time
+}
+
+type TransactionStatusEvent struct {
+	ID              int64     `json:"id"`
+	Reference       string    `json:"reference"`
+	TransactionType string    `json:"transaction_type"`
+	PreviousStatus  string    `json:"previous_status"`
+	Status          string    `json:"status"`
+	Amount          float64   `json:"amount"`
+	OccurredAt      time.Time `json:"occurred_at"`
+}
+
+// ListTransactions filters a user's history, zero values match everything.
+type ListTransactions struct {
+	UserID            int32
+	TransactionType   string
+	TransactionStatus string
+	From              time.Time
+	To                time.Time
+}
@@ -1,6 +1,15 @@
 package model
 
+// Roles carried in the validated identity.
+const (
+	RoleUser             = "user"
+	RolePaymentProcessor = "payment_processor"
+	RoleAdmin            = "admin"
+)
+
 type TokenResponse struct {
-	UserID int32  `json:"user_id"`
-	Email  string `json:"email"`
+	UserID int32    `json:"user_id"`
+	Email  string   `json:"email"`
+	Role   string   `json:"role"`
+	Scopes []string `json:"scopes"`
 }
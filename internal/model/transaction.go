@@ -10,14 +10,19 @@ var Validate *validator.Validate
 
 func init() {
 	Validate = validator.New(validator.WithRequiredStructEnabled())
+	if err := Validate.RegisterValidation("https_url", isHTTPSURL); err != nil {
+		panic(err)
+	}
+	registerTranslations(Validate)
 }
 
 type TransactionPayload struct {
-	UserID          int32   `json:"user_id"`
+	UserID          int32   `json:"-"`
 	Amount          float64 `json:"amount" validate:"required,numeric,gte=1000"`
 	TransactionType string  `json:"transaction_type" validate:"required"`
 	Description     string  `json:"description" validate:"required,min=5,max=255"`
 	AdditionalInfo  string  `json:"additional_info" validate:"omitempty"`
+	Email           string  `json:"-"`
 }
 
 func (u *TransactionPayload) Validate() error {
@@ -28,8 +33,9 @@ type TransactionUpdatePayload struct {
 	Reference         string `json:"reference"`
 	TransactionStatus string `json:"transaction_status" validate:"required"`
 	AdditionalInfo    string `json:"additional_info"`
-	Token             string
-	Email             string
+	// Reason is why the status was changed, set when support staff force a
+	// status.
+	Reason string `json:"-"`
 }
 
 func (u *TransactionUpdatePayload) Validate() error {
@@ -37,11 +43,12 @@ func (u *TransactionUpdatePayload) Validate() error {
 }
 
 type TransactionResponse struct {
-	WalletID  int32     `json:"wallet_id"`
-	Reference string    `json:"reference"`
-	Amount    float64   `json:"amount"`
-	CreatedAt time.Time `json:"created_at"`
-	Status    string    `json:"status"`
+	WalletID          int32     `json:"wallet_id"`
+	Reference         string    `json:"reference"`
+	ReversalReference string    `json:"reversal_reference,omitempty"`
+	Amount            float64   `json:"amount"`
+	CreatedAt         time.Time `json:"created_at"`
+	Status            string    `json:"status"`
 }
 
 type GetTransaction struct {
@@ -59,7 +66,10 @@ type TransactionRefundPayload struct {
 	Reference      string `json:"reference" validate:"required"`
 	Description    string `json:"description"`
 	AdditionalInfo string `json:"additional_info"`
-	Token          string
+	Email          string `json:"-"`
+	// UserID is the owner the purchase must belong to, zero when support
+	// staff refund on the owner's behalf.
+	UserID int32 `json:"-"`
 }
 
 func (u *TransactionRefundPayload) Validate() error {
@@ -72,3 +82,14 @@ type RefundResponse struct {
 	Amount            float64   `json:"amount"`
 	CreatedAt         time.Time `json:"created_at"`
 }
+
+type TransactionCancelPayload struct {
+	Reference string `json:"reference"`
+	Reason    string `json:"reason" validate:"omitempty,max=255"`
+	UserID    int32
+	Email     string
+}
+
+func (u *TransactionCancelPayload) Validate() error {
+	return Validate.Struct(u)
+}
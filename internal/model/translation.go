@@ -0,0 +1,113 @@
+package model
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/id"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	en_translations "github.com/go-playground/validator/v10/translations/en"
+	id_translations "github.com/go-playground/validator/v10/translations/id"
+)
+
+// Locales are the languages validation messages are translated to, the first
+// one is used when the client accepts none of them.
+var Locales = []string{"en", "id"}
+
+var translator *ut.UniversalTranslator
+
+// extraTranslations covers the tags used by the payloads that the validator
+// translations leave out.
+var extraTranslations = map[string]map[string]string{
+	"en": {
+		"timezone":  "{0} must be a valid IANA time zone",
+		"https_url": "{0} must be a valid HTTPS URL",
+	},
+	"id": {
+		"timezone":  "{0} harus berupa zona waktu IANA yang valid",
+		"https_url": "{0} harus berupa URL HTTPS yang valid",
+		"datetime":  "{0} tidak sesuai dengan format {1}",
+	},
+}
+
+// FieldError is one field that failed validation.
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// jsonName reports fields by the name the client sent them as.
+func jsonName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	switch name {
+	case "-":
+		return ""
+	case "":
+		return field.Name
+	}
+	return name
+}
+
+func registerTranslations(v *validator.Validate) {
+	v.RegisterTagNameFunc(jsonName)
+
+	translator = ut.New(en.New(), en.New(), id.New())
+	register := map[string]func(*validator.Validate, ut.Translator) error{
+		"en": en_translations.RegisterDefaultTranslations,
+		"id": id_translations.RegisterDefaultTranslations,
+	}
+
+	for _, locale := range Locales {
+		trans, _ := translator.GetTranslator(locale)
+		if err := register[locale](v, trans); err != nil {
+			panic(err)
+		}
+
+		for tag, text := range extraTranslations[locale] {
+			err := v.RegisterTranslation(tag, trans, func(ut ut.Translator) error {
+				return ut.Add(tag, text, true)
+			}, func(ut ut.Translator, fe validator.FieldError) string {
+				msg, err := ut.T(fe.Tag(), fe.Field(), fe.Param())
+				if err != nil {
+					return fe.Error()
+				}
+				return msg
+			})
+			if err != nil {
+				panic(err)
+			}
+		}
+	}
+}
+
+// FieldErrors translates the validation failures in err to locale, it returns
+// nil when err carries neither validator errors nor a FieldErrorList.
+func FieldErrors(err error, locale string) []FieldError {
+	var list FieldErrorList
+	if errors.As(err, &list) {
+		return list
+	}
+
+	var validationErrs validator.ValidationErrors
+	if !errors.As(err, &validationErrs) {
+		return nil
+	}
+
+	trans, _ := translator.GetTranslator(locale)
+	fields := make([]FieldError, 0, len(validationErrs))
+	for _, fe := range validationErrs {
+		// the namespace starts with the struct name, e.g.
+		// TransactionBatchPayload.items[0].amount
+		_, field, _ := strings.Cut(fe.Namespace(), ".")
+		fields = append(fields, FieldError{
+			Field:   field,
+			Rule:    fe.Tag(),
+			Message: fe.Translate(trans),
+		})
+	}
+	return fields
+}
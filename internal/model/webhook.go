@@ -0,0 +1,91 @@
+package model
+
+import (
+	"net/url"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// isHTTPSURL validates the https_url tag, webhooks are only delivered over
+// https.
+func isHTTPSURL(fl validator.FieldLevel) bool {
+	u, err := url.Parse(fl.Field().String())
+	return err == nil && u.Scheme == "https" && u.Host != ""
+}
+
+type WebhookEndpointPayload struct {
+	URL    string `json:"url" validate:"required,https_url,max=1024"`
+	UserID int32
+}
+
+func (u *WebhookEndpointPayload) Validate() error {
+	return Validate.Struct(u)
+}
+
+type GetWebhookEndpoints struct {
+	UserID int32
+}
+
+type GetWebhookEndpoint struct {
+	UserID     int32
+	EndpointID int32
+}
+
+type GetWebhookDeliveries struct {
+	UserID     int32
+	EndpointID int32
+	Limit      int32
+	Offset     int32
+}
+
+type GetWebhookDelivery struct {
+	UserID     int32
+	EndpointID int32
+	DeliveryID int32
+}
+
+// WebhookEvent is the body posted to webhook endpoints.
+type WebhookEvent struct {
+	Event           string    `json:"event"`
+	Reference       string    `json:"reference"`
+	UserID          int32     `json:"user_id"`
+	TransactionType string    `json:"transaction_type"`
+	PreviousStatus  string    `json:"previous_status"`
+	Status          string    `json:"status"`
+	Amount          float64   `json:"amount"`
+	OccurredAt      time.Time `json:"occurred_at"`
+}
+
+type WebhookEndpointResponse struct {
+	ID                  int32      `json:"id"`
+	URL                 string     `json:"url"`
+	Secret              string     `json:"secret,omitempty"`
+	IsActive            bool       `json:"is_active"`
+	ConsecutiveFailures int32      `json:"consecutive_failures"`
+	DisabledAt          *time.Time `json:"disabled_at,omitempty"`
+	CreatedAt           time.Time  `json:"created_at"`
+}
+
+type WebhookDeliveryAttemptResponse struct {
+	StatusCode int32     `json:"status_code,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	DurationMs int32     `json:"duration_ms"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type WebhookDeliveryResponse struct {
+	ID             int32                            `json:"id"`
+	EndpointID     int32                            `json:"endpoint_id"`
+	Event          string                           `json:"event"`
+	Reference      string                           `json:"reference"`
+	Payload        string                           `json:"payload"`
+	Status         string                           `json:"status"`
+	Attempts       int32                            `json:"attempts"`
+	NextAttemptAt  time.Time                        `json:"next_attempt_at"`
+	LastStatusCode int32                            `json:"last_status_code,omitempty"`
+	LastError      string                           `json:"last_error,omitempty"`
+	DeliveredAt    *time.Time                       `json:"delivered_at,omitempty"`
+	CreatedAt      time.Time                        `json:"created_at"`
+	AttemptLog     []WebhookDeliveryAttemptResponse `json:"attempt_log,omitempty"`
+}
@@ -0,0 +1,84 @@
+package notification
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	ChannelEmail = "email"
+	ChannelPush  = "push"
+	ChannelSMS   = "sms"
+)
+
+// Event names a transaction reaching a status, e.g. "purchase.success". Users
+// turn notifications off per event.
+func Event(transactionType, status string) string {
+	return fmt.Sprintf("%s.%s", strings.ToLower(transactionType), strings.ToLower(status))
+}
+
+// Preferences decide which queued notifications a user gets and when.
+type Preferences struct {
+	DisabledEvents []string
+	Channels       []string
+	// QuietStart and QuietEnd are minutes after midnight in Location, there
+	// are no quiet hours when they are equal. The window may span midnight.
+	QuietStart int
+	QuietEnd   int
+	Location   *time.Location
+	MinAmount  float64
+}
+
+// DefaultPreferences apply to users who never set any, every event is sent by
+// email right away.
+func DefaultPreferences() Preferences {
+	return Preferences{
+		Channels: []string{ChannelEmail},
+		Location: time.UTC,
+	}
+}
+
+// Allows reports whether a notification of event for amount may be sent on
+// channel.
+func (p Preferences) Allows(event, channel string, amount float64) bool {
+	if slices.Contains(p.DisabledEvents, event) {
+		return false
+	}
+	if !slices.Contains(p.Channels, channel) {
+		return false
+	}
+	return amount >= p.MinAmount
+}
+
+// QuietUntil returns when the quiet hours around now end, ok is false when now
+// is outside them.
+func (p Preferences) QuietUntil(now time.Time) (until time.Time, ok bool) {
+	if p.QuietStart == p.QuietEnd {
+		return time.Time{}, false
+	}
+
+	loc := p.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+	local := now.In(loc)
+	minute := local.Hour()*60 + local.Minute()
+
+	inside := p.QuietStart <= minute && minute < p.QuietEnd
+	if p.QuietStart > p.QuietEnd {
+		inside = minute >= p.QuietStart || minute < p.QuietEnd
+	}
+	if !inside {
+		return time.Time{}, false
+	}
+
+	// built from the wall clock rather than added to midnight, so the end
+	// stays right on days the clocks change
+	day := local.Day()
+	if minute >= p.QuietEnd {
+		day++
+	}
+	return time.Date(local.Year(), local.Month(), day, 0, p.QuietEnd, 0, 0, loc), true
+}
@@ -0,0 +1,183 @@
+package notification
+
+import (
+	"testing"
+	"time"
+)
+
+func clock(t *testing.T, loc *time.Location, value string) time.Time {
+	t.Helper()
+
+	parsed, err := time.ParseInLocation("2006-01-02 15:04", value, loc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return parsed
+}
+
+func TestQuietUntil(t *testing.T) {
+	jakarta := time.FixedZone("WIB", 7*60*60)
+
+	tests := []struct {
+		name      string
+		prefs     Preferences
+		loc       *time.Location
+		now       string
+		wantQuiet bool
+		wantUntil string
+	}{
+		{
+			name:  "no quiet hours",
+			prefs: Preferences{QuietStart: 600, QuietEnd: 600},
+			loc:   time.UTC,
+			now:   "2026-10-19 10:00",
+		},
+		{
+			name:      "inside a daytime window",
+			prefs:     Preferences{QuietStart: 12 * 60, QuietEnd: 13 * 60},
+			loc:       time.UTC,
+			now:       "2026-10-19 12:30",
+			wantQuiet: true,
+			wantUntil: "2026-10-19 13:00",
+		},
+		{
+			name:      "daytime window start is inside",
+			prefs:     Preferences{QuietStart: 12 * 60, QuietEnd: 13 * 60},
+			loc:       time.UTC,
+			now:       "2026-10-19 12:00",
+			wantQuiet: true,
+			wantUntil: "2026-10-19 13:00",
+		},
+		{
+			name:  "daytime window end is outside",
+			prefs: Preferences{QuietStart: 12 * 60, QuietEnd: 13 * 60},
+			loc:   time.UTC,
+			now:   "2026-10-19 13:00",
+		},
+		{
+			name:  "before a daytime window",
+			prefs: Preferences{QuietStart: 12 * 60, QuietEnd: 13 * 60},
+			loc:   time.UTC,
+			now:   "2026-10-19 11:59",
+		},
+		{
+			name:      "wrapping window before midnight ends the next day",
+			prefs:     Preferences{QuietStart: 22 * 60, QuietEnd: 7 * 60},
+			loc:       time.UTC,
+			now:       "2026-10-19 23:30",
+			wantQuiet: true,
+			wantUntil: "2026-10-20 07:00",
+		},
+		{
+			name:      "wrapping window after midnight ends the same day",
+			prefs:     Preferences{QuietStart: 22 * 60, QuietEnd: 7 * 60},
+			loc:       time.UTC,
+			now:       "2026-10-20 03:00",
+			wantQuiet: true,
+			wantUntil: "2026-10-20 07:00",
+		},
+		{
+			name:      "wrapping window across the end of a month",
+			prefs:     Preferences{QuietStart: 22 * 60, QuietEnd: 7 * 60},
+			loc:       time.UTC,
+			now:       "2026-10-31 22:00",
+			wantQuiet: true,
+			wantUntil: "2026-11-01 07:00",
+		},
+		{
+			name:  "outside a wrapping window",
+			prefs: Preferences{QuietStart: 22 * 60, QuietEnd: 7 * 60},
+			loc:   time.UTC,
+			now:   "2026-10-19 07:00",
+		},
+		{
+			name:      "in the user's timezone",
+			prefs:     Preferences{QuietStart: 22 * 60, QuietEnd: 7 * 60, Location: jakarta},
+			loc:       time.UTC,
+			now:       "2026-10-19 16:00",
+			wantQuiet: true,
+			wantUntil: "2026-10-20 00:00",
+		},
+		{
+			name:  "quiet in UTC but not in the user's timezone",
+			prefs: Preferences{QuietStart: 22 * 60, QuietEnd: 7 * 60, Location: jakarta},
+			loc:   time.UTC,
+			now:   "2026-10-20 02:00",
+		},
+		{
+			name:      "no location is UTC",
+			prefs:     Preferences{QuietStart: 22 * 60, QuietEnd: 7 * 60},
+			loc:       jakarta,
+			now:       "2026-10-20 06:00",
+			wantQuiet: true,
+			wantUntil: "2026-10-20 14:00",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			until, quiet := tt.prefs.QuietUntil(clock(t, tt.loc, tt.now))
+			if quiet != tt.wantQuiet {
+				t.Fatalf("QuietUntil() quiet = %v, want %v", quiet, tt.wantQuiet)
+			}
+			if !quiet {
+				return
+			}
+			if want := clock(t, tt.loc, tt.wantUntil); !until.Equal(want) {
+				t.Errorf("QuietUntil() until = %s, want %s", until, want)
+			}
+		})
+	}
+}
+
+func TestQuietUntilDaylightSaving(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("timezone data unavailable: %v", err)
+	}
+
+	// clocks jump from 02:00 to 03:00 on 2026-03-08, the quiet hours still end
+	// at 04:00 on the wall clock
+	prefs := Preferences{QuietStart: 60, QuietEnd: 4 * 60, Location: newYork}
+	until, quiet := prefs.QuietUntil(clock(t, newYork, "2026-03-08 01:30"))
+	if !quiet {
+		t.Fatal("QuietUntil() quiet = false, want true")
+	}
+	if want := clock(t, newYork, "2026-03-08 04:00"); !until.Equal(want) {
+		t.Errorf("QuietUntil() until = %s, want %s", until, want)
+	}
+}
+
+func TestAllows(t *testing.T) {
+	prefs := Preferences{
+		DisabledEvents: []string{Event("TOPUP", "SUCCESS")},
+		Channels:       []string{ChannelEmail, ChannelPush},
+		MinAmount:      10000,
+	}
+
+	tests := []struct {
+		name    string
+		prefs   Preferences
+		event   string
+		channel string
+		amount  float64
+		want    bool
+	}{
+		{name: "allowed", prefs: prefs, event: "purchase.success", channel: ChannelEmail, amount: 20000, want: true},
+		{name: "at the minimum amount", prefs: prefs, event: "purchase.success", channel: ChannelPush, amount: 10000, want: true},
+		{name: "below the minimum amount", prefs: prefs, event: "purchase.success", channel: ChannelEmail, amount: 9999, want: false},
+		{name: "disabled event", prefs: prefs, event: "topup.success", channel: ChannelEmail, amount: 20000, want: false},
+		{name: "channel not picked", prefs: prefs, event: "purchase.success", channel: ChannelSMS, amount: 20000, want: false},
+		{name: "every channel off", prefs: Preferences{}, event: "purchase.success", channel: ChannelEmail, amount: 20000, want: false},
+		{name: "defaults send email", prefs: DefaultPreferences(), event: "purchase.success", channel: ChannelEmail, amount: 1, want: true},
+		{name: "defaults don't push", prefs: DefaultPreferences(), event: "purchase.success", channel: ChannelPush, amount: 1, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.prefs.Allows(tt.event, tt.channel, tt.amount); got != tt.want {
+				t.Errorf("Allows(%q, %q, %v) = %v, want %v", tt.event, tt.channel, tt.amount, got, tt.want)
+			}
+		})
+	}
+}
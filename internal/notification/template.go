@@ -0,0 +1,125 @@
+package notification
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// Placeholder names a template can ask for.
+const (
+	PlaceholderUserID            = "user_id"
+	PlaceholderAmount            = "amount"
+	PlaceholderReference         = "reference"
+	PlaceholderTransactionType   = "transaction_type"
+	PlaceholderStatus            = "status"
+	PlaceholderPreviousStatus    = "previous_status"
+	PlaceholderCreatedAt         = "created_at"
+	PlaceholderUpdatedAt         = "updated_at"
+	PlaceholderReversalReference = "reversal_reference"
+	PlaceholderOriginalReference = "original_reference"
+)
+
+var placeholders = map[string]bool{
+	PlaceholderUserID:            true,
+	PlaceholderAmount:            true,
+	PlaceholderReference:         true,
+	PlaceholderTransactionType:   true,
+	PlaceholderStatus:            true,
+	PlaceholderPreviousStatus:    true,
+	PlaceholderCreatedAt:         true,
+	PlaceholderUpdatedAt:         true,
+	PlaceholderReversalReference: true,
+	PlaceholderOriginalReference: true,
+}
+
+// Template is the notification sent for a transition and the placeholders it
+// is rendered with.
+type Template struct {
+	Name         string   `json:"template"`
+	Placeholders []string `json:"placeholders"`
+}
+
+// Templates maps a transition to its template. A key is either
+// "TYPE:FROM->TO" or "TYPE:TO" for any previous status, a new transaction has
+// no previous status. A template with an empty name turns the notification
+// off.
+type Templates map[string]Template
+
+// basePlaceholders are the placeholders the original templates were written
+// for, plus extra.
+func basePlaceholders(extra ...string) []string {
+	return append([]string{PlaceholderUserID, PlaceholderAmount, PlaceholderReference, PlaceholderCreatedAt}, extra...)
+}
+
+// DefaultTemplates are used for every transition the templates file doesn't
+// set.
+func DefaultTemplates() Templates {
+	return Templates{
+		"TOPUP:SUCCESS":      {Name: "topup_success", Placeholders: basePlaceholders()},
+		"TOPUP:FAILED":       {Name: "topup_failed", Placeholders: basePlaceholders()},
+		"TOPUP:CANCELLED":    {Name: "topup_cancelled", Placeholders: basePlaceholders()},
+		"TOPUP:REVERSED":     {Name: "topup_reversed", Placeholders: basePlaceholders(PlaceholderReversalReference)},
+		"PURCHASE:SUCCESS":   {Name: "purchase_success", Placeholders: basePlaceholders()},
+		"PURCHASE:FAILED":    {Name: "purchase_failed", Placeholders: basePlaceholders()},
+		"PURCHASE:CANCELLED": {Name: "purchase_cancelled", Placeholders: basePlaceholders()},
+		"PURCHASE:REVERSED":  {Name: "purchase_reversed", Placeholders: basePlaceholders(PlaceholderReversalReference)},
+		"REFUND:SUCCESS":     {Name: "refund_issued", Placeholders: basePlaceholders(PlaceholderOriginalReference)},
+		"REFUND:CANCELLED":   {Name: "refund_cancelled", Placeholders: basePlaceholders()},
+		"REFUND:REVERSED":    {Name: "refund_reversed", Placeholders: basePlaceholders(PlaceholderReversalReference)},
+		// a lost dispute takes its provisional credit back
+		"DISPUTE_CREDIT:REVERSED": {Name: "dispute_credit_reversed", Placeholders: basePlaceholders(PlaceholderReversalReference, PlaceholderOriginalReference)},
+	}
+}
+
+// LoadTemplates reads the templates file over the defaults, an empty path
+// keeps the defaults.
+func LoadTemplates(path string) (Templates, error) {
+	templates := DefaultTemplates()
+	if path == "" {
+		return templates, nil
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read notification templates :%w", err)
+	}
+
+	file := Templates{}
+	if err := json.Unmarshal(b, &file); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal notification templates :%w", err)
+	}
+
+	for key, template := range file {
+		for _, p := range template.Placeholders {
+			if !placeholders[p] {
+				return nil, fmt.Errorf("notification template %s has unknown placeholder %q", key, p)
+			}
+		}
+		templates[key] = template
+	}
+
+	return templates, nil
+}
+
+// Lookup returns the template of a transition, from is empty for a new
+// transaction.
+func (t Templates) Lookup(transactionType, from, to string) (Template, bool) {
+	template, ok := t[fmt.Sprintf("%s:%s->%s", transactionType, from, to)]
+	if !ok {
+		template, ok = t[fmt.Sprintf("%s:%s", transactionType, to)]
+	}
+	if !ok || template.Name == "" {
+		return Template{}, false
+	}
+	return template, true
+}
+
+// Render picks the template's placeholders out of values.
+func (t Template) Render(values map[string]string) map[string]string {
+	placeholder := make(map[string]string, len(t.Placeholders))
+	for _, p := range t.Placeholders {
+		placeholder[p] = values[p]
+	}
+	return placeholder
+}
@@ -0,0 +1,50 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/gofiber/fiber/v2"
+)
+
+// docsPage renders the document with Swagger UI, loaded from its CDN.
+const docsPage = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>%[1]s</title>
+<link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css">
+</head>
+<body>
+<div id="swagger-ui"></div>
+<script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js"></script>
+<script>
+window.ui = SwaggerUIBundle({url: %[2]q, dom_id: "#swagger-ui"});
+</script>
+</body>
+</html>
+`
+
+// SpecHandler serves doc as JSON. The document doesn't change once built, it
+// is encoded once.
+func SpecHandler(doc *openapi3.T) (fiber.Handler, error) {
+	body, err := json.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode openapi document :%w", err)
+	}
+
+	return func(ctx *fiber.Ctx) error {
+		ctx.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+		return ctx.Send(body)
+	}, nil
+}
+
+// DocsHandler serves the docs UI of the document at specURL.
+func DocsHandler(title, specURL string) fiber.Handler {
+	page := fmt.Sprintf(docsPage, title, specURL)
+	return func(ctx *fiber.Ctx) error {
+		ctx.Set(fiber.HeaderContentType, fiber.MIMETextHTMLCharsetUTF8)
+		return ctx.SendString(page)
+	}
+}
@@ -0,0 +1,454 @@
+// Package openapi builds the OpenAPI 3 document of the HTTP API from the
+// routes registered on the Fiber app and the model types they read and write,
+// and validates incoming requests against it.
+package openapi
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/model"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3gen"
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	problemContentType = "application/problem+json"
+	bearerAuth         = "bearerAuth"
+)
+
+// Param is a path or query parameter. Path parameters that are not listed are
+// documented as strings.
+type Param struct {
+	In          string
+	Name        string
+	Type        string
+	Description string
+}
+
+func PathInt(name, description string) Param {
+	return Param{In: openapi3.ParameterInPath, Name: name, Type: openapi3.TypeInteger, Description: description}
+}
+
+func QueryInt(name, description string) Param {
+	return Param{In: openapi3.ParameterInQuery, Name: name, Type: openapi3.TypeInteger, Description: description}
+}
+
+func QueryString(name, description string) Param {
+	return Param{In: openapi3.ParameterInQuery, Name: name, Type: openapi3.TypeString, Description: description}
+}
+
+func QueryTime(name, description string) Param {
+	return Param{In: openapi3.ParameterInQuery, Name: name, Type: "date-time", Description: description}
+}
+
+// Operation documents one route.
+type Operation struct {
+	Summary string
+	Tag     string
+	Params  []Param
+	// Request is the model read from the body, nil when the route has none.
+	Request any
+	// OptionalBody marks a body the route also accepts empty.
+	OptionalBody bool
+	// Response is the data of the success envelope, nil when there is none.
+	Response any
+	// Status is the success status, 200 when zero.
+	Status int
+	// ContentType of the success response, JSON when empty.
+	ContentType string
+	// Public routes don't take a bearer token.
+	Public bool
+}
+
+type Info struct {
+	Title   string
+	Version string
+}
+
+// Path turns a Fiber path into an OpenAPI one, e.g. /v1/dispute/:id becomes
+// /v1/dispute/{id}. Fiber doesn't tell /v1/transaction/ apart from
+// /v1/transaction, the trailing slash is dropped.
+func Path(fiberPath string) string {
+	segments := strings.Split(fiberPath, "/")
+	for i, s := range segments {
+		if strings.HasPrefix(s, ":") {
+			segments[i] = "{" + strings.TrimSuffix(s[1:], "?") + "}"
+		}
+	}
+	path := strings.Join(segments, "/")
+	if len(path) > 1 {
+		path = strings.TrimSuffix(path, "/")
+	}
+	return path
+}
+
+// Build documents every route registered on the app with the operations keyed
+// by "METHOD path", the same keys as policy.Routes. It fails when a route is
+// not documented or a documented route is not registered, so the document
+// can't drift from the router.
+func Build(routes []fiber.Route, operations map[string]Operation, info Info) (*openapi3.T, error) {
+	doc := &openapi3.T{
+		OpenAPI: "3.0.3",
+		Info: &openapi3.Info{
+			Title:   info.Title,
+			Version: info.Version,
+		},
+		Servers: openapi3.Servers{{URL: "/"}},
+		Paths:   openapi3.NewPaths(),
+		Components: &openapi3.Components{
+			Schemas: openapi3.Schemas{},
+			SecuritySchemes: openapi3.SecuritySchemes{
+				bearerAuth: &openapi3.SecuritySchemeRef{
+					Value: openapi3.NewJWTSecurityScheme(),
+				},
+			},
+		},
+	}
+
+	g := &generator{
+		schemas:   doc.Components.Schemas,
+		requests:  openapi3gen.NewGenerator(openapi3gen.SchemaCustomizer(customize)),
+		responses: openapi3gen.NewGenerator(openapi3gen.SchemaCustomizer(customize), openapi3gen.UseAllExportedFields()),
+	}
+
+	problem, err := g.component(g.responses, model.Problem{})
+	if err != nil {
+		return nil, err
+	}
+
+	documented := map[string]bool{}
+	var missing []string
+	for _, route := range routes {
+		// Fiber registers HEAD next to every GET
+		if route.Method == fiber.MethodHead {
+			continue
+		}
+
+		key := route.Method + " " + route.Path
+		op, ok := operations[key]
+		if !ok {
+			missing = append(missing, key)
+			continue
+		}
+		if documented[key] {
+			continue
+		}
+		documented[key] = true
+
+		operation, err := g.operation(route, op, problem)
+		if err != nil {
+			return nil, fmt.Errorf("failed to document %s :%w", key, err)
+		}
+		doc.AddOperation(Path(route.Path), route.Method, operation)
+	}
+
+	for key := range operations {
+		if !documented[key] {
+			missing = append(missing, key)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return nil, fmt.Errorf("routes and operations out of sync: %s", strings.Join(missing, ", "))
+	}
+
+	if err := doc.Validate(context.Background()); err != nil {
+		return nil, fmt.Errorf("invalid openapi document :%w", err)
+	}
+
+	return doc, nil
+}
+
+type generator struct {
+	schemas openapi3.Schemas
+	// requests only document fields with a json tag, the ones the body
+	// parser fills. Responses also carry the untagged fields of the sqlc
+	// rows, which encoding/json writes under their Go names.
+	requests  *openapi3gen.Generator
+	responses *openapi3gen.Generator
+}
+
+// component adds the schema of v's type to the components once and refers to
+// it.
+func (g *generator) component(gen *openapi3gen.Generator, v any) (*openapi3.SchemaRef, error) {
+	t := reflect.TypeOf(v)
+	for t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice {
+		t = t.Elem()
+	}
+
+	name := t.Name()
+	if _, ok := g.schemas[name]; !ok {
+		ref, err := gen.GenerateSchemaRef(t)
+		if err != nil {
+			return nil, err
+		}
+		inline(ref.Value)
+		if gen == g.requests {
+			closeObjects(ref.Value)
+		}
+		g.schemas[name] = openapi3.NewSchemaRef("", ref.Value)
+	}
+
+	ref := openapi3.NewSchemaRef("#/components/schemas/"+name, g.schemas[name].Value)
+	if reflect.TypeOf(v).Kind() == reflect.Slice {
+		array := openapi3.NewArraySchema()
+		array.Items = ref
+		return openapi3.NewSchemaRef("", array), nil
+	}
+	return ref, nil
+}
+
+func (g *generator) operation(route fiber.Route, op Operation, problem *openapi3.SchemaRef) (*openapi3.Operation, error) {
+	operation := openapi3.NewOperation()
+	operation.Summary = op.Summary
+	operation.OperationID = operationID(route)
+	if op.Tag != "" {
+		operation.Tags = []string{op.Tag}
+	}
+	if !op.Public {
+		operation.Security = openapi3.NewSecurityRequirements().With(openapi3.NewSecurityRequirement().Authenticate(bearerAuth))
+	}
+
+	for _, name := range route.Params {
+		param := Param{In: openapi3.ParameterInPath, Name: name, Type: openapi3.TypeString}
+		if i := slices.IndexFunc(op.Params, func(p Param) bool {
+			return p.In == openapi3.ParameterInPath && p.Name == name
+		}); i >= 0 {
+			param = op.Params[i]
+		}
+		operation.AddParameter(parameter(param))
+	}
+	for _, p := range op.Params {
+		if p.In == openapi3.ParameterInQuery {
+			operation.AddParameter(parameter(p))
+		}
+	}
+
+	if op.Request != nil {
+		schema, err := g.component(g.requests, op.Request)
+		if err != nil {
+			return nil, err
+		}
+		body := openapi3.NewRequestBody().WithJSONSchemaRef(schema)
+		body.Required = !op.OptionalBody
+		operation.RequestBody = &openapi3.RequestBodyRef{Value: body}
+	}
+
+	status := op.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+
+	response := openapi3.NewResponse().WithDescription(http.StatusText(status))
+	switch {
+	case op.ContentType != "":
+		response.WithContent(openapi3.NewContentWithSchema(openapi3.NewStringSchema(), []string{op.ContentType}))
+	case status != http.StatusSwitchingProtocols:
+		envelope := openapi3.NewObjectSchema().
+			WithProperty("message", openapi3.NewStringSchema())
+		envelope.Required = []string{"message"}
+		if op.Response != nil {
+			data, err := g.component(g.responses, op.Response)
+			if err != nil {
+				return nil, err
+			}
+			envelope.WithPropertyRef("data", data)
+		}
+		response.WithJSONSchema(envelope)
+	}
+	operation.AddResponse(status, response)
+
+	errorResponse := openapi3.NewResponse().
+		WithDescription("Problem details, see RFC 7807").
+		WithContent(openapi3.NewContentWithSchemaRef(problem, []string{problemContentType}))
+	operation.Responses.Set("default", &openapi3.ResponseRef{Value: errorResponse})
+
+	return operation, nil
+}
+
+func parameter(p Param) *openapi3.Parameter {
+	var param *openapi3.Parameter
+	if p.In == openapi3.ParameterInPath {
+		param = openapi3.NewPathParameter(p.Name)
+	} else {
+		param = openapi3.NewQueryParameter(p.Name)
+	}
+	param.Description = p.Description
+
+	switch p.Type {
+	case openapi3.TypeInteger:
+		param.WithSchema(openapi3.NewInt64Schema())
+	case "date-time":
+		param.WithSchema(openapi3.NewDateTimeSchema())
+	default:
+		param.WithSchema(openapi3.NewStringSchema())
+	}
+	return param
+}
+
+// operationID names an operation after its method and path, e.g.
+// get_v1_dispute_id.
+func operationID(route fiber.Route) string {
+	var b strings.Builder
+	b.WriteString(strings.ToLower(route.Method))
+	for _, s := range strings.Split(route.Path, "/") {
+		s = strings.Trim(s, ":?")
+		if s == "" {
+			continue
+		}
+		b.WriteByte('_')
+		b.WriteString(strings.ReplaceAll(s, "-", "_"))
+	}
+	return b.String()
+}
+
+// inline drops the type names the generator keeps as refs of nested schemas,
+// they point nowhere in the document. The schemas stay inlined.
+func inline(schema *openapi3.Schema) {
+	if schema == nil {
+		return
+	}
+	for _, prop := range schema.Properties {
+		prop.Ref = ""
+		inline(prop.Value)
+	}
+	if schema.Items != nil {
+		schema.Items.Ref = ""
+		inline(schema.Items.Value)
+	}
+}
+
+// closeObjects refuses properties a request model doesn't have, the strict
+// validation rejects them and the lenient one reports them.
+func closeObjects(schema *openapi3.Schema) {
+	if schema == nil {
+		return
+	}
+	if schema.Type.Is(openapi3.TypeObject) && schema.AdditionalProperties.Schema == nil {
+		closed := false
+		schema.AdditionalProperties.Has = &closed
+	}
+	for _, prop := range schema.Properties {
+		closeObjects(prop.Value)
+	}
+	if schema.Items != nil {
+		closeObjects(schema.Items.Value)
+	}
+}
+
+var pgtypePkg = "github.com/jackc/pgx/v5/pgtype"
+
+// customize documents the pgtype values as they are encoded and applies the
+// validate tags of a struct's fields to their property schemas. The tags are
+// read at the struct rather than at each field, the generator hands a slice's
+// tag to its items too.
+func customize(_ string, t reflect.Type, _ reflect.StructTag, schema *openapi3.Schema) error {
+	if t.PkgPath() == pgtypePkg {
+		switch t.Name() {
+		case "Numeric", "Float4", "Float8":
+			*schema = *openapi3.NewFloat64Schema()
+		case "Int2", "Int4", "Int8":
+			*schema = *openapi3.NewInt64Schema()
+		case "Bool":
+			*schema = *openapi3.NewBoolSchema()
+		case "Timestamp", "Timestamptz":
+			*schema = *openapi3.NewDateTimeSchema()
+		case "Date":
+			*schema = *openapi3.NewStringSchema().WithFormat("date")
+		default:
+			*schema = *openapi3.NewStringSchema()
+		}
+		schema.Nullable = true
+		return nil
+	}
+
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	for i := range t.NumField() {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		prop, ok := schema.Properties[name]
+		if !ok || prop.Value == nil {
+			continue
+		}
+
+		// rules after dive apply to the items of a slice
+		rules, itemRules, _ := strings.Cut(f.Tag.Get("validate"), ",dive")
+		if slices.Contains(strings.Split(rules, ","), "required") {
+			schema.Required = append(schema.Required, name)
+		}
+		applyRules(prop.Value, rules)
+		if prop.Value.Items != nil && prop.Value.Items.Value != nil {
+			applyRules(prop.Value.Items.Value, strings.TrimPrefix(itemRules, ","))
+		}
+	}
+	return nil
+}
+
+func applyRules(schema *openapi3.Schema, rules string) {
+	for _, rule := range strings.Split(rules, ",") {
+		key, param, _ := strings.Cut(rule, "=")
+		switch key {
+		case "min", "gte":
+			bound(schema, param, true)
+		case "max", "lte":
+			bound(schema, param, false)
+		case "oneof":
+			for _, v := range strings.Fields(param) {
+				schema.Enum = append(schema.Enum, v)
+			}
+		case "url", "http_url":
+			schema.Format = "uri"
+		case "https_url":
+			schema.Format = "uri"
+			schema.Pattern = "^https://"
+		case "datetime":
+			if param == "15:04" {
+				schema.Pattern = `^([01][0-9]|2[0-3]):[0-5][0-9]$`
+			}
+		case "timezone":
+			schema.Description = "IANA time zone, e.g. Asia/Jakarta"
+		}
+	}
+}
+
+// bound sets the lower or upper bound a min/max rule means for the type of
+// schema: a length for strings, a count for arrays and a value for numbers.
+func bound(schema *openapi3.Schema, param string, lower bool) {
+	n, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		return
+	}
+	u := uint64(n)
+
+	switch {
+	case schema.Type.Is(openapi3.TypeString):
+		if lower {
+			schema.MinLength = u
+		} else {
+			schema.MaxLength = &u
+		}
+	case schema.Type.Is(openapi3.TypeArray):
+		if lower {
+			schema.MinItems = u
+		} else {
+			schema.MaxItems = &u
+		}
+	case schema.Type.Is(openapi3.TypeNumber), schema.Type.Is(openapi3.TypeInteger):
+		if lower {
+			schema.Min = &n
+		} else {
+			schema.Max = &n
+		}
+	}
+}
@@ -0,0 +1,238 @@
+package openapi
+
+import (
+	"net/http"
+
+	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/model"
+	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/storage/sqlc"
+)
+
+var (
+	limit  = QueryInt("limit", "page size")
+	offset = QueryInt("offset", "page number, starting at 1")
+)
+
+// Operations documents every HTTP route, keyed like policy.Routes. Build
+// refuses to start the API when a route is missing here.
+var Operations = map[string]Operation{
+	"GET /health": {
+		Summary: "Check the API is up",
+		Tag:     "health",
+		Public:  true,
+	},
+
+	"POST /v1/transaction/": {
+		Summary:  "Create a transaction",
+		Tag:      "transaction",
+		Request:  model.TransactionPayload{},
+		Response: sqlc.CreateTransactionRow{},
+		Status:   http.StatusCreated,
+	},
+	"POST /v1/transaction/batch": {
+		Summary:  "Create a batch of transactions",
+		Tag:      "transaction",
+		Request:  model.TransactionBatchPayload{},
+		Response: model.TransactionBatchResponse{},
+		Status:   http.StatusCreated,
+	},
+	"GET /v1/transaction/batch/:batch_id": {
+		Summary:  "Get a batch and the result of its items",
+		Tag:      "transaction",
+		Response: model.TransactionBatchResponse{},
+	},
+	"GET /v1/transaction/notification-preferences": {
+		Summary:  "Get the caller's notification preferences",
+		Tag:      "notification",
+		Response: model.NotificationPreferenceResponse{},
+	},
+	"PUT /v1/transaction/notification-preferences": {
+		Summary:  "Replace the caller's notification preferences",
+		Tag:      "notification",
+		Request:  model.NotificationPreferencePayload{},
+		Response: model.NotificationPreferenceResponse{},
+	},
+	"PUT /v1/transaction/:reference": {
+		Summary:  "Settle a transaction",
+		Tag:      "transaction",
+		Request:  model.TransactionUpdatePayload{},
+		Response: model.TransactionResponse{},
+	},
+	"POST /v1/transaction/:reference/cancel": {
+		Summary:      "Cancel a pending transaction",
+		Tag:          "transaction",
+		Request:      model.TransactionCancelPayload{},
+		OptionalBody: true,
+		Response:     model.TransactionResponse{},
+	},
+	"POST /v1/transaction/:reference/dispute": {
+		Summary:  "Dispute a transaction",
+		Tag:      "dispute",
+		Request:  model.DisputePayload{},
+		Response: model.DisputeResponse{},
+		Status:   http.StatusCreated,
+	},
+	"GET /v1/transaction/": {
+		Summary:  "List the caller's transactions",
+		Tag:      "transaction",
+		Params:   []Param{limit, offset},
+		Response: []sqlc.GetTransactionsRow{},
+	},
+	"GET /v1/transaction/stream": {
+		Summary:     "Stream the caller's status changes as server-sent events",
+		Tag:         "stream",
+		Params:      []Param{QueryInt("last_event_id", "resume after this event, the Last-Event-ID header takes precedence")},
+		ContentType: "text/event-stream",
+	},
+	"GET /v1/transaction/stream/ws": {
+		Summary: "Stream the caller's status changes over a WebSocket",
+		Tag:     "stream",
+		Params:  []Param{QueryInt("last_event_id", "resume after this event")},
+		Status:  http.StatusSwitchingProtocols,
+	},
+	"GET /v1/transaction/rewards": {
+		Summary:  "List the caller's rewards",
+		Tag:      "reward",
+		Params:   []Param{limit, offset},
+		Response: []model.RewardResponse{},
+	},
+	"GET /v1/transaction/:reference": {
+		Summary:  "Get one of the caller's transactions",
+		Tag:      "transaction",
+		Response: sqlc.Transaction{},
+	},
+	"POST /v1/transaction/refund": {
+		Summary:  "Refund a purchase",
+		Tag:      "transaction",
+		Request:  model.TransactionRefundPayload{},
+		Response: model.RefundResponse{},
+		Status:   http.StatusCreated,
+	},
+
+	"GET /v1/dispute/": {
+		Summary:  "List the caller's disputes",
+		Tag:      "dispute",
+		Params:   []Param{limit, offset},
+		Response: []model.DisputeResponse{},
+	},
+	"GET /v1/dispute/:id": {
+		Summary:  "Get a dispute",
+		Tag:      "dispute",
+		Params:   []Param{PathInt("id", "dispute id")},
+		Response: model.DisputeResponse{},
+	},
+	"POST /v1/dispute/:id/evidence": {
+		Summary:  "Attach evidence to an open dispute",
+		Tag:      "dispute",
+		Params:   []Param{PathInt("id", "dispute id")},
+		Request:  model.DisputeEvidencePayload{},
+		Response: model.DisputeEvidenceResponse{},
+		Status:   http.StatusCreated,
+	},
+
+	"POST /v1/webhook/": {
+		Summary:  "Register a webhook endpoint",
+		Tag:      "webhook",
+		Request:  model.WebhookEndpointPayload{},
+		Response: model.WebhookEndpointResponse{},
+		Status:   http.StatusCreated,
+	},
+	"GET /v1/webhook/": {
+		Summary:  "List the caller's webhook endpoints",
+		Tag:      "webhook",
+		Response: []model.WebhookEndpointResponse{},
+	},
+	"DELETE /v1/webhook/:id": {
+		Summary: "Delete a webhook endpoint",
+		Tag:     "webhook",
+		Params:  []Param{PathInt("id", "webhook endpoint id")},
+	},
+	"POST /v1/webhook/:id/enable": {
+		Summary:  "Enable a disabled webhook endpoint",
+		Tag:      "webhook",
+		Params:   []Param{PathInt("id", "webhook endpoint id")},
+		Response: model.WebhookEndpointResponse{},
+	},
+	"GET /v1/webhook/:id/deliveries": {
+		Summary:  "List the deliveries to a webhook endpoint",
+		Tag:      "webhook",
+		Params:   []Param{PathInt("id", "webhook endpoint id"), limit, offset},
+		Response: []model.WebhookDeliveryResponse{},
+	},
+	"GET /v1/webhook/:id/deliveries/:delivery_id": {
+		Summary:  "Get a delivery and its attempts",
+		Tag:      "webhook",
+		Params:   []Param{PathInt("id", "webhook endpoint id"), PathInt("delivery_id", "delivery id")},
+		Response: model.WebhookDeliveryResponse{},
+	},
+	"POST /v1/webhook/:id/deliveries/:delivery_id/redeliver": {
+		Summary:  "Send a delivery again",
+		Tag:      "webhook",
+		Params:   []Param{PathInt("id", "webhook endpoint id"), PathInt("delivery_id", "delivery id")},
+		Response: model.WebhookDeliveryResponse{},
+		Status:   http.StatusCreated,
+	},
+
+	"GET /admin/v1/transaction": {
+		Summary: "Search transactions of every user",
+		Tag:     "admin",
+		Params: []Param{
+			limit,
+			offset,
+			QueryTime("from", "created at or after, RFC 3339"),
+			QueryTime("to", "created before, RFC 3339"),
+			QueryInt("user_id", "owner"),
+			QueryString("reference", "transaction reference"),
+			QueryString("transaction_type", "transaction type"),
+			QueryString("transaction_status", "transaction status"),
+		},
+		Response: []model.AdminTransactionResponse{},
+	},
+	"GET /admin/v1/transaction/:reference": {
+		Summary:  "Get a transaction with its history and audit log",
+		Tag:      "admin",
+		Response: model.AdminTransactionDetail{},
+	},
+	"PUT /admin/v1/transaction/:reference/status": {
+		Summary:  "Force the status of a transaction",
+		Tag:      "admin",
+		Request:  model.AdminStatusPayload{},
+		Response: model.TransactionResponse{},
+	},
+	"POST /admin/v1/transaction/:reference/refund": {
+		Summary:  "Refund a purchase on the owner's behalf",
+		Tag:      "admin",
+		Request:  model.AdminRefundPayload{},
+		Response: model.RefundResponse{},
+		Status:   http.StatusCreated,
+	},
+	"POST /admin/v1/dispute/:id/resolve": {
+		Summary:  "Resolve a dispute",
+		Tag:      "admin",
+		Params:   []Param{PathInt("id", "dispute id")},
+		Request:  model.AdminResolveDisputePayload{},
+		Response: model.DisputeResponse{},
+	},
+	"GET /admin/v1/reconciliation": {
+		Summary:  "List reconciliation reports",
+		Tag:      "admin",
+		Params:   []Param{limit, offset},
+		Response: []model.ReconciliationReport{},
+	},
+	"GET /admin/v1/reconciliation/:id": {
+		Summary:  "Get a reconciliation report",
+		Tag:      "admin",
+		Params:   []Param{PathInt("id", "report id")},
+		Response: model.ReconciliationReport{},
+	},
+	"GET /admin/v1/audit": {
+		Summary: "List the admin audit log",
+		Tag:     "admin",
+		Params: []Param{
+			QueryString("reference", "transaction reference"),
+			QueryInt("admin_id", "admin"),
+			limit,
+			offset,
+		},
+		Response: []model.AdminAuditLogResponse{},
+	},
+}
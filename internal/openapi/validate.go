@@ -0,0 +1,156 @@
+package openapi
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/apperror"
+	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/config/logger"
+	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/model"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+	"github.com/gofiber/fiber/v2"
+	"github.com/valyala/fasthttp/fasthttpadaptor"
+)
+
+var log = logger.NewLogger()
+
+// Validator checks incoming requests against the OpenAPI document. Requests
+// under a path prefix listed as lenient, e.g. /v1, only log what doesn't
+// match, so clients written before the document existed keep working. Every
+// other path, including /admin/v1 and the versions added later, is strict and
+// rejects them with a problem listing the offending fields.
+type Validator struct {
+	router  routers.Router
+	lenient []string
+}
+
+func NewValidator(lenientPrefixes []string) *Validator {
+	v := &Validator{}
+	for _, prefix := range lenientPrefixes {
+		prefix = strings.TrimSuffix(strings.TrimSpace(prefix), "/")
+		if prefix != "" {
+			v.lenient = append(v.lenient, prefix)
+		}
+	}
+	return v
+}
+
+// Load sets the document requests are validated against. The document is
+// built from the registered routes, so the middleware is installed before it
+// exists and lets every request through until then.
+func (v *Validator) Load(doc *openapi3.T) error {
+	router, err := gorillamux.NewRouter(doc)
+	if err != nil {
+		return err
+	}
+
+	v.router = router
+	return nil
+}
+
+func (v *Validator) Middleware() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		if v.router == nil {
+			return ctx.Next()
+		}
+
+		var req http.Request
+		if err := fasthttpadaptor.ConvertRequest(ctx.Context(), &req, true); err != nil {
+			return ctx.Next()
+		}
+		// the document drops the trailing slash of /v1/transaction/
+		if len(req.URL.Path) > 1 {
+			req.URL.Path = strings.TrimSuffix(req.URL.Path, "/")
+		}
+
+		// unknown routes are left to the router to answer
+		route, pathParams, err := v.router.FindRoute(&req)
+		if err != nil {
+			return ctx.Next()
+		}
+
+		err = openapi3filter.ValidateRequest(ctx.Context(), &openapi3filter.RequestValidationInput{
+			Request:    &req,
+			PathParams: pathParams,
+			Route:      route,
+			Options: &openapi3filter.Options{
+				MultiError:          true,
+				SkipSettingDefaults: true,
+				AuthenticationFunc:  openapi3filter.NoopAuthenticationFunc,
+			},
+		})
+		if err == nil {
+			return ctx.Next()
+		}
+
+		fields := model.FieldErrorList(fieldErrors(err, ""))
+		if v.isLenient(ctx.Path()) {
+			log.WithError(fields).Warnf("request does not match the openapi document, method: %v, path: %v", ctx.Method(), ctx.Path())
+			return ctx.Next()
+		}
+
+		return apperror.ErrValidation.Wrap(fields)
+	}
+}
+
+// isLenient reports whether path is under a lenient prefix, a prefix matches
+// whole segments so /v1 doesn't cover /v10.
+func (v *Validator) isLenient(path string) bool {
+	for _, prefix := range v.lenient {
+		if path == prefix || strings.HasPrefix(path, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// fieldErrors flattens the errors of the request validation into field
+// errors. Body fields are named like the validator names them, e.g.
+// items[0].amount, parameters by their name. The errors are matched by type
+// rather than with errors.As, which would skip a RequestError for the
+// MultiError it wraps and lose the parameter name.
+func fieldErrors(err error, field string) []model.FieldError {
+	switch e := err.(type) {
+	case openapi3.MultiError:
+		var fields []model.FieldError
+		for _, err := range e {
+			fields = append(fields, fieldErrors(err, field)...)
+		}
+		return fields
+	case *openapi3filter.RequestError:
+		if e.Parameter != nil {
+			field = e.Parameter.Name
+		}
+		if e.Err == nil {
+			return []model.FieldError{{Field: field, Rule: "invalid", Message: e.Reason}}
+		}
+		return fieldErrors(e.Err, field)
+	case *openapi3.SchemaError:
+		return []model.FieldError{{
+			Field:   joinField(field, e.JSONPointer()),
+			Rule:    e.SchemaField,
+			Message: e.Reason,
+		}}
+	}
+
+	return []model.FieldError{{Field: field, Rule: "invalid", Message: err.Error()}}
+}
+
+func joinField(field string, pointer []string) string {
+	var b strings.Builder
+	b.WriteString(field)
+	for _, p := range pointer {
+		if strings.Trim(p, "0123456789") == "" {
+			b.WriteString("[" + p + "]")
+			continue
+		}
+		if b.Len() > 0 {
+			b.WriteByte('.')
+		}
+		b.WriteString(p)
+	}
+	return b.String()
+}
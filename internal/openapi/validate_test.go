@@ -0,0 +1,29 @@
+package openapi
+
+import "testing"
+
+func TestIsLenient(t *testing.T) {
+	v := NewValidator([]string{"/v1/", " /legacy", ""})
+
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"/v1", true},
+		{"/v1/transaction", true},
+		{"/v1/transaction/batch", true},
+		{"/legacy/report", true},
+		{"/admin/v1/audit", false},
+		{"/admin/v1", false},
+		{"/v10/transaction", false},
+		{"/v2/transaction", false},
+		{"/health", false},
+		{"/", false},
+	}
+
+	for _, tt := range tests {
+		if got := v.isLenient(tt.path); got != tt.want {
+			t.Errorf("isLenient(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package policy
+
+import (
+	"slices"
+
+	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/model"
+	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/proto/transaction"
+)
+
+const (
+	// ScopeTransactionRead reads the caller's own transactions.
+	ScopeTransactionRead = "transaction:read"
+	// ScopeTransactionWrite creates, cancels, refunds and disputes the
+	// caller's own transactions.
+	ScopeTransactionWrite = "transaction:write"
+	// ScopeTransactionStatus settles any user's transaction, it is meant for
+	// payment processor service accounts.
+	ScopeTransactionStatus = "transaction:status"
+	// ScopeTransactionPayout lets a batch TOPUP other users, e.g. a payroll.
+	// It is meant for employer service accounts and no role has it.
+	ScopeTransactionPayout = "transaction:payout"
+	// ScopeAdmin grants the admin API.
+	ScopeAdmin = "admin"
+)
+
+// roleScopes are the scopes a role has on top of the ones in the token. Tokens
+// issued before roles existed carry no role and act as users.
+var roleScopes = map[string][]string{
+	"":                         {ScopeTransactionRead, ScopeTransactionWrite},
+	model.RoleUser:             {ScopeTransactionRead, ScopeTransactionWrite},
+	model.RolePaymentProcessor: {ScopeTransactionStatus},
+	model.RoleAdmin:            {ScopeTransactionRead, ScopeTransactionWrite, ScopeAdmin},
+}
+
+// Routes maps every authenticated HTTP route, as "METHOD path" with the path
+// as registered, and every gRPC method to the scopes that allow it. Any one of
+// the scopes is enough, routes missing here are refused.
+var Routes = map[string][]string{
+	"POST /v1/transaction/":                                  {ScopeTransactionWrite},
+	"POST /v1/transaction/batch":                             {ScopeTransactionWrite},
+	"GET /v1/transaction/batch/:batch_id":                    {ScopeTransactionRead},
+	"PUT /v1/transaction/:reference":                         {ScopeTransactionStatus},
+	"POST /v1/transaction/:reference/cancel":                 {ScopeTransactionWrite},
+	"POST /v1/transaction/:reference/dispute":                {ScopeTransactionWrite},
+	"GET /v1/transaction/":                                   {ScopeTransactionRead},
+	"GET /v1/transaction/stream":                             {ScopeTransactionRead},
+	"GET /v1/transaction/stream/ws":                          {ScopeTransactionRead},
+	"GET /v1/transaction/rewards":                            {ScopeTransactionRead},
+	"GET /v1/transaction/notification-preferences":           {ScopeTransactionRead},
+	"PUT /v1/transaction/notification-preferences":           {ScopeTransactionWrite},
+	"GET /v1/transaction/:reference":                         {ScopeTransactionRead},
+	"POST /v1/transaction/refund":                            {ScopeTransactionWrite},
+	"GET /v1/dispute/":                                       {ScopeTransactionRead},
+	"GET /v1/dispute/:id":                                    {ScopeTransactionRead},
+	"POST /v1/dispute/:id/evidence":                          {ScopeTransactionWrite},
+	"POST /v1/webhook/":                                      {ScopeTransactionWrite},
+	"GET /v1/webhook/":                                       {ScopeTransactionRead},
+	"DELETE /v1/webhook/:id":                                 {ScopeTransactionWrite},
+	"POST /v1/webhook/:id/enable":                            {ScopeTransactionWrite},
+	"GET /v1/webhook/:id/deliveries":                         {ScopeTransactionRead},
+	"GET /v1/webhook/:id/deliveries/:delivery_id":            {ScopeTransactionRead},
+	"POST /v1/webhook/:id/deliveries/:delivery_id/redeliver": {ScopeTransactionWrite},
+	"GET /admin/v1/transaction":                              {ScopeAdmin},
+	"GET /admin/v1/transaction/:reference":                   {ScopeAdmin},
+	"PUT /admin/v1/transaction/:reference/status":            {ScopeAdmin},
+	"POST /admin/v1/transaction/:reference/refund":           {ScopeAdmin},
+	"POST /admin/v1/dispute/:id/resolve":                     {ScopeAdmin},
+	"GET /admin/v1/reconciliation":                           {ScopeAdmin},
+	"GET /admin/v1/reconciliation/:id":                       {ScopeAdmin},
+	"GET /admin/v1/audit":                                    {ScopeAdmin},
+
+	transaction.TransactionService_CreateTransaction_FullMethodName:       {ScopeTransactionWrite},
+	transaction.TransactionService_UpdateTransactionStatus_FullMethodName: {ScopeTransactionStatus},
+	transaction.TransactionService_GetTransaction_FullMethodName:          {ScopeTransactionRead},
+	transaction.TransactionService_GetTransactions_FullMethodName:         {ScopeTransactionRead},
+	transaction.TransactionService_RefundTransaction_FullMethodName:       {ScopeTransactionWrite},
+	transaction.TransactionService_CancelTransaction_FullMethodName:       {ScopeTransactionWrite},
+	transaction.TransactionService_ListTransactions_FullMethodName:        {ScopeTransactionRead},
+	transaction.TransactionService_WatchTransactions_FullMethodName:       {ScopeTransactionRead},
+}
+
+// Scopes returns the scopes of an identity, its own and its role's.
+func Scopes(identity model.TokenResponse) []string {
+	scopes := slices.Clone(identity.Scopes)
+	for _, scope := range roleScopes[identity.Role] {
+		if !slices.Contains(scopes, scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+	return scopes
+}
+
+func HasScope(identity model.TokenResponse, scope string) bool {
+	return slices.Contains(Scopes(identity), scope)
+}
+
+// Allowed reports whether the identity may call route, a key of Routes.
+func Allowed(identity model.TokenResponse, route string) bool {
+	required, ok := Routes[route]
+	if !ok {
+		return false
+	}
+
+	scopes := Scopes(identity)
+	for _, scope := range required {
+		if slices.Contains(scopes, scope) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,317 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+
+	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/event"
+	eventv1 "github.com/ArdiSasongko/EwalletProjects-transaction/internal/event/proto/v1"
+	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/model"
+	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/storage/sqlc"
+	"github.com/jackc/pgx/v5/pgtype"
+	"google.golang.org/protobuf/proto"
+)
+
+// AdminService lets support staff act on any user's transactions. Every call
+// is written to the audit log whether it succeeds or not.
+type AdminService struct {
+	q              *sqlc.Queries
+	transaction    *TransactionService
+	dispute        *DisputeService
+	reconciliation *ReconciliationService
+	walletToken    string
+}
+
+func optionalText(s string) pgtype.Text {
+	return pgtype.Text{String: s, Valid: s != ""}
+}
+
+// audit records an admin action. A failed write is only logged, the action
+// itself has already happened by then and must not be reported as failed.
+func (s *AdminService) audit(ctx context.Context, admin model.TokenResponse, action, reference, reason string, detail interface{}, actionErr error) {
+	params := sqlc.CreateAdminAuditLogParams{
+		AdminID:    admin.UserID,
+		AdminEmail: admin.Email,
+		Action:     action,
+		Reference:  optionalText(reference),
+		Reason:     optionalText(reason),
+		Succeeded:  actionErr == nil,
+	}
+
+	if detail != nil {
+		b, err := json.Marshal(detail)
+		if err == nil {
+			params.Detail = optionalText(string(b))
+		}
+	}
+
+	if actionErr != nil {
+		params.Error = optionalText(actionErr.Error())
+	}
+
+	// keep writing the log when the request was cancelled mid action
+	if err := s.q.CreateAdminAuditLog(context.WithoutCancel(ctx), params); err != nil {
+		log.Printf("failed to write admin audit log, action: %s, admin: %d, err: %v", action, admin.UserID, err)
+	}
+}
+
+func adminTransactionResponse(tsx sqlc.Transaction) model.AdminTransactionResponse {
+	amount, _ := tsx.Amount.Float64Value()
+	return model.AdminTransactionResponse{
+		ID:                tsx.ID,
+		UserID:            tsx.UserID,
+		Reference:         tsx.Reference,
+		Amount:            amount.Float64,
+		TransactionType:   string(tsx.TransactionType),
+		TransactionStatus: string(tsx.TransactionStatus),
+		Description:       tsx.Description.String,
+		AdditionalInfo:    tsx.AdditionalInfo.String,
+		StatusReason:      tsx.StatusReason.String,
+		CreatedAt:         tsx.CreatedAt.Time,
+		UpdatedAt:         tsx.UpdatedAt.Time,
+	}
+}
+
+func adminAuditLogResponse(l sqlc.AdminAuditLog) model.AdminAuditLogResponse {
+	return model.AdminAuditLogResponse{
+		ID:         l.ID,
+		AdminID:    l.AdminID,
+		AdminEmail: l.AdminEmail,
+		Action:     l.Action,
+		Reference:  l.Reference.String,
+		Reason:     l.Reason.String,
+		Detail:     l.Detail.String,
+		Succeeded:  l.Succeeded,
+		Error:      l.Error.String,
+		CreatedAt:  l.CreatedAt.Time,
+	}
+}
+
+func adminTransactionEvent(e sqlc.EventOutbox) (model.AdminTransactionEvent, error) {
+	envelope := &eventv1.Envelope{}
+	if err := proto.Unmarshal(e.Payload, envelope); err != nil {
+		return model.AdminTransactionEvent{}, fmt.Errorf("failed to unmarshal event %d :%w", e.ID, err)
+	}
+
+	resp := model.AdminTransactionEvent{
+		ID:         e.ID,
+		Type:       e.EventType,
+		OccurredAt: envelope.OccurredAt.AsTime(),
+	}
+	if e.PublishedAt.Valid {
+		resp.PublishedAt = &e.PublishedAt.Time
+	}
+
+	switch e.EventType {
+	case event.TypeTransactionCreated:
+		resp.Status = envelope.GetTransactionCreated().GetStatus()
+	case event.TypeStatusChanged:
+		resp.PreviousStatus = envelope.GetStatusChanged().GetPreviousStatus()
+		resp.Status = envelope.GetStatusChanged().GetStatus()
+	case event.TypeTransactionRefunded:
+		resp.RefundReference = envelope.GetRefunded().GetReference()
+	}
+
+	return resp, nil
+}
+
+func (s *AdminService) SearchTransactions(ctx context.Context, payload *model.AdminSearchTransactions) (resp []model.AdminTransactionResponse, err error) {
+	defer func() {
+		s.audit(ctx, payload.Admin, model.AdminActionSearchTransactions, payload.Reference, "", payload, err)
+	}()
+
+	pageSize := payload.Limit
+	pageNumber := payload.Offset
+
+	transactions, err := s.q.SearchTransactions(ctx, sqlc.SearchTransactionsParams{
+		UserID:            payload.UserID,
+		Reference:         payload.Reference,
+		TransactionType:   payload.TransactionType,
+		TransactionStatus: payload.TransactionStatus,
+		CreatedFrom:       pgtype.Timestamp{Time: payload.From, Valid: !payload.From.IsZero()},
+		CreatedTo:         pgtype.Timestamp{Time: payload.To, Valid: !payload.To.IsZero()},
+		Limit:             pageSize,
+		Offset:            (pageNumber - 1) * pageSize,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to search transactions :%w", err)
+	}
+
+	resp = make([]model.AdminTransactionResponse, 0, len(transactions))
+	for _, tsx := range transactions {
+		resp = append(resp, adminTransactionResponse(tsx))
+	}
+
+	return resp, nil
+}
+
+// GetTransaction returns a transaction with its event history and the admin
+// actions taken on it.
+func (s *AdminService) GetTransaction(ctx context.Context, payload *model.AdminGetTransaction) (resp *model.AdminTransactionDetail, err error) {
+	defer func() {
+		s.audit(ctx, payload.Admin, model.AdminActionViewTransaction, payload.Reference, "", nil, err)
+	}()
+
+	tsx, err := s.q.GetTransactionByReference(ctx, payload.Reference)
+	if err != nil {
+		return nil, notFound(err, ErrTransactionNotFound, "transaction")
+	}
+
+	events, err := s.q.GetEventsByReference(ctx, tsx.Reference)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transaction history :%w", err)
+	}
+
+	logs, err := s.q.GetAdminAuditLogs(ctx, sqlc.GetAdminAuditLogsParams{
+		Reference: tsx.Reference,
+		Limit:     100,
+		Offset:    0,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transaction audit log :%w", err)
+	}
+
+	resp = &model.AdminTransactionDetail{
+		Transaction: adminTransactionResponse(tsx),
+		History:     make([]model.AdminTransactionEvent, 0, len(events)),
+		AuditLog:    make([]model.AdminAuditLogResponse, 0, len(logs)),
+	}
+
+	for _, e := range events {
+		history, err := adminTransactionEvent(e)
+		if err != nil {
+			return nil, err
+		}
+		resp.History = append(resp.History, history)
+	}
+
+	for _, l := range logs {
+		resp.AuditLog = append(resp.AuditLog, adminAuditLogResponse(l))
+	}
+
+	return resp, nil
+}
+
+// ForceStatus moves a transaction to a new status on behalf of its owner. The
+// usual status flow and wallet operations apply, the admin is kept in the
+// transaction's additional info and the reason as its status reason.
+func (s *AdminService) ForceStatus(ctx context.Context, payload *model.AdminStatusPayload) (resp model.TransactionResponse, err error) {
+	defer func() {
+		s.audit(ctx, payload.Admin, model.AdminActionForceStatus, payload.Reference, payload.Reason, map[string]string{
+			"transaction_status": payload.TransactionStatus,
+		}, err)
+	}()
+
+	additionalInfo := map[string]interface{}{}
+	if payload.AdditionalInfo != "" {
+		if err := json.Unmarshal([]byte(payload.AdditionalInfo), &additionalInfo); err != nil {
+			return model.TransactionResponse{}, ErrInvalidAdditionalInfo
+		}
+	}
+	additionalInfo["forced_by"] = strconv.Itoa(int(payload.Admin.UserID))
+
+	b, err := json.Marshal(additionalInfo)
+	if err != nil {
+		return model.TransactionResponse{}, fmt.Errorf("failed to marshal additional info :%w", err)
+	}
+
+	return s.transaction.UpdateTransaction(ctx, &model.TransactionUpdatePayload{
+		Reference:         payload.Reference,
+		TransactionStatus: payload.TransactionStatus,
+		AdditionalInfo:    string(b),
+		Reason:            payload.Reason,
+	})
+}
+
+// Refund refunds a SUCCESS purchase on behalf of its owner.
+func (s *AdminService) Refund(ctx context.Context, payload *model.AdminRefundPayload) (resp *model.RefundResponse, err error) {
+	defer func() {
+		s.audit(ctx, payload.Admin, model.AdminActionRefund, payload.Reference, payload.Reason, nil, err)
+	}()
+
+	description := payload.Description
+	if description == "" {
+		description = fmt.Sprintf("refund by support: %s", payload.Reason)
+	}
+
+	return s.transaction.CreateRefund(ctx, &model.TransactionRefundPayload{
+		Reference:      payload.Reference,
+		Description:    description,
+		AdditionalInfo: payload.AdditionalInfo,
+	})
+}
+
+func (s *AdminService) ResolveDispute(ctx context.Context, payload *model.AdminResolveDisputePayload) (resp *model.DisputeResponse, err error) {
+	reference := ""
+	defer func() {
+		s.audit(ctx, payload.Admin, model.AdminActionResolveDispute, reference, payload.Resolution, map[string]interface{}{
+			"dispute_id": payload.DisputeID,
+			"outcome":    payload.Outcome,
+		}, err)
+	}()
+
+	resp, err = s.dispute.Resolve(ctx, &model.DisputeResolvePayload{
+		DisputeID:  payload.DisputeID,
+		Outcome:    payload.Outcome,
+		Resolution: payload.Resolution,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	reference = resp.TransactionReference
+	return resp, nil
+}
+
+func (s *AdminService) GetReconciliationReport(ctx context.Context, payload *model.AdminGetReconciliationReport) (resp *model.ReconciliationReport, err error) {
+	defer func() {
+		s.audit(ctx, payload.Admin, model.AdminActionViewReconciliation, "", "", map[string]int32{
+			"report_id": payload.ReportID,
+		}, err)
+	}()
+
+	return s.reconciliation.GetReport(ctx, payload.ReportID)
+}
+
+func (s *AdminService) GetReconciliationReports(ctx context.Context, payload *model.AdminGetReconciliationReports) (resp []model.ReconciliationReport, err error) {
+	defer func() {
+		s.audit(ctx, payload.Admin, model.AdminActionViewReconciliation, "", "", nil, err)
+	}()
+
+	return s.reconciliation.GetReports(ctx, &model.GetReconciliationReports{
+		Limit:  payload.Limit,
+		Offset: payload.Offset,
+	})
+}
+
+func (s *AdminService) GetAuditLogs(ctx context.Context, payload *model.GetAdminAuditLogs) (_ []model.AdminAuditLogResponse, err error) {
+	// reading the audit log is audited like any other admin read
+	defer func() {
+		s.audit(ctx, payload.Admin, model.AdminActionViewAuditLog, payload.Reference, "", map[string]int32{
+			"admin_id": payload.AdminID,
+		}, err)
+	}()
+
+	pageSize := payload.Limit
+	pageNumber := payload.Offset
+
+	logs, err := s.q.GetAdminAuditLogs(ctx, sqlc.GetAdminAuditLogsParams{
+		Reference: payload.Reference,
+		AdminID:   payload.AdminID,
+		Limit:     pageSize,
+		Offset:    (pageNumber - 1) * pageSize,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resp := make([]model.AdminAuditLogResponse, 0, len(logs))
+	for _, l := range logs {
+		resp = append(resp, adminAuditLogResponse(l))
+	}
+
+	return resp, nil
+}
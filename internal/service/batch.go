@@ -0,0 +1,217 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/model"
+	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/storage/sqlc"
+	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/tracing"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func generateBatchID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate batch id :%w", err)
+	}
+	return "BATCH" + strings.ToUpper(hex.EncodeToString(b)), nil
+}
+
+// CreateBatch validates every item on its own and inserts the valid ones and
+// their events with a COPY each. Invalid items are reported back as REJECTED,
+// unless the batch is all-or-nothing in which case one invalid item rejects
+// the whole batch. A payout batch, e.g. a payroll, can TOPUP other users.
+func (s *TransactionService) CreateBatch(ctx context.Context, payload *model.TransactionBatchPayload) (_ *model.TransactionBatchResponse, err error) {
+	ctx, span := tracing.Start(ctx, "TransactionService.CreateBatch")
+	defer func() { tracing.End(span, err) }()
+
+	if len(payload.Items) > s.batchMaxItems {
+		return nil, ErrBatchTooLarge.Messagef("batch exceeds maximum of %d items", s.batchMaxItems)
+	}
+
+	batchID, err := generateBatchID()
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]model.TransactionBatchItemResult, len(payload.Items))
+	transactions := make([]sqlc.CreateTransactionsParams, 0, len(payload.Items))
+
+	for i := range payload.Items {
+		item := &payload.Items[i]
+		if item.UserID == 0 {
+			item.UserID = payload.UserID
+		}
+		results[i].Index = int32(i)
+
+		if item.UserID != payload.UserID && (!payload.Payout || item.TransactionType != string(sqlc.TransactionTypeTOPUP)) {
+			results[i].Status = string(sqlc.BatchItemStatusREJECTED)
+			results[i].Error = ErrBatchOtherUser.Error()
+			continue
+		}
+
+		if err := item.Validate(); err != nil {
+			results[i].Status = string(sqlc.BatchItemStatusREJECTED)
+			results[i].Error = err.Error()
+			continue
+		}
+
+		if err := checkTransactionPayload(&item.TransactionPayload); err != nil {
+			results[i].Status = string(sqlc.BatchItemStatusREJECTED)
+			results[i].Error = err.Error()
+			continue
+		}
+
+		amountNumeric, err := toNumeric(item.Amount)
+		if err != nil {
+			results[i].Status = string(sqlc.BatchItemStatusREJECTED)
+			results[i].Error = err.Error()
+			continue
+		}
+
+		reference, err := generateReference(item.TransactionType, item.UserID)
+		if err != nil {
+			return nil, err
+		}
+		transactions = append(transactions, sqlc.CreateTransactionsParams{
+			UserID:            item.UserID,
+			Amount:            amountNumeric,
+			TransactionType:   sqlc.TransactionType(item.TransactionType),
+			TransactionStatus: StatusPending,
+			Reference:         reference,
+			Description: pgtype.Text{
+				String: item.Description,
+				Valid:  true,
+			},
+			AdditionalInfo: pgtype.Text{
+				String: item.AdditionalInfo,
+				Valid:  true,
+			},
+		})
+
+		results[i].Reference = reference
+		results[i].Status = string(sqlc.BatchItemStatusCREATED)
+	}
+
+	rejected := len(payload.Items) - len(transactions)
+	if payload.AllOrNothing && rejected > 0 {
+		for i := range results {
+			if results[i].Status == string(sqlc.BatchItemStatusCREATED) {
+				results[i].Reference = ""
+				results[i].Status = string(sqlc.BatchItemStatusREJECTED)
+				results[i].Error = "batch rejected, another item is invalid"
+			}
+		}
+		transactions = nil
+		rejected = len(payload.Items)
+	}
+
+	batchStatus := sqlc.BatchStatusCOMPLETED
+	switch {
+	case len(transactions) == 0:
+		batchStatus = sqlc.BatchStatusFAILED
+	case rejected > 0:
+		batchStatus = sqlc.BatchStatusPARTIAL
+	}
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed start database tx : %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.q.WithTx(tx)
+	ctx, events := withEventMetrics(ctx)
+
+	batch, err := qtx.CreateTransactionBatch(ctx, sqlc.CreateTransactionBatchParams{
+		BatchID:       batchID,
+		UserID:        payload.UserID,
+		AllOrNothing:  payload.AllOrNothing,
+		TotalItems:    int32(len(payload.Items)),
+		CreatedItems:  int32(len(transactions)),
+		RejectedItems: int32(rejected),
+		BatchStatus:   batchStatus,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create batch :%w", err)
+	}
+
+	if len(transactions) > 0 {
+		if _, err := qtx.CreateTransactions(ctx, transactions); err != nil {
+			return nil, fmt.Errorf("failed to copy batch transactions :%w", err)
+		}
+
+		if err := recordCreatedBatch(ctx, qtx, transactions); err != nil {
+			return nil, err
+		}
+	}
+
+	items := make([]sqlc.CreateTransactionBatchItemsParams, 0, len(results))
+	for _, result := range results {
+		items = append(items, sqlc.CreateTransactionBatchItemsParams{
+			BatchID:    batchID,
+			ItemIndex:  result.Index,
+			Reference:  pgtype.Text{String: result.Reference, Valid: result.Reference != ""},
+			ItemStatus: sqlc.BatchItemStatus(result.Status),
+			Error:      pgtype.Text{String: result.Error, Valid: result.Error != ""},
+		})
+	}
+
+	if _, err := qtx.CreateTransactionBatchItems(ctx, items); err != nil {
+		return nil, fmt.Errorf("failed to copy batch items :%w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	events.observe()
+
+	return batchResponse(batch, results), nil
+}
+
+func (s *TransactionService) GetBatch(ctx context.Context, payload *model.GetTransactionBatch) (_ *model.TransactionBatchResponse, err error) {
+	ctx, span := tracing.Start(ctx, "TransactionService.GetBatch")
+	defer func() { tracing.End(span, err) }()
+
+	batch, err := s.q.GetTransactionBatchByBatchIdAndUserId(ctx, sqlc.GetTransactionBatchByBatchIdAndUserIdParams{
+		BatchID: payload.BatchID,
+		UserID:  payload.UserID,
+	})
+	if err != nil {
+		return nil, notFound(err, ErrBatchNotFound, "transaction batch")
+	}
+
+	items, err := s.q.GetTransactionBatchItems(ctx, batch.BatchID)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]model.TransactionBatchItemResult, 0, len(items))
+	for _, item := range items {
+		results = append(results, model.TransactionBatchItemResult{
+			Index:     item.ItemIndex,
+			Reference: item.Reference.String,
+			Status:    string(item.ItemStatus),
+			Error:     item.Error.String,
+		})
+	}
+
+	return batchResponse(batch, results), nil
+}
+
+func batchResponse(batch sqlc.TransactionBatch, results []model.TransactionBatchItemResult) *model.TransactionBatchResponse {
+	return &model.TransactionBatchResponse{
+		BatchID:       batch.BatchID,
+		Status:        string(batch.BatchStatus),
+		AllOrNothing:  batch.AllOrNothing,
+		TotalItems:    batch.TotalItems,
+		CreatedItems:  batch.CreatedItems,
+		RejectedItems: batch.RejectedItems,
+		CreatedAt:     batch.CreatedAt.Time,
+		Items:         results,
+	}
+}
@@ -0,0 +1,558 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/external"
+	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/model"
+	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/notification"
+	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/storage/sqlc"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	EvidenceAuthorUser    = "USER"
+	EvidenceAuthorSupport = "SUPPORT"
+)
+
+// disputeBatchSize caps how many disputes are moved per worker run.
+const disputeBatchSize = 50
+
+type DisputeService struct {
+	db           *pgxpool.Pool
+	q            *sqlc.Queries
+	external     external.External
+	reward       *RewardService
+	notifier     *notifier
+	reviewPeriod time.Duration
+	walletToken  string
+}
+
+func disputeResponse(d sqlc.Dispute, evidence []sqlc.DisputeEvidence) model.DisputeResponse {
+	amount, _ := d.Amount.Float64Value()
+	resp := model.DisputeResponse{
+		ID:                   d.ID,
+		TransactionReference: d.TransactionReference,
+		Amount:               amount.Float64,
+		Reason:               d.Reason,
+		Status:               string(d.DisputeStatus),
+		Resolution:           d.Resolution.String,
+		DeadlineAt:           d.DeadlineAt.Time,
+		CreatedAt:            d.CreatedAt.Time,
+	}
+	// the credit reference is reserved when the dispute opens, it only names
+	// a transaction once the credit was made
+	if d.DisputeStatus != sqlc.DisputeStatusOPENED {
+		resp.CreditReference = d.CreditReference.String
+	}
+	if d.ResolvedAt.Valid {
+		resp.ResolvedAt = &d.ResolvedAt.Time
+	}
+
+	for _, e := range evidence {
+		resp.Evidence = append(resp.Evidence, evidenceResponse(e))
+	}
+
+	return resp
+}
+
+func evidenceResponse(e sqlc.DisputeEvidence) model.DisputeEvidenceResponse {
+	return model.DisputeEvidenceResponse{
+		ID:             e.ID,
+		Author:         e.Author,
+		Message:        e.Message,
+		AttachmentName: e.AttachmentName.String,
+		AttachmentURL:  e.AttachmentUrl.String,
+		CreatedAt:      e.CreatedAt.Time,
+	}
+}
+
+func hasActiveDispute(ctx context.Context, qtx *sqlc.Queries, reference string) (bool, error) {
+	count, err := qtx.CountActiveDisputesByTransactionReference(ctx, reference)
+	if err != nil {
+		return false, fmt.Errorf("failed to check active dispute :%w", err)
+	}
+	return count > 0, nil
+}
+
+func (s *DisputeService) Open(ctx context.Context, payload *model.DisputePayload) (*model.DisputeResponse, error) {
+	tsx, err := s.q.GetTransactionByReferenceAndUserId(ctx, sqlc.GetTransactionByReferenceAndUserIdParams{
+		Reference: payload.Reference,
+		UserID:    payload.UserID,
+	})
+	if err != nil {
+		return nil, notFound(err, ErrTransactionNotFound, "transaction")
+	}
+
+	if tsx.TransactionType != sqlc.TransactionTypePURCHASE || tsx.TransactionStatus != StatusSuccess {
+		return nil, ErrNotDisputable
+	}
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed start database tx : %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.q.WithTx(tx)
+
+	// lock the purchase so a refund can't be made while the dispute opens
+	tsx, err = qtx.GetTransactionByReferenceForUpdate(ctx, tsx.Reference)
+	if err != nil {
+		return nil, notFound(err, ErrTransactionNotFound, "transaction")
+	}
+	if tsx.TransactionStatus != StatusSuccess {
+		return nil, ErrNotDisputable
+	}
+
+	refunded, err := hasRefund(ctx, qtx, tsx.Reference)
+	if err != nil {
+		return nil, err
+	}
+	if refunded {
+		return nil, ErrAlreadyRefunded
+	}
+
+	active, err := hasActiveDispute(ctx, qtx, tsx.Reference)
+	if err != nil {
+		return nil, err
+	}
+	if active {
+		return nil, ErrActiveDispute
+	}
+
+	// the provisional credit's reference is committed with the dispute, before
+	// the wallet is ever called, so a credit retried after a failed commit
+	// reuses it and the wallet sees the same operation
+	creditRef, err := generateReference(string(sqlc.TransactionTypeDISPUTECREDIT), tsx.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	dispute, err := qtx.CreateDispute(ctx, sqlc.CreateDisputeParams{
+		TransactionReference: tsx.Reference,
+		UserID:               tsx.UserID,
+		Amount:               tsx.Amount,
+		Reason:               payload.Reason,
+		DisputeStatus:        sqlc.DisputeStatusOPENED,
+		CreditReference:      pgtype.Text{String: creditRef, Valid: true},
+		DeadlineAt: pgtype.Timestamp{
+			Time:  time.Now().Add(s.reviewPeriod),
+			Valid: true,
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create dispute :%w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	resp := disputeResponse(dispute, nil)
+	return &resp, nil
+}
+
+func (s *DisputeService) AddEvidence(ctx context.Context, payload *model.DisputeEvidencePayload) (*model.DisputeEvidenceResponse, error) {
+	dispute, err := s.q.GetDisputeByIdAndUserId(ctx, sqlc.GetDisputeByIdAndUserIdParams{
+		ID:     payload.DisputeID,
+		UserID: payload.UserID,
+	})
+	if err != nil {
+		return nil, notFound(err, ErrDisputeNotFound, "dispute")
+	}
+
+	if dispute.DisputeStatus == sqlc.DisputeStatusWON || dispute.DisputeStatus == sqlc.DisputeStatusLOST {
+		return nil, ErrDisputeResolved
+	}
+
+	evidence, err := s.q.CreateDisputeEvidence(ctx, sqlc.CreateDisputeEvidenceParams{
+		DisputeID: dispute.ID,
+		Author:    EvidenceAuthorUser,
+		Message:   payload.Message,
+		AttachmentName: pgtype.Text{
+			String: payload.AttachmentName,
+			Valid:  payload.AttachmentName != "",
+		},
+		AttachmentUrl: pgtype.Text{
+			String: payload.AttachmentURL,
+			Valid:  payload.AttachmentURL != "",
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create dispute evidence :%w", err)
+	}
+
+	resp := evidenceResponse(evidence)
+	return &resp, nil
+}
+
+func (s *DisputeService) GetDispute(ctx context.Context, payload *model.GetDispute) (*model.DisputeResponse, error) {
+	dispute, err := s.q.GetDisputeByIdAndUserId(ctx, sqlc.GetDisputeByIdAndUserIdParams{
+		ID:     payload.DisputeID,
+		UserID: payload.UserID,
+	})
+	if err != nil {
+		return nil, notFound(err, ErrDisputeNotFound, "dispute")
+	}
+
+	evidence, err := s.q.GetDisputeEvidence(ctx, dispute.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := disputeResponse(dispute, evidence)
+	return &resp, nil
+}
+
+func (s *DisputeService) GetDisputes(ctx context.Context, payload *model.GetDisputes) ([]model.DisputeResponse, error) {
+	pageSize := payload.Limit
+	pageNumber := payload.Offset
+
+	disputes, err := s.q.GetDisputesByUserId(ctx, sqlc.GetDisputesByUserIdParams{
+		UserID: payload.UserID,
+		Limit:  pageSize,
+		Offset: (pageNumber - 1) * pageSize,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resp := make([]model.DisputeResponse, 0, len(disputes))
+	for _, dispute := range disputes {
+		resp = append(resp, disputeResponse(dispute, nil))
+	}
+
+	return resp, nil
+}
+
+func (s *DisputeService) Resolve(ctx context.Context, payload *model.DisputeResolvePayload) (*model.DisputeResponse, error) {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed start database tx : %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.q.WithTx(tx)
+	ctx, events := withEventMetrics(ctx)
+
+	dispute, err := qtx.GetDisputeByIdForUpdate(ctx, payload.DisputeID)
+	if err != nil {
+		return nil, notFound(err, ErrDisputeNotFound, "dispute")
+	}
+
+	dispute, err = s.resolve(ctx, qtx, dispute, sqlc.DisputeStatus(payload.Outcome), payload.Resolution)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	events.observe()
+
+	resp := disputeResponse(dispute, nil)
+	return &resp, nil
+}
+
+// provisionalCredit gives the disputed amount back to the user while the
+// dispute is under review, recorded as a DISPUTE_CREDIT transaction under the
+// credit reference reserved when the dispute opened.
+func (s *DisputeService) provisionalCredit(ctx context.Context, qtx *sqlc.Queries, d sqlc.Dispute) error {
+	ref := d.CreditReference.String
+
+	credit := sqlc.CreateTransactionParams{
+		UserID:            d.UserID,
+		Amount:            d.Amount,
+		TransactionType:   sqlc.TransactionTypeDISPUTECREDIT,
+		TransactionStatus: sqlc.TransactionStatusSUCCESS,
+		Reference:         ref,
+		Description: pgtype.Text{
+			String: fmt.Sprintf("provisional credit for dispute %d on %s", d.ID, d.TransactionReference),
+			Valid:  true,
+		},
+	}
+	if _, err := qtx.CreateTransaction(ctx, credit); err != nil {
+		return fmt.Errorf("failed to create provisional credit transaction :%w", err)
+	}
+
+	if err := recordCreated(ctx, qtx, credit); err != nil {
+		return err
+	}
+
+	amount, _ := d.Amount.Float64Value()
+	if _, err := s.external.Wallet.Credit(ctx, external.WalletRequest{
+		UserID:    d.UserID,
+		Amount:    amount.Float64,
+		Reference: ref,
+		Status:    StatusSuccess,
+	}, s.walletToken); err != nil {
+		return fmt.Errorf("credit wallet error :%w", err)
+	}
+
+	return nil
+}
+
+// resolve settles a dispute. A WON dispute keeps the provisional credit as the
+// refund of the purchase, which is marked REVERSED and linked to the credit. A
+// LOST dispute takes the provisional credit back from the wallet.
+func (s *DisputeService) resolve(ctx context.Context, qtx *sqlc.Queries, d sqlc.Dispute, outcome sqlc.DisputeStatus, resolution string) (sqlc.Dispute, error) {
+	if d.DisputeStatus != sqlc.DisputeStatusOPENED && d.DisputeStatus != sqlc.DisputeStatusUNDERREVIEW {
+		return sqlc.Dispute{}, ErrDisputeResolved
+	}
+
+	creditRef := d.CreditReference
+
+	switch outcome {
+	case sqlc.DisputeStatusWON:
+		// an OPENED dispute was not credited yet
+		if d.DisputeStatus == sqlc.DisputeStatusOPENED {
+			if err := s.provisionalCredit(ctx, qtx, d); err != nil {
+				return sqlc.Dispute{}, err
+			}
+		}
+
+		purchase, err := qtx.GetTransactionByReferenceForUpdate(ctx, d.TransactionReference)
+		if err != nil {
+			return sqlc.Dispute{}, err
+		}
+
+		if !canTransition(string(purchase.TransactionStatus), StatusReversed) {
+			return sqlc.Dispute{}, fmt.Errorf("transaction status flow invalid, current status - %s", purchase.TransactionStatus)
+		}
+
+		if _, err := qtx.UpdateTransactionStatusByReference(ctx, sqlc.UpdateTransactionStatusByReferenceParams{
+			Reference:         purchase.Reference,
+			TransactionStatus: sqlc.TransactionStatusREVERSED,
+			AdditionalInfo:    purchase.AdditionalInfo,
+		}); err != nil {
+			return sqlc.Dispute{}, err
+		}
+
+		if err := recordStatusChange(ctx, qtx, purchase, sqlc.TransactionStatusREVERSED); err != nil {
+			return sqlc.Dispute{}, err
+		}
+
+		if _, err := qtx.CreateTransactionReversal(ctx, sqlc.CreateTransactionReversalParams{
+			TransactionReference: purchase.Reference,
+			ReversalReference:    creditRef.String,
+			Amount:               d.Amount,
+		}); err != nil {
+			return sqlc.Dispute{}, fmt.Errorf("failed to link reversal transaction :%w", err)
+		}
+
+		if err := s.reward.clawback(ctx, qtx, purchase.Reference); err != nil {
+			return sqlc.Dispute{}, err
+		}
+
+		if err := s.notifier.transitioned(ctx, qtx, purchase, sqlc.TransactionStatusREVERSED, map[string]string{
+			notification.PlaceholderReversalReference: creditRef.String,
+		}); err != nil {
+			return sqlc.Dispute{}, err
+		}
+
+	case sqlc.DisputeStatusLOST:
+		// nothing was credited for an OPENED dispute, drop the reference
+		// reserved for it
+		if d.DisputeStatus == sqlc.DisputeStatusOPENED {
+			creditRef = pgtype.Text{}
+		}
+
+		if creditRef.Valid {
+			credit, err := qtx.GetTransactionByReferenceForUpdate(ctx, creditRef.String)
+			if err != nil {
+				return sqlc.Dispute{}, err
+			}
+
+			if _, err := qtx.UpdateTransactionStatusByReference(ctx, sqlc.UpdateTransactionStatusByReferenceParams{
+				Reference:         credit.Reference,
+				TransactionStatus: sqlc.TransactionStatusREVERSED,
+				AdditionalInfo:    credit.AdditionalInfo,
+			}); err != nil {
+				return sqlc.Dispute{}, err
+			}
+
+			if err := recordStatusChange(ctx, qtx, credit, sqlc.TransactionStatusREVERSED); err != nil {
+				return sqlc.Dispute{}, err
+			}
+
+			// the credit is taken back under a reversal of its own, the
+			// credit's reference was already used to pay it out
+			reversalRef := compensationReference(string(sqlc.TransactionTypeREVERSAL), credit.Reference)
+
+			reversal := sqlc.CreateTransactionParams{
+				UserID:            d.UserID,
+				Amount:            credit.Amount,
+				TransactionType:   sqlc.TransactionTypeREVERSAL,
+				TransactionStatus: sqlc.TransactionStatusSUCCESS,
+				Reference:         reversalRef,
+				Description: pgtype.Text{
+					String: fmt.Sprintf("reversal of %s", credit.Reference),
+					Valid:  true,
+				},
+			}
+			if _, err := qtx.CreateTransaction(ctx, reversal); err != nil {
+				return sqlc.Dispute{}, fmt.Errorf("failed to create reversal transaction :%w", err)
+			}
+
+			if err := recordCreated(ctx, qtx, reversal); err != nil {
+				return sqlc.Dispute{}, err
+			}
+
+			if _, err := qtx.CreateTransactionReversal(ctx, sqlc.CreateTransactionReversalParams{
+				TransactionReference: credit.Reference,
+				ReversalReference:    reversalRef,
+				Amount:               credit.Amount,
+			}); err != nil {
+				return sqlc.Dispute{}, fmt.Errorf("failed to link reversal transaction :%w", err)
+			}
+
+			if err := s.notifier.transitioned(ctx, qtx, credit, sqlc.TransactionStatusREVERSED, map[string]string{
+				notification.PlaceholderReversalReference: reversalRef,
+				notification.PlaceholderOriginalReference: d.TransactionReference,
+			}); err != nil {
+				return sqlc.Dispute{}, err
+			}
+
+			amount, _ := credit.Amount.Float64Value()
+			if _, err := s.external.Wallet.Debit(ctx, external.WalletRequest{
+				UserID:    d.UserID,
+				Amount:    amount.Float64,
+				Reference: reversalRef,
+				Status:    StatusReversed,
+			}, s.walletToken); err != nil {
+				return sqlc.Dispute{}, fmt.Errorf("debit wallet error :%w", err)
+			}
+		}
+
+	default:
+		return sqlc.Dispute{}, ErrInvalidDisputeOutcome
+	}
+
+	resolvedAt := pgtype.Timestamp{Time: time.Now(), Valid: true}
+	status, err := qtx.UpdateDisputeStatus(ctx, sqlc.UpdateDisputeStatusParams{
+		ID:              d.ID,
+		DisputeStatus:   outcome,
+		CreditReference: creditRef,
+		Resolution: pgtype.Text{
+			String: resolution,
+			Valid:  true,
+		},
+		ResolvedAt: resolvedAt,
+	})
+	if err != nil {
+		return sqlc.Dispute{}, fmt.Errorf("failed to update dispute :%w", err)
+	}
+
+	d.DisputeStatus = status
+	d.CreditReference = creditRef
+	d.Resolution = pgtype.Text{String: resolution, Valid: true}
+	d.ResolvedAt = resolvedAt
+	return d, nil
+}
+
+// ProcessDisputes moves OPENED disputes under review with a provisional credit
+// and settles disputes whose review deadline passed in the user's favour.
+func (s *DisputeService) ProcessDisputes(ctx context.Context) (int, error) {
+	processed := 0
+	for processed < disputeBatchSize {
+		ok, err := s.reviewNextDispute(ctx)
+		if err != nil {
+			return processed, err
+		}
+		if !ok {
+			break
+		}
+		processed++
+	}
+
+	for processed < disputeBatchSize*2 {
+		ok, err := s.resolveNextOverdueDispute(ctx)
+		if err != nil {
+			return processed, err
+		}
+		if !ok {
+			break
+		}
+		processed++
+	}
+
+	return processed, nil
+}
+
+func (s *DisputeService) reviewNextDispute(ctx context.Context) (bool, error) {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed start database tx : %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.q.WithTx(tx)
+	ctx, events := withEventMetrics(ctx)
+
+	disputes, err := qtx.GetOpenedDisputes(ctx, 1)
+	if err != nil {
+		return false, fmt.Errorf("failed to get opened disputes :%w", err)
+	}
+	if len(disputes) == 0 {
+		return false, nil
+	}
+	dispute := disputes[0]
+
+	if err := s.provisionalCredit(ctx, qtx, dispute); err != nil {
+		return false, err
+	}
+
+	if _, err := qtx.UpdateDisputeStatus(ctx, sqlc.UpdateDisputeStatusParams{
+		ID:              dispute.ID,
+		DisputeStatus:   sqlc.DisputeStatusUNDERREVIEW,
+		CreditReference: dispute.CreditReference,
+		Resolution:      dispute.Resolution,
+		ResolvedAt:      dispute.ResolvedAt,
+	}); err != nil {
+		return false, fmt.Errorf("failed to update dispute :%w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return false, err
+	}
+	events.observe()
+
+	return true, nil
+}
+
+func (s *DisputeService) resolveNextOverdueDispute(ctx context.Context) (bool, error) {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed start database tx : %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.q.WithTx(tx)
+	ctx, events := withEventMetrics(ctx)
+
+	disputes, err := qtx.GetOverdueDisputes(ctx, sqlc.GetOverdueDisputesParams{
+		DeadlineAt: pgtype.Timestamp{Time: time.Now(), Valid: true},
+		Limit:      1,
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to get overdue disputes :%w", err)
+	}
+	if len(disputes) == 0 {
+		return false, nil
+	}
+
+	if _, err := s.resolve(ctx, qtx, disputes[0], sqlc.DisputeStatusWON, "review deadline passed without a decision"); err != nil {
+		return false, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return false, err
+	}
+	events.observe()
+
+	return true, nil
+}
@@ -0,0 +1,54 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/apperror"
+	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/external"
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	ErrTransactionNotFound       = apperror.NotFound("transaction_not_found", "transaction not found")
+	ErrBatchNotFound             = apperror.NotFound("batch_not_found", "transaction batch not found")
+	ErrTransactionTypeNotAllowed = apperror.InvalidInput("transaction_type_not_allowed", "transaction type not allowed only 'TOPUP', 'PURCHASE', 'REFUND'")
+	ErrInvalidAdditionalInfo     = apperror.InvalidInput("invalid_additional_info", "additional info invalid format")
+	ErrInvalidTransition         = apperror.InvalidTransition("invalid_status_transition", "transaction status flow invalid")
+	ErrNotRefundable             = apperror.InvalidTransition("transaction_not_refundable", "only type 'PURCHASE' and status 'SUCCESS' can be refunded")
+	ErrAlreadyRefunded           = apperror.InvalidTransition("transaction_already_refunded", "transaction has already been refunded")
+	ErrActiveDispute             = apperror.Conflict("active_dispute", "transaction has an active dispute")
+	ErrBatchTooLarge             = apperror.LimitExceeded("batch_too_large", "batch exceeds maximum items")
+	ErrBatchOtherUser            = apperror.InvalidInput("batch_other_user", "only 'TOPUP' items of a payout batch can be made for another user")
+	ErrWalletUnavailable         = apperror.UpstreamUnavailable("wallet_unavailable", "wallet service unavailable, try again later")
+	ErrWalletRejected            = apperror.Conflict("wallet_rejected", "wallet rejected the operation")
+	ErrNotDisputable             = apperror.InvalidTransition("transaction_not_disputable", "only type 'PURCHASE' and status 'SUCCESS' can be disputed")
+	ErrDisputeNotFound           = apperror.NotFound("dispute_not_found", "dispute not found")
+	ErrDisputeResolved           = apperror.InvalidTransition("dispute_already_resolved", "dispute already resolved")
+	ErrInvalidDisputeOutcome     = apperror.InvalidInput("invalid_dispute_outcome", "dispute outcome must be 'WON' or 'LOST'")
+	ErrWebhookEndpointNotFound   = apperror.NotFound("webhook_endpoint_not_found", "webhook endpoint not found")
+	ErrWebhookDeliveryNotFound   = apperror.NotFound("webhook_delivery_not_found", "webhook delivery not found")
+	ErrWebhookEndpointDisabled   = apperror.Conflict("webhook_endpoint_disabled", "webhook endpoint is disabled, enable it before redelivering")
+	ErrReportNotFound            = apperror.NotFound("reconciliation_report_not_found", "reconciliation report not found")
+	ErrInvalidQuietHours         = apperror.InvalidInput("invalid_quiet_hours", "quiet hours invalid format")
+)
+
+// notFound turns a missing row into notFoundErr, any other error is wrapped
+// as an internal failure to get what.
+func notFound(err error, notFoundErr *apperror.Error, what string) error {
+	if errors.Is(err, pgx.ErrNoRows) {
+		return notFoundErr
+	}
+	return fmt.Errorf("failed to get %s :%w", what, err)
+}
+
+// walletError tells a wallet that could not be reached apart from one that
+// refused the operation.
+func walletError(err error) error {
+	switch external.WalletErrorKindOf(err) {
+	case external.WalletErrorRetryable, external.WalletErrorUnknownOutcome:
+		return ErrWalletUnavailable.Wrap(err)
+	default:
+		return ErrWalletRejected.Wrap(err)
+	}
+}
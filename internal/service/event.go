@@ -0,0 +1,285 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/event"
+	eventv1 "github.com/ArdiSasongko/EwalletProjects-transaction/internal/event/proto/v1"
+	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/metrics"
+	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/storage/sqlc"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// eventBatchSize caps how many outbox events are published per relay run.
+const eventBatchSize = 100
+
+type EventService struct {
+	db          *pgxpool.Pool
+	q           *sqlc.Queries
+	publisher   event.Publisher
+	maxAttempts int
+}
+
+// outboxEvent stamps the event and builds its outbox row.
+func outboxEvent(userID int32, envelope *eventv1.Envelope) (sqlc.CreateOutboxEventParams, error) {
+	envelope.OccurredAt = timestamppb.Now()
+
+	payload, err := proto.Marshal(envelope)
+	if err != nil {
+		return sqlc.CreateOutboxEventParams{}, fmt.Errorf("failed to marshal event :%w", err)
+	}
+
+	return sqlc.CreateOutboxEventParams{
+		Reference: envelope.Reference,
+		EventType: envelope.Type,
+		Payload:   payload,
+		UserID:    pgtype.Int4{Int32: userID, Valid: true},
+	}, nil
+}
+
+// recordEvent writes the event to the outbox on the caller's database tx, the
+// relay publishes it once the tx is committed. The event is counted in the
+// metrics when ctx comes from withEventMetrics.
+func recordEvent(ctx context.Context, qtx *sqlc.Queries, userID int32, envelope *eventv1.Envelope) error {
+	row, err := outboxEvent(userID, envelope)
+	if err != nil {
+		return err
+	}
+
+	if err := qtx.CreateOutboxEvent(ctx, row); err != nil {
+		return fmt.Errorf("failed to create outbox event :%w", err)
+	}
+
+	collectEvents(ctx, envelope)
+	return nil
+}
+
+func createdEvent(tsx sqlc.CreateTransactionParams) *eventv1.Envelope {
+	amount, _ := tsx.Amount.Float64Value()
+	return &eventv1.Envelope{
+		Type:      event.TypeTransactionCreated,
+		Reference: tsx.Reference,
+		Payload: &eventv1.Envelope_TransactionCreated{
+			TransactionCreated: &eventv1.TransactionCreated{
+				Reference:       tsx.Reference,
+				UserId:          tsx.UserID,
+				TransactionType: string(tsx.TransactionType),
+				Status:          string(tsx.TransactionStatus),
+				Amount:          amount.Float64,
+				Description:     tsx.Description.String,
+				AdditionalInfo:  tsx.AdditionalInfo.String,
+			},
+		},
+	}
+}
+
+func recordCreated(ctx context.Context, qtx *sqlc.Queries, tsx sqlc.CreateTransactionParams) error {
+	return recordEvent(ctx, qtx, tsx.UserID, createdEvent(tsx))
+}
+
+// recordCreatedBatch writes the created events of transactions copied in
+// together with a single COPY, like the transactions themselves.
+func recordCreatedBatch(ctx context.Context, qtx *sqlc.Queries, transactions []sqlc.CreateTransactionsParams) error {
+	rows := make([]sqlc.CreateOutboxEventsParams, 0, len(transactions))
+	envelopes := make([]*eventv1.Envelope, 0, len(transactions))
+	for _, tsx := range transactions {
+		envelope := createdEvent(sqlc.CreateTransactionParams(tsx))
+		row, err := outboxEvent(tsx.UserID, envelope)
+		if err != nil {
+			return err
+		}
+		rows = append(rows, sqlc.CreateOutboxEventsParams(row))
+		envelopes = append(envelopes, envelope)
+	}
+
+	if _, err := qtx.CreateOutboxEvents(ctx, rows); err != nil {
+		return fmt.Errorf("failed to copy outbox events :%w", err)
+	}
+
+	collectEvents(ctx, envelopes...)
+	return nil
+}
+
+// recordStatusChange publishes a status change to the event stream and to the
+// owner's webhook endpoints.
+func recordStatusChange(ctx context.Context, qtx *sqlc.Queries, tsx sqlc.Transaction, status sqlc.TransactionStatus) error {
+	if err := enqueueWebhook(ctx, qtx, tsx, status); err != nil {
+		return err
+	}
+
+	amount, _ := tsx.Amount.Float64Value()
+	return recordEvent(ctx, qtx, tsx.UserID, &eventv1.Envelope{
+		Type:      event.TypeStatusChanged,
+		Reference: tsx.Reference,
+		Payload: &eventv1.Envelope_StatusChanged{
+			StatusChanged: &eventv1.TransactionStatusChanged{
+				Reference:       tsx.Reference,
+				UserId:          tsx.UserID,
+				TransactionType: string(tsx.TransactionType),
+				PreviousStatus:  string(tsx.TransactionStatus),
+				Status:          string(status),
+				Amount:          amount.Float64,
+			},
+		},
+	})
+}
+
+// recordRefunded is keyed by the purchase so it is ordered with the purchase's
+// own events.
+func recordRefunded(ctx context.Context, qtx *sqlc.Queries, purchase sqlc.Transaction, refundReference string) error {
+	amount, _ := purchase.Amount.Float64Value()
+	return recordEvent(ctx, qtx, purchase.UserID, &eventv1.Envelope{
+		Type:      event.TypeTransactionRefunded,
+		Reference: purchase.Reference,
+		Payload: &eventv1.Envelope_Refunded{
+			Refunded: &eventv1.TransactionRefunded{
+				Reference:         refundReference,
+				OriginalReference: purchase.Reference,
+				UserId:            purchase.UserID,
+				Amount:            amount.Float64,
+			},
+		},
+	})
+}
+
+// PublishPending relays outbox events to the publisher in outbox id order.
+// When an event fails the later events of its reference are held back until
+// the next run, an event failing maxAttempts times is set aside as dead so it
+// doesn't hold its reference back forever. Delivery is at least once: an
+// event published right before a failed commit is published again under the
+// same id.
+//
+// Ids are taken at insert, not at commit, so only the events of one
+// reference are ordered: they are written under the lock of the transaction
+// row and commit in id order. Across references a lower id can commit after
+// a higher one is published, consumers can't use the sequence as a global
+// order.
+func (s *EventService) PublishPending(ctx context.Context) (int, error) {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed start database tx : %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.q.WithTx(tx)
+
+	locked, err := qtx.TryLockEventRelay(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to lock event relay :%w", err)
+	}
+	if !locked {
+		return 0, nil
+	}
+
+	events, err := qtx.GetUnpublishedEvents(ctx, eventBatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get unpublished events :%w", err)
+	}
+
+	published := 0
+	blocked := map[string]bool{}
+	for _, e := range events {
+		if blocked[e.Reference] {
+			continue
+		}
+
+		if err := s.publish(ctx, e); err != nil {
+			if int(e.Attempts)+1 >= s.maxAttempts {
+				log.Printf("event %d of %s is dead after %d attempts, err: %v", e.ID, e.Reference, e.Attempts+1, err)
+			} else {
+				blocked[e.Reference] = true
+			}
+
+			if err := qtx.MarkEventFailed(ctx, sqlc.MarkEventFailedParams{
+				ID:          e.ID,
+				LastError:   pgtype.Text{String: err.Error(), Valid: true},
+				MaxAttempts: int32(s.maxAttempts),
+			}); err != nil {
+				return published, fmt.Errorf("failed to mark event failed :%w", err)
+			}
+			continue
+		}
+
+		if err := qtx.MarkEventPublished(ctx, e.ID); err != nil {
+			return published, fmt.Errorf("failed to mark event published :%w", err)
+		}
+		published++
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return published, err
+	}
+
+	return published, nil
+}
+
+// eventMetricsKey carries the recordedEvents of a database tx in its ctx.
+type eventMetricsKey struct{}
+
+// recordedEvents are the events recorded on one database tx.
+type recordedEvents struct {
+	envelopes []*eventv1.Envelope
+}
+
+// withEventMetrics collects the events recorded with the returned ctx. The
+// caller observes them once its tx has committed, so a transaction whose tx
+// rolled back is never counted and a committed one is counted right away by
+// the instance that committed it, whether or not the relay is running.
+func withEventMetrics(ctx context.Context) (context.Context, *recordedEvents) {
+	events := &recordedEvents{}
+	return context.WithValue(ctx, eventMetricsKey{}, events), events
+}
+
+// collectEvents adds recorded events to the ones of ctx's tx, if any.
+func collectEvents(ctx context.Context, envelopes ...*eventv1.Envelope) {
+	if events, ok := ctx.Value(eventMetricsKey{}).(*recordedEvents); ok {
+		events.envelopes = append(events.envelopes, envelopes...)
+	}
+}
+
+// observe counts the collected transactions in the metrics.
+func (r *recordedEvents) observe() {
+	for _, envelope := range r.envelopes {
+		observeEvent(envelope)
+	}
+	r.envelopes = nil
+}
+
+func observeEvent(envelope *eventv1.Envelope) {
+	switch p := envelope.Payload.(type) {
+	case *eventv1.Envelope_TransactionCreated:
+		e := p.TransactionCreated
+		metrics.TransactionCreated(e.TransactionType, e.Status, e.Amount)
+	case *eventv1.Envelope_StatusChanged:
+		e := p.StatusChanged
+		metrics.TransactionTransitioned(e.TransactionType, e.Status, e.Amount)
+	}
+}
+
+func (s *EventService) publish(ctx context.Context, e sqlc.EventOutbox) error {
+	envelope := &eventv1.Envelope{}
+	if err := proto.Unmarshal(e.Payload, envelope); err != nil {
+		return fmt.Errorf("failed to unmarshal event :%w", err)
+	}
+
+	// the outbox id is only known once the row exists
+	envelope.Id = fmt.Sprintf("evt_%d", e.ID)
+	envelope.Sequence = e.ID
+
+	data, err := proto.Marshal(envelope)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event :%w", err)
+	}
+
+	return s.publisher.Publish(ctx, event.Message{
+		ID:      envelope.Id,
+		Subject: e.EventType,
+		Key:     e.Reference,
+		Data:    data,
+	})
+}
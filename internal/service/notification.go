@@ -0,0 +1,439 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/external"
+	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/metrics"
+	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/model"
+	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/notification"
+	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/storage/sqlc"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	// notificationBatchSize caps how many notifications are sent per worker
+	// run.
+	notificationBatchSize = 100
+	// notificationMaxBackoff caps the delay between two attempts of a
+	// notification.
+	notificationMaxBackoff = time.Hour
+	// notificationLease hides a claimed notification from the other workers
+	// while it is sent, it outlasts the client timeout. A worker stopped
+	// mid-send leaves it to be attempted again once the lease ends.
+	notificationLease = time.Minute
+)
+
+type NotificationService struct {
+	db          *pgxpool.Pool
+	q           *sqlc.Queries
+	external    external.External
+	workers     int
+	maxAttempts int
+	retryBase   time.Duration
+}
+
+// notifier queues the notifications of transaction transitions. They go to
+// the email the owner last used, so a change made by a payment processor or
+// support staff still reaches the owner.
+type notifier struct {
+	templates notification.Templates
+}
+
+// rememberContact keeps the owner's email for later notifications.
+func rememberContact(ctx context.Context, qtx *sqlc.Queries, userID int32, email string) error {
+	if email == "" {
+		return nil
+	}
+
+	if err := qtx.UpsertNotificationContact(ctx, sqlc.UpsertNotificationContactParams{
+		UserID: userID,
+		Email:  email,
+	}); err != nil {
+		return fmt.Errorf("failed to save notification contact :%w", err)
+	}
+
+	return nil
+}
+
+// transitioned queues the notification of tsx moving from its current status
+// to status on the caller's database tx, it is only sent once the tx is
+// committed and never holds the tx back. Nothing is queued when the
+// transition has no template or the owner turned every channel off. Channels
+// other than email reach the owner by user id, email is skipped while the
+// owner's email is unknown, e.g. for transactions created in a batch on the
+// owner's behalf.
+func (n *notifier) transitioned(ctx context.Context, qtx *sqlc.Queries, tsx sqlc.Transaction, status sqlc.TransactionStatus, extra map[string]string) error {
+	template, ok := n.templates.Lookup(string(tsx.TransactionType), string(tsx.TransactionStatus), string(status))
+	if !ok {
+		return nil
+	}
+
+	amount, _ := tsx.Amount.Float64Value()
+	values := map[string]string{
+		notification.PlaceholderUserID:          strconv.Itoa(int(tsx.UserID)),
+		notification.PlaceholderAmount:          fmt.Sprintf("%.2f", amount.Float64),
+		notification.PlaceholderReference:       tsx.Reference,
+		notification.PlaceholderTransactionType: string(tsx.TransactionType),
+		notification.PlaceholderStatus:          string(status),
+		notification.PlaceholderPreviousStatus:  string(tsx.TransactionStatus),
+		notification.PlaceholderCreatedAt:       tsx.CreatedAt.Time.Format("2006-01-02 15:04:05"),
+		notification.PlaceholderUpdatedAt:       time.Now().Format("2006-01-02 15:04:05"),
+	}
+	for k, v := range extra {
+		values[k] = v
+	}
+
+	placeholder, err := json.Marshal(template.Render(values))
+	if err != nil {
+		return fmt.Errorf("failed to marshal notification placeholder :%w", err)
+	}
+
+	// one notification per channel the owner picked, the preferences are
+	// checked again when it is sent
+	event := notification.Event(string(tsx.TransactionType), string(status))
+	queued, err := qtx.EnqueueNotification(ctx, sqlc.EnqueueNotificationParams{
+		Reference:    tsx.Reference,
+		TemplateName: template.Name,
+		Placeholder:  string(placeholder),
+		Event:        event,
+		Amount:       tsx.Amount,
+		UserID:       tsx.UserID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to enqueue notification :%w", err)
+	}
+	if queued > 0 {
+		return nil
+	}
+
+	// nothing is queued either because the owner turned every channel off,
+	// which is their choice, or because email is their only channel and their
+	// address is unknown
+	preference, err := qtx.GetNotificationPreference(ctx, tsx.UserID)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("failed to get notification preference :%w", err)
+	}
+	if err == nil && len(preference.Channels) == 0 {
+		return nil
+	}
+
+	metrics.NotificationUnroutable(event)
+	log.Printf("notification %s of %s not queued, email of user %d unknown", event, tsx.Reference, tsx.UserID)
+	return nil
+}
+
+func parseClock(s string) (int, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, err
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+func formatClock(minutes int16) string {
+	return fmt.Sprintf("%02d:%02d", minutes/60, minutes%60)
+}
+
+func notificationPreferences(p sqlc.NotificationPreference) (notification.Preferences, error) {
+	loc, err := time.LoadLocation(p.Timezone)
+	if err != nil {
+		return notification.Preferences{}, fmt.Errorf("failed to load timezone %s :%w", p.Timezone, err)
+	}
+
+	minAmount, _ := p.MinAmount.Float64Value()
+	return notification.Preferences{
+		DisabledEvents: p.DisabledEvents,
+		Channels:       p.Channels,
+		QuietStart:     int(p.QuietStart.Int16),
+		QuietEnd:       int(p.QuietEnd.Int16),
+		Location:       loc,
+		MinAmount:      minAmount.Float64,
+	}, nil
+}
+
+// userPreferences returns the user's preferences, the defaults when the user
+// never set any.
+func userPreferences(ctx context.Context, qtx *sqlc.Queries, userID int32) (notification.Preferences, error) {
+	p, err := qtx.GetNotificationPreference(ctx, userID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return notification.DefaultPreferences(), nil
+	}
+	if err != nil {
+		return notification.Preferences{}, fmt.Errorf("failed to get notification preference :%w", err)
+	}
+
+	return notificationPreferences(p)
+}
+
+func notificationPreferenceResponse(p sqlc.NotificationPreference) model.NotificationPreferenceResponse {
+	minAmount, _ := p.MinAmount.Float64Value()
+	resp := model.NotificationPreferenceResponse{
+		DisabledEvents: p.DisabledEvents,
+		Channels:       p.Channels,
+		MinAmount:      minAmount.Float64,
+		UpdatedAt:      &p.UpdatedAt.Time,
+	}
+	if p.QuietStart.Valid && p.QuietEnd.Valid {
+		resp.QuietHours = &model.QuietHours{
+			Start:    formatClock(p.QuietStart.Int16),
+			End:      formatClock(p.QuietEnd.Int16),
+			Timezone: p.Timezone,
+		}
+	}
+	return resp
+}
+
+func (s *NotificationService) GetPreferences(ctx context.Context, payload *model.GetNotificationPreference) (*model.NotificationPreferenceResponse, error) {
+	p, err := s.q.GetNotificationPreference(ctx, payload.UserID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		defaults := notification.DefaultPreferences()
+		return &model.NotificationPreferenceResponse{
+			DisabledEvents: []string{},
+			Channels:       defaults.Channels,
+			MinAmount:      defaults.MinAmount,
+		}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	resp := notificationPreferenceResponse(p)
+	return &resp, nil
+}
+
+func (s *NotificationService) UpdatePreferences(ctx context.Context, payload *model.NotificationPreferencePayload) (*model.NotificationPreferenceResponse, error) {
+	minAmount, err := toNumeric(payload.MinAmount)
+	if err != nil {
+		return nil, err
+	}
+
+	params := sqlc.UpsertNotificationPreferenceParams{
+		UserID:         payload.UserID,
+		DisabledEvents: payload.DisabledEvents,
+		Channels:       payload.Channels,
+		Timezone:       "UTC",
+		MinAmount:      minAmount,
+	}
+	if params.DisabledEvents == nil {
+		params.DisabledEvents = []string{}
+	}
+
+	if payload.QuietHours != nil {
+		start, err := parseClock(payload.QuietHours.Start)
+		if err != nil {
+			return nil, ErrInvalidQuietHours.Messagef("quiet hours start invalid format")
+		}
+		end, err := parseClock(payload.QuietHours.End)
+		if err != nil {
+			return nil, ErrInvalidQuietHours.Messagef("quiet hours end invalid format")
+		}
+		params.QuietStart = pgtype.Int2{Int16: int16(start), Valid: true}
+		params.QuietEnd = pgtype.Int2{Int16: int16(end), Valid: true}
+		if payload.QuietHours.Timezone != "" {
+			params.Timezone = payload.QuietHours.Timezone
+		}
+	}
+
+	p, err := s.q.UpsertNotificationPreference(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save notification preference :%w", err)
+	}
+
+	resp := notificationPreferenceResponse(p)
+	return &resp, nil
+}
+
+// backoff doubles the retry delay after every failed attempt.
+func (s *NotificationService) backoff(attempts int32) time.Duration {
+	delay := s.retryBase
+	for i := int32(1); i < attempts && delay < notificationMaxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > notificationMaxBackoff {
+		delay = notificationMaxBackoff
+	}
+	return delay
+}
+
+// SendDue attempts the notifications whose next attempt is due with a pool of
+// workers. A notification failing maxAttempts times is moved to DEAD and left
+// for an operator.
+func (s *NotificationService) SendDue(ctx context.Context) (int, error) {
+	var (
+		wg        sync.WaitGroup
+		mu        sync.Mutex
+		firstErr  error
+		claimed   atomic.Int32
+		attempted atomic.Int32
+	)
+
+	for range max(s.workers, 1) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for claimed.Add(1) <= notificationBatchSize {
+				ok, err := s.sendNext(ctx)
+				if err != nil {
+					mu.Lock()
+					if firstErr == nil {
+						firstErr = err
+					}
+					mu.Unlock()
+					return
+				}
+				if !ok {
+					return
+				}
+				attempted.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	return int(attempted.Load()), firstErr
+}
+
+// applyPreferences skips a notification the owner no longer wants and holds
+// one back until the owner's quiet hours end. held reports whether it was not
+// sent for either reason.
+func (s *NotificationService) applyPreferences(ctx context.Context, qtx *sqlc.Queries, n sqlc.NotificationQueue, now time.Time) (held bool, err error) {
+	preferences, err := userPreferences(ctx, qtx, n.UserID.Int32)
+	if err != nil {
+		return false, err
+	}
+
+	amount, _ := n.Amount.Float64Value()
+	update := sqlc.UpdateNotificationParams{
+		ID:            n.ID,
+		Attempts:      n.Attempts,
+		NextAttemptAt: n.NextAttemptAt,
+		LastError:     n.LastError,
+	}
+
+	if !preferences.Allows(n.Event.String, n.Channel, amount.Float64) {
+		update.NotificationStatus = sqlc.NotificationStatusSKIPPED
+	} else if until, quiet := preferences.QuietUntil(now); quiet {
+		update.NotificationStatus = sqlc.NotificationStatusPENDING
+		update.NextAttemptAt = pgtype.Timestamp{Time: until.In(now.Location()), Valid: true}
+	} else {
+		return false, nil
+	}
+
+	if err := qtx.UpdateNotification(ctx, update); err != nil {
+		return false, fmt.Errorf("failed to update notification :%w", err)
+	}
+	return true, nil
+}
+
+// sendNext sends a single notification. Only claiming it takes a database
+// tx, the notification service is called and the result written with no tx
+// open, so a slow notification service doesn't hold pool connections.
+func (s *NotificationService) sendNext(ctx context.Context) (bool, error) {
+	n, ok, err := s.claimNext(ctx)
+	if err != nil || !ok {
+		return false, err
+	}
+
+	now := time.Now()
+	recipient := n.Recipient
+	if n.UserID.Valid {
+		held, err := s.applyPreferences(ctx, s.q, n, now)
+		if err != nil {
+			return false, err
+		}
+		if held {
+			return true, nil
+		}
+
+		// the notification service looks the device or phone up by user
+		if n.Channel != notification.ChannelEmail {
+			recipient = strconv.Itoa(int(n.UserID.Int32))
+		}
+	}
+
+	placeholder := map[string]string{}
+	sendErr := json.Unmarshal([]byte(n.Placeholder), &placeholder)
+	if sendErr == nil {
+		sendErr = s.external.Notif.SendNotification(ctx, external.NotifRequest{
+			Recipient:    recipient,
+			TemplateName: n.TemplateName,
+			Placeholder:  placeholder,
+			Channel:      n.Channel,
+		})
+	}
+
+	attempts := n.Attempts + 1
+	update := sqlc.UpdateNotificationParams{
+		ID:                 n.ID,
+		NotificationStatus: sqlc.NotificationStatusSENT,
+		Attempts:           attempts,
+		NextAttemptAt:      pgtype.Timestamp{Time: now, Valid: true},
+		SentAt:             pgtype.Timestamp{Time: time.Now(), Valid: true},
+	}
+
+	if sendErr != nil {
+		update.NotificationStatus = sqlc.NotificationStatusPENDING
+		update.NextAttemptAt = pgtype.Timestamp{Time: now.Add(s.backoff(attempts)), Valid: true}
+		update.LastError = pgtype.Text{String: sendErr.Error(), Valid: true}
+		update.SentAt = pgtype.Timestamp{}
+		if int(attempts) >= s.maxAttempts {
+			update.NotificationStatus = sqlc.NotificationStatusDEAD
+			log.Printf("notification %d of %s is dead after %d attempts, err: %v", n.ID, n.Reference, attempts, sendErr)
+		}
+	}
+
+	if err := s.q.UpdateNotification(ctx, update); err != nil {
+		return false, fmt.Errorf("failed to update notification :%w", err)
+	}
+
+	return true, nil
+}
+
+// claimNext leases the next due notification.
+func (s *NotificationService) claimNext(ctx context.Context) (sqlc.NotificationQueue, bool, error) {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return sqlc.NotificationQueue{}, false, fmt.Errorf("failed start database tx : %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.q.WithTx(tx)
+
+	now := time.Now()
+	notifications, err := qtx.GetDueNotifications(ctx, sqlc.GetDueNotificationsParams{
+		NextAttemptAt: pgtype.Timestamp{Time: now, Valid: true},
+		Limit:         1,
+	})
+	if err != nil {
+		return sqlc.NotificationQueue{}, false, fmt.Errorf("failed to get due notifications :%w", err)
+	}
+	if len(notifications) == 0 {
+		return sqlc.NotificationQueue{}, false, nil
+	}
+	n := notifications[0]
+
+	if err := qtx.LeaseNotification(ctx, sqlc.LeaseNotificationParams{
+		ID:            n.ID,
+		NextAttemptAt: pgtype.Timestamp{Time: now.Add(notificationLease), Valid: true},
+	}); err != nil {
+		return sqlc.NotificationQueue{}, false, fmt.Errorf("failed to lease notification :%w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return sqlc.NotificationQueue{}, false, err
+	}
+
+	return n, true, nil
+}
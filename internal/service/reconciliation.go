@@ -0,0 +1,341 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/external"
+	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/model"
+	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/storage/sqlc"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type ReconciliationService struct {
+	db          *pgxpool.Pool
+	q           *sqlc.Queries
+	external    external.External
+	walletToken string
+}
+
+func toCents(amount float64) int64 {
+	return int64(math.Round(math.Abs(amount) * 100))
+}
+
+// walletOperation is the wallet call a transaction type makes on SUCCESS.
+func walletOperation(transactionType sqlc.TransactionType) string {
+	switch transactionType {
+	case sqlc.TransactionTypePURCHASE:
+		return "debit"
+	case sqlc.TransactionTypeREVERSAL:
+		return "compensation"
+	default:
+		return "credit"
+	}
+}
+
+// movesWallet reports whether a transaction in this status must have reached
+// the wallet. REVERSED transactions keep their original entry, the reversal is
+// booked under its own reference.
+func movesWallet(status sqlc.TransactionStatus) bool {
+	return status == sqlc.TransactionStatusSUCCESS || status == sqlc.TransactionStatusREVERSED
+}
+
+// RunDue reconciles from the end of the last completed run up to to, so a
+// failed or missed run is covered by the next one. The first run covers the
+// window before to. Nothing is run while another instance is reconciling.
+func (s *ReconciliationService) RunDue(ctx context.Context, to time.Time, window time.Duration) (*model.ReconciliationReport, error) {
+	// the lock is held by this tx until the run is finished, the run itself
+	// is written outside of it so a failed run is still recorded
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed start database tx : %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.q.WithTx(tx)
+
+	locked, err := qtx.TryLockReconciliation(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock reconciliation :%w", err)
+	}
+	if !locked {
+		return nil, nil
+	}
+
+	// window_end is stored to the second, truncating keeps the next run
+	// starting exactly where this one ends
+	to = to.Truncate(time.Second)
+	from := to.Add(-window)
+
+	last, err := qtx.GetLastReconciledWindowEnd(ctx)
+	switch {
+	case err == nil:
+		from = last.Time
+	case !errors.Is(err, pgx.ErrNoRows):
+		return nil, fmt.Errorf("failed to get last reconciled window :%w", err)
+	}
+
+	if !from.Before(to) {
+		return nil, nil
+	}
+
+	return s.Run(ctx, from, to)
+}
+
+// Run matches the wallet entries of a date window against the transactions
+// settled in it by reference and amount, and persists every discrepancy found.
+func (s *ReconciliationService) Run(ctx context.Context, from, to time.Time) (*model.ReconciliationReport, error) {
+	run, err := s.q.CreateReconciliationRun(ctx, sqlc.CreateReconciliationRunParams{
+		WindowStart:          pgtype.Timestamp{Time: from, Valid: true},
+		WindowEnd:            pgtype.Timestamp{Time: to, Valid: true},
+		ReconciliationStatus: sqlc.ReconciliationStatusRUNNING,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create reconciliation run :%w", err)
+	}
+
+	report, err := s.reconcile(ctx, run, from, to)
+	if err != nil {
+		if _, finishErr := s.q.FinishReconciliationRun(ctx, sqlc.FinishReconciliationRunParams{
+			ID:                   run.ID,
+			ReconciliationStatus: sqlc.ReconciliationStatusFAILED,
+			Error: pgtype.Text{
+				String: err.Error(),
+				Valid:  true,
+			},
+		}); finishErr != nil {
+			return nil, fmt.Errorf("%w, failed to mark reconciliation run failed :%v", err, finishErr)
+		}
+		return nil, err
+	}
+
+	return report, nil
+}
+
+func (s *ReconciliationService) reconcile(ctx context.Context, run sqlc.ReconciliationRun, from, to time.Time) (*model.ReconciliationReport, error) {
+	entries, err := s.external.Wallet.ListEntries(ctx, from, to, s.walletToken)
+	if err != nil {
+		return nil, err
+	}
+
+	transactions, err := s.q.GetTransactionsBySettledAt(ctx, sqlc.GetTransactionsBySettledAtParams{
+		SettledAt:   pgtype.Timestamp{Time: from, Valid: true},
+		SettledAt_2: pgtype.Timestamp{Time: to, Valid: true},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transactions :%w", err)
+	}
+
+	byRef := make(map[string]sqlc.Transaction, len(transactions))
+	for _, tsx := range transactions {
+		byRef[tsx.Reference] = tsx
+	}
+
+	entriesByRef := map[string][]external.WalletEntry{}
+	var unknown []string
+	for _, entry := range entries {
+		if _, ok := byRef[entry.Reference]; !ok && len(entriesByRef[entry.Reference]) == 0 {
+			unknown = append(unknown, entry.Reference)
+		}
+		entriesByRef[entry.Reference] = append(entriesByRef[entry.Reference], entry)
+	}
+
+	// wallet entries inside the window can belong to transactions not
+	// settled in it, e.g. one moved to FAILED after the wallet was moved
+	if len(unknown) > 0 {
+		older, err := s.q.GetTransactionsByReferences(ctx, unknown)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get transactions by reference :%w", err)
+		}
+		for _, tsx := range older {
+			byRef[tsx.Reference] = tsx
+		}
+	}
+
+	var (
+		matched       int32
+		discrepancies []sqlc.CreateReconciliationDiscrepanciesParams
+	)
+
+	for ref, tsx := range byRef {
+		tsxEntries := entriesByRef[ref]
+		amount, _ := tsx.Amount.Float64Value()
+
+		switch {
+		case movesWallet(tsx.TransactionStatus) && len(tsxEntries) == 0:
+			discrepancies = append(discrepancies, sqlc.CreateReconciliationDiscrepanciesParams{
+				RunID:             run.ID,
+				Category:          sqlc.DiscrepancyCategoryMISSING,
+				Reference:         ref,
+				TransactionAmount: tsx.Amount,
+				TransactionStatus: pgtype.Text{String: string(tsx.TransactionStatus), Valid: true},
+				SuggestedRepair:   fmt.Sprintf("re-send the wallet %s of %.2f for reference %s", walletOperation(tsx.TransactionType), amount.Float64, ref),
+			})
+
+		case !movesWallet(tsx.TransactionStatus) && len(tsxEntries) > 0:
+			walletAmount, err := toNumeric(math.Abs(tsxEntries[0].Amount))
+			if err != nil {
+				return nil, err
+			}
+			discrepancies = append(discrepancies, sqlc.CreateReconciliationDiscrepanciesParams{
+				RunID:             run.ID,
+				Category:          sqlc.DiscrepancyCategorySTATUSMISMATCH,
+				Reference:         ref,
+				TransactionAmount: tsx.Amount,
+				WalletAmount:      walletAmount,
+				TransactionStatus: pgtype.Text{String: string(tsx.TransactionStatus), Valid: true},
+				SuggestedRepair:   fmt.Sprintf("transaction is %s but the wallet was moved, mark it SUCCESS or reverse the wallet entry", tsx.TransactionStatus),
+			})
+
+		case len(tsxEntries) > 0:
+			mismatch := false
+			for _, entry := range tsxEntries {
+				if toCents(entry.Amount) == toCents(amount.Float64) {
+					continue
+				}
+
+				walletAmount, err := toNumeric(math.Abs(entry.Amount))
+				if err != nil {
+					return nil, err
+				}
+				discrepancies = append(discrepancies, sqlc.CreateReconciliationDiscrepanciesParams{
+					RunID:             run.ID,
+					Category:          sqlc.DiscrepancyCategoryAMOUNTMISMATCH,
+					Reference:         ref,
+					TransactionAmount: tsx.Amount,
+					WalletAmount:      walletAmount,
+					TransactionStatus: pgtype.Text{String: string(tsx.TransactionStatus), Valid: true},
+					SuggestedRepair:   fmt.Sprintf("adjust the wallet by %.2f for reference %s", amount.Float64-math.Abs(entry.Amount), ref),
+				})
+				mismatch = true
+				break
+			}
+			if !mismatch {
+				matched++
+			}
+		}
+	}
+
+	for ref, refEntries := range entriesByRef {
+		if _, ok := byRef[ref]; ok {
+			continue
+		}
+
+		walletAmount, err := toNumeric(math.Abs(refEntries[0].Amount))
+		if err != nil {
+			return nil, err
+		}
+		discrepancies = append(discrepancies, sqlc.CreateReconciliationDiscrepanciesParams{
+			RunID:           run.ID,
+			Category:        sqlc.DiscrepancyCategoryORPHAN,
+			Reference:       ref,
+			WalletAmount:    walletAmount,
+			SuggestedRepair: "no transaction has this reference, reverse the wallet entry or backfill the transaction",
+		})
+	}
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed start database tx : %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.q.WithTx(tx)
+
+	if len(discrepancies) > 0 {
+		if _, err := qtx.CreateReconciliationDiscrepancies(ctx, discrepancies); err != nil {
+			return nil, fmt.Errorf("failed to copy discrepancies :%w", err)
+		}
+	}
+
+	run, err = qtx.FinishReconciliationRun(ctx, sqlc.FinishReconciliationRunParams{
+		ID:                   run.ID,
+		ReconciliationStatus: sqlc.ReconciliationStatusCOMPLETED,
+		MatchedCount:         matched,
+		DiscrepancyCount:     int32(len(discrepancies)),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to finish reconciliation run :%w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return s.GetReport(ctx, run.ID)
+}
+
+func (s *ReconciliationService) GetReport(ctx context.Context, id int32) (*model.ReconciliationReport, error) {
+	run, err := s.q.GetReconciliationRun(ctx, id)
+	if err != nil {
+		return nil, notFound(err, ErrReportNotFound, "reconciliation report")
+	}
+
+	discrepancies, err := s.q.GetReconciliationDiscrepancies(ctx, run.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	report := reconciliationReport(run)
+	for _, d := range discrepancies {
+		discrepancy := model.ReconciliationDiscrepancy{
+			Category:          string(d.Category),
+			Reference:         d.Reference,
+			TransactionStatus: d.TransactionStatus.String,
+			SuggestedRepair:   d.SuggestedRepair,
+		}
+		if d.TransactionAmount.Valid {
+			amount, _ := d.TransactionAmount.Float64Value()
+			discrepancy.TransactionAmount = &amount.Float64
+		}
+		if d.WalletAmount.Valid {
+			amount, _ := d.WalletAmount.Float64Value()
+			discrepancy.WalletAmount = &amount.Float64
+		}
+		report.Discrepancies = append(report.Discrepancies, discrepancy)
+	}
+
+	return &report, nil
+}
+
+func (s *ReconciliationService) GetReports(ctx context.Context, payload *model.GetReconciliationReports) ([]model.ReconciliationReport, error) {
+	pageSize := payload.Limit
+	pageNumber := payload.Offset
+
+	runs, err := s.q.GetReconciliationRuns(ctx, sqlc.GetReconciliationRunsParams{
+		Limit:  pageSize,
+		Offset: (pageNumber - 1) * pageSize,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resp := make([]model.ReconciliationReport, 0, len(runs))
+	for _, run := range runs {
+		resp = append(resp, reconciliationReport(run))
+	}
+
+	return resp, nil
+}
+
+func reconciliationReport(run sqlc.ReconciliationRun) model.ReconciliationReport {
+	report := model.ReconciliationReport{
+		ID:               run.ID,
+		WindowStart:      run.WindowStart.Time,
+		WindowEnd:        run.WindowEnd.Time,
+		Status:           string(run.ReconciliationStatus),
+		MatchedCount:     run.MatchedCount,
+		DiscrepancyCount: run.DiscrepancyCount,
+		Error:            run.Error.String,
+		CreatedAt:        run.CreatedAt.Time,
+	}
+	if run.FinishedAt.Valid {
+		report.FinishedAt = &run.FinishedAt.Time
+	}
+	return report
+}
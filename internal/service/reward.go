@@ -0,0 +1,321 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/external"
+	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/model"
+	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/storage/sqlc"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// rewardBatchSize caps how many cleared rewards are credited per worker run.
+const rewardBatchSize = 50
+
+type RewardService struct {
+	db             *pgxpool.Pool
+	q              *sqlc.Queries
+	external       external.External
+	clearingPeriod time.Duration
+	walletToken    string
+}
+
+func merchantCategory(additionalInfo pgtype.Text) string {
+	if !additionalInfo.Valid || additionalInfo.String == "" {
+		return ""
+	}
+
+	info := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(additionalInfo.String), &info); err != nil {
+		return ""
+	}
+
+	category, _ := info["merchant_category"].(string)
+	return category
+}
+
+func campaignAllowsCategory(categories []string, category string) bool {
+	// a campaign without categories applies to every merchant
+	if len(categories) == 0 {
+		return true
+	}
+
+	for i := range categories {
+		if categories[i] == category {
+			return true
+		}
+	}
+
+	return false
+}
+
+// accrue records a PENDING reward for every active campaign matching a
+// purchase that has just transitioned to SUCCESS. It runs on the caller's
+// database tx so the reward only exists if the transition is committed. The
+// reward gets the reference of its cashback transaction up front, every
+// attempt to credit it reuses that reference.
+func (s *RewardService) accrue(ctx context.Context, qtx *sqlc.Queries, tsx sqlc.Transaction) error {
+	now := time.Now()
+
+	campaigns, err := qtx.GetActiveCampaigns(ctx, pgtype.Timestamp{Time: now, Valid: true})
+	if err != nil {
+		return fmt.Errorf("failed to get active campaigns :%w", err)
+	}
+
+	category := merchantCategory(tsx.AdditionalInfo)
+	amount, _ := tsx.Amount.Float64Value()
+
+	for _, campaign := range campaigns {
+		if !campaignAllowsCategory(campaign.MerchantCategories, category) {
+			continue
+		}
+
+		percentage, _ := campaign.Percentage.Float64Value()
+		maxPerUser, _ := campaign.MaxPerUser.Float64Value()
+
+		// held until the caller's tx ends, so two purchases of the same user
+		// can't both see the room left under the cap
+		if err := qtx.LockUserCampaignRewards(ctx, sqlc.LockUserCampaignRewardsParams{
+			CampaignID: campaign.ID,
+			UserID:     tsx.UserID,
+		}); err != nil {
+			return fmt.Errorf("failed to lock user rewards :%w", err)
+		}
+
+		used, err := qtx.SumUserCampaignRewards(ctx, sqlc.SumUserCampaignRewardsParams{
+			CampaignID: campaign.ID,
+			UserID:     tsx.UserID,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to sum user rewards :%w", err)
+		}
+		usedFloat, _ := used.Float64Value()
+
+		cashback := roundToTwoDecimalPlaces(amount.Float64 * percentage.Float64 / 100)
+		remaining := roundToTwoDecimalPlaces(maxPerUser.Float64 - usedFloat.Float64)
+		if cashback > remaining {
+			cashback = remaining
+		}
+		if cashback <= 0 {
+			continue
+		}
+
+		cashbackNumeric, err := toNumeric(cashback)
+		if err != nil {
+			return err
+		}
+
+		creditRef, err := generateReference(string(sqlc.TransactionTypeCASHBACK), tsx.UserID)
+		if err != nil {
+			return err
+		}
+
+		if _, err := qtx.CreateReward(ctx, sqlc.CreateRewardParams{
+			CampaignID:           campaign.ID,
+			UserID:               tsx.UserID,
+			TransactionReference: tsx.Reference,
+			CreditReference:      pgtype.Text{String: creditRef, Valid: true},
+			Amount:               cashbackNumeric,
+			RewardStatus:         sqlc.RewardStatusPENDING,
+			ClearAt: pgtype.Timestamp{
+				Time:  now.Add(s.clearingPeriod),
+				Valid: true,
+			},
+		}); err != nil {
+			return fmt.Errorf("failed to create reward :%w", err)
+		}
+	}
+
+	return nil
+}
+
+// clawback undoes the rewards earned by a refunded purchase. Rewards still in
+// their clearing period are cancelled, credited rewards are debited back from
+// the wallet and their CASHBACK transaction is marked REVERSED.
+func (s *RewardService) clawback(ctx context.Context, qtx *sqlc.Queries, reference string) error {
+	rewards, err := qtx.GetRewardsByTransactionReference(ctx, reference)
+	if err != nil {
+		return fmt.Errorf("failed to get rewards :%w", err)
+	}
+
+	for _, reward := range rewards {
+		switch reward.RewardStatus {
+		case sqlc.RewardStatusPENDING:
+			if _, err := qtx.UpdateRewardStatus(ctx, sqlc.UpdateRewardStatusParams{
+				ID:              reward.ID,
+				RewardStatus:    sqlc.RewardStatusCANCELLED,
+				CreditReference: reward.CreditReference,
+			}); err != nil {
+				return fmt.Errorf("failed to cancel reward :%w", err)
+			}
+
+		case sqlc.RewardStatusCREDITED:
+			cashback, err := qtx.GetTransactionByReference(ctx, reward.CreditReference.String)
+			if err != nil {
+				return fmt.Errorf("failed to get cashback transaction :%w", err)
+			}
+
+			if _, err := qtx.UpdateTransactionStatusByReference(ctx, sqlc.UpdateTransactionStatusByReferenceParams{
+				Reference:         cashback.Reference,
+				TransactionStatus: sqlc.TransactionStatusREVERSED,
+				AdditionalInfo:    cashback.AdditionalInfo,
+			}); err != nil {
+				return fmt.Errorf("failed to reverse cashback transaction :%w", err)
+			}
+
+			if err := recordStatusChange(ctx, qtx, cashback, sqlc.TransactionStatusREVERSED); err != nil {
+				return err
+			}
+
+			amount, _ := reward.Amount.Float64Value()
+			if _, err := s.external.Wallet.Debit(ctx, external.WalletRequest{
+				UserID:    cashback.UserID,
+				Amount:    amount.Float64,
+				Reference: cashback.Reference,
+				Status:    StatusReversed,
+			}, s.walletToken); err != nil {
+				return fmt.Errorf("debit wallet error :%w", err)
+			}
+
+			if _, err := qtx.UpdateRewardStatus(ctx, sqlc.UpdateRewardStatusParams{
+				ID:              reward.ID,
+				RewardStatus:    sqlc.RewardStatusCLAWEDBACK,
+				CreditReference: reward.CreditReference,
+			}); err != nil {
+				return fmt.Errorf("failed to claw back reward :%w", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// CreditDueRewards credits rewards whose clearing period has passed. Every
+// reward is credited in its own database tx so a wallet failure only leaves
+// that reward PENDING for the next run.
+func (s *RewardService) CreditDueRewards(ctx context.Context) (int, error) {
+	credited := 0
+	for credited < rewardBatchSize {
+		ok, err := s.creditNextReward(ctx)
+		if err != nil {
+			return credited, err
+		}
+		if !ok {
+			break
+		}
+		credited++
+	}
+
+	return credited, nil
+}
+
+func (s *RewardService) creditNextReward(ctx context.Context) (bool, error) {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed start database tx : %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.q.WithTx(tx)
+	ctx, events := withEventMetrics(ctx)
+
+	rewards, err := qtx.GetDueRewards(ctx, sqlc.GetDueRewardsParams{
+		ClearAt: pgtype.Timestamp{Time: time.Now(), Valid: true},
+		Limit:   1,
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to get due rewards :%w", err)
+	}
+	if len(rewards) == 0 {
+		return false, nil
+	}
+	reward := rewards[0]
+
+	// a retry after a failed commit credits the wallet under the same
+	// reference again, which the wallet treats as the same operation
+	ref := reward.CreditReference.String
+	cashback := sqlc.CreateTransactionParams{
+		UserID:            reward.UserID,
+		Amount:            reward.Amount,
+		TransactionType:   sqlc.TransactionTypeCASHBACK,
+		TransactionStatus: sqlc.TransactionStatusSUCCESS,
+		Reference:         ref,
+		Description: pgtype.Text{
+			String: fmt.Sprintf("cashback for %s", reward.TransactionReference),
+			Valid:  true,
+		},
+	}
+	if _, err := qtx.CreateTransaction(ctx, cashback); err != nil {
+		return false, fmt.Errorf("failed to create cashback transaction :%w", err)
+	}
+
+	if err := recordCreated(ctx, qtx, cashback); err != nil {
+		return false, err
+	}
+
+	if _, err := qtx.UpdateRewardStatus(ctx, sqlc.UpdateRewardStatusParams{
+		ID:              reward.ID,
+		RewardStatus:    sqlc.RewardStatusCREDITED,
+		CreditReference: reward.CreditReference,
+	}); err != nil {
+		return false, fmt.Errorf("failed to update reward :%w", err)
+	}
+
+	amount, _ := reward.Amount.Float64Value()
+	if _, err := s.external.Wallet.Credit(ctx, external.WalletRequest{
+		UserID:    reward.UserID,
+		Amount:    amount.Float64,
+		Reference: ref,
+		Status:    StatusSuccess,
+	}, s.walletToken); err != nil {
+		return false, fmt.Errorf("credit wallet error :%w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return false, err
+	}
+	events.observe()
+
+	return true, nil
+}
+
+func (s *RewardService) GetRewards(ctx context.Context, payload *model.GetRewards) ([]model.RewardResponse, error) {
+	pageSize := payload.Limit
+	pageNumber := payload.Offset
+
+	rewards, err := s.q.GetRewardsByUserId(ctx, sqlc.GetRewardsByUserIdParams{
+		UserID: payload.UserID,
+		Limit:  pageSize,
+		Offset: (pageNumber - 1) * pageSize,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resp := make([]model.RewardResponse, 0, len(rewards))
+	for _, reward := range rewards {
+		// the credit reference is reserved on accrual, the cashback
+		// transaction only exists once the reward is credited
+		creditRef := ""
+		if reward.RewardStatus == sqlc.RewardStatusCREDITED || reward.RewardStatus == sqlc.RewardStatusCLAWEDBACK {
+			creditRef = reward.CreditReference.String
+		}
+
+		amount, _ := reward.Amount.Float64Value()
+		resp = append(resp, model.RewardResponse{
+			CampaignID:           reward.CampaignID,
+			TransactionReference: reward.TransactionReference,
+			CreditReference:      creditRef,
+			Amount:               amount.Float64,
+			Status:               string(reward.RewardStatus),
+			ClearAt:              reward.ClearAt.Time,
+			CreatedAt:            reward.CreatedAt.Time,
+		})
+	}
+
+	return resp, nil
+}
@@ -2,10 +2,14 @@ package service
 
 import (
 	"context"
+	"time"
 
+	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/event"
 	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/external"
 	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/model"
+	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/notification"
 	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/storage/sqlc"
+	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/stream"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -16,16 +20,178 @@ type Service struct {
 		GetTransasction(context.Context, *model.GetTransaction) (sqlc.Transaction, error)
 		GetTransactions(context.Context, *model.GetTransactions) ([]sqlc.GetTransactionsRow, error)
 		CreateRefund(context.Context, *model.TransactionRefundPayload) (*model.RefundResponse, error)
+		CancelTransaction(context.Context, *model.TransactionCancelPayload) (model.TransactionResponse, error)
+		CreateBatch(context.Context, *model.TransactionBatchPayload) (*model.TransactionBatchResponse, error)
+		GetBatch(context.Context, *model.GetTransactionBatch) (*model.TransactionBatchResponse, error)
 	}
+	Reward interface {
+		GetRewards(context.Context, *model.GetRewards) ([]model.RewardResponse, error)
+		CreditDueRewards(context.Context) (int, error)
+	}
+	Dispute interface {
+		Open(context.Context, *model.DisputePayload) (*model.DisputeResponse, error)
+		AddEvidence(context.Context, *model.DisputeEvidencePayload) (*model.DisputeEvidenceResponse, error)
+		GetDispute(context.Context, *model.GetDispute) (*model.DisputeResponse, error)
+		GetDisputes(context.Context, *model.GetDisputes) ([]model.DisputeResponse, error)
+		Resolve(context.Context, *model.DisputeResolvePayload) (*model.DisputeResponse, error)
+		ProcessDisputes(context.Context) (int, error)
+	}
+	Reconciliation interface {
+		Run(ctx context.Context, from, to time.Time) (*model.ReconciliationReport, error)
+		RunDue(ctx context.Context, to time.Time, window time.Duration) (*model.ReconciliationReport, error)
+		GetReport(context.Context, int32) (*model.ReconciliationReport, error)
+		GetReports(context.Context, *model.GetReconciliationReports) ([]model.ReconciliationReport, error)
+	}
+	Webhook interface {
+		Register(context.Context, *model.WebhookEndpointPayload) (*model.WebhookEndpointResponse, error)
+		GetEndpoints(context.Context, *model.GetWebhookEndpoints) ([]model.WebhookEndpointResponse, error)
+		DeleteEndpoint(context.Context, *model.GetWebhookEndpoint) error
+		EnableEndpoint(context.Context, *model.GetWebhookEndpoint) (*model.WebhookEndpointResponse, error)
+		GetDeliveries(context.Context, *model.GetWebhookDeliveries) ([]model.WebhookDeliveryResponse, error)
+		GetDelivery(context.Context, *model.GetWebhookDelivery) (*model.WebhookDeliveryResponse, error)
+		Redeliver(context.Context, *model.GetWebhookDelivery) (*model.WebhookDeliveryResponse, error)
+		DeliverDue(context.Context) (int, error)
+	}
+	Event interface {
+		PublishPending(context.Context) (int, error)
+	}
+	Notification interface {
+		GetPreferences(context.Context, *model.GetNotificationPreference) (*model.NotificationPreferenceResponse, error)
+		UpdatePreferences(context.Context, *model.NotificationPreferencePayload) (*model.NotificationPreferenceResponse, error)
+		SendDue(context.Context) (int, error)
+	}
+	Admin interface {
+		SearchTransactions(context.Context, *model.AdminSearchTransactions) ([]model.AdminTransactionResponse, error)
+		GetTransaction(context.Context, *model.AdminGetTransaction) (*model.AdminTransactionDetail, error)
+		ForceStatus(context.Context, *model.AdminStatusPayload) (model.TransactionResponse, error)
+		Refund(context.Context, *model.AdminRefundPayload) (*model.RefundResponse, error)
+		ResolveDispute(context.Context, *model.AdminResolveDisputePayload) (*model.DisputeResponse, error)
+		GetReconciliationReport(context.Context, *model.AdminGetReconciliationReport) (*model.ReconciliationReport, error)
+		GetReconciliationReports(context.Context, *model.AdminGetReconciliationReports) ([]model.ReconciliationReport, error)
+		GetAuditLogs(context.Context, *model.GetAdminAuditLogs) ([]model.AdminAuditLogResponse, error)
+	}
+	Stream interface {
+		Subscribe(int32) (<-chan struct{}, func())
+		GetStatusEvents(context.Context, *model.GetStatusEvents) ([]model.TransactionStatusEvent, error)
+		ListTransactions(context.Context, *model.ListTransactions, func(sqlc.Transaction) error) error
+	}
+}
+
+type Config struct {
+	// RewardClearingPeriod is how long a cashback reward stays PENDING
+	// before it is credited to the wallet.
+	RewardClearingPeriod time.Duration
+	// WalletServiceToken authenticates every call to the wallet service, the
+	// wallet moved is named in the request rather than taken from a token.
+	WalletServiceToken string
+	// DisputeReviewPeriod is how long a dispute can stay under review before
+	// it is settled in the user's favour.
+	DisputeReviewPeriod time.Duration
+	// BatchMaxItems is the maximum number of items accepted in one
+	// transaction batch.
+	BatchMaxItems int
+	// WebhookMaxAttempts is how many times a webhook delivery is attempted
+	// before it is marked FAILED.
+	WebhookMaxAttempts int
+	// WebhookRetryBase is the delay before the first retry of a webhook
+	// delivery, doubled after every failed attempt.
+	WebhookRetryBase time.Duration
+	// WebhookDisableAfter is how many consecutive failed attempts disable a
+	// webhook endpoint.
+	WebhookDisableAfter int
+	// NotificationWorkers is how many notifications are sent concurrently.
+	NotificationWorkers int
+	// NotificationMaxAttempts is how many times a notification is attempted
+	// before it is marked DEAD.
+	NotificationMaxAttempts int
+	// NotificationRetryBase is the delay before the first retry of a
+	// notification, doubled after every failed attempt.
+	NotificationRetryBase time.Duration
+	// NotificationTemplates maps transaction transitions to the notification
+	// sent to the owner.
+	NotificationTemplates notification.Templates
+	// EventMaxAttempts is how many times an outbox event is published before
+	// it is set aside as dead.
+	EventMaxAttempts int
+	// Publisher receives the transaction events relayed from the outbox.
+	Publisher event.Publisher
+	// Hub signals the open transaction streams of this instance.
+	Hub *stream.Hub
+	// External holds the clients of the other services.
+	External external.External
 }
 
-func NewService(q *sqlc.Queries, db *pgxpool.Pool) Service {
-	external := external.NewExternal()
+func NewService(q *sqlc.Queries, db *pgxpool.Pool, cfg Config) Service {
+	external := cfg.External
+	notifier := &notifier{templates: cfg.NotificationTemplates}
+	reward := &RewardService{
+		q:              q,
+		db:             db,
+		external:       external,
+		clearingPeriod: cfg.RewardClearingPeriod,
+		walletToken:    cfg.WalletServiceToken,
+	}
+	transaction := &TransactionService{
+		q:             q,
+		db:            db,
+		external:      external,
+		reward:        reward,
+		notifier:      notifier,
+		batchMaxItems: cfg.BatchMaxItems,
+		walletToken:   cfg.WalletServiceToken,
+	}
+	dispute := &DisputeService{
+		q:            q,
+		db:           db,
+		external:     external,
+		reward:       reward,
+		notifier:     notifier,
+		reviewPeriod: cfg.DisputeReviewPeriod,
+		walletToken:  cfg.WalletServiceToken,
+	}
+	reconciliation := &ReconciliationService{
+		q:           q,
+		db:          db,
+		external:    external,
+		walletToken: cfg.WalletServiceToken,
+	}
 	return Service{
-		Transaction: &TransactionService{
-			q:        q,
-			db:       db,
-			external: external,
+		Transaction:    transaction,
+		Reward:         reward,
+		Dispute:        dispute,
+		Reconciliation: reconciliation,
+		Webhook: &WebhookService{
+			q:            q,
+			db:           db,
+			external:     external,
+			maxAttempts:  cfg.WebhookMaxAttempts,
+			retryBase:    cfg.WebhookRetryBase,
+			disableAfter: cfg.WebhookDisableAfter,
+		},
+		Event: &EventService{
+			q:           q,
+			db:          db,
+			publisher:   cfg.Publisher,
+			maxAttempts: cfg.EventMaxAttempts,
+		},
+		Notification: &NotificationService{
+			q:           q,
+			db:          db,
+			external:    external,
+			workers:     cfg.NotificationWorkers,
+			maxAttempts: cfg.NotificationMaxAttempts,
+			retryBase:   cfg.NotificationRetryBase,
+		},
+		Admin: &AdminService{
+			q:              q,
+			transaction:    transaction,
+			dispute:        dispute,
+			reconciliation: reconciliation,
+			walletToken:    cfg.WalletServiceToken,
+		},
+		Stream: &StreamService{
+			q:   q,
+			hub: cfg.Hub,
 		},
 	}
 }
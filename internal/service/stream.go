@@ -0,0 +1,138 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/event"
+	eventv1 "github.com/ArdiSasongko/EwalletProjects-transaction/internal/event/proto/v1"
+	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/model"
+	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/storage/sqlc"
+	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/stream"
+	"github.com/jackc/pgx/v5/pgtype"
+	"google.golang.org/protobuf/proto"
+)
+
+// streamBatchSize caps how many events a stream reads from the outbox at once.
+const streamBatchSize = 100
+
+// streamOverlap is how long a stream keeps reading events it is already past.
+// Outbox ids are taken at insert, so an event whose tx commits late shows up
+// below ids already sent, the overlap covers the longest such tx.
+const streamOverlap = 2 * time.Minute
+
+// listPageSize is how many rows ListTransactions reads per query, so a full
+// history is never held in memory nor a connection held while it is sent.
+const listPageSize = 500
+
+type StreamService struct {
+	q   *sqlc.Queries
+	hub *stream.Hub
+}
+
+func (s *StreamService) Subscribe(userID int32) (<-chan struct{}, func()) {
+	return s.hub.Subscribe(userID)
+}
+
+// GetStatusEvents reads the status changes of a user after an event id, plus
+// the ones committed late within streamOverlap that the stream hasn't sent.
+// It moves payload past the events returned, so a stream passes the same
+// payload on every read. The outbox id doubles as the stream event id, so a
+// client resumes by sending the last id it has seen, it may then get again
+// events of the overlap and should drop the ids it already has.
+func (s *StreamService) GetStatusEvents(ctx context.Context, payload *model.GetStatusEvents) ([]model.TransactionStatusEvent, error) {
+	if payload.Seen == nil {
+		payload.Seen = map[int64]time.Time{}
+	}
+
+	// forget the ids the query can't return anymore, with room for the
+	// clocks of the database and this instance to differ
+	expired := time.Now().Add(-2 * streamOverlap)
+	seen := make([]int64, 0, len(payload.Seen))
+	for id, writtenAt := range payload.Seen {
+		if writtenAt.Before(expired) {
+			delete(payload.Seen, id)
+			continue
+		}
+		seen = append(seen, id)
+	}
+
+	events, err := s.q.GetUserEventsAfter(ctx, sqlc.GetUserEventsAfterParams{
+		UserID:         pgtype.Int4{Int32: payload.UserID, Valid: true},
+		EventType:      event.TypeStatusChanged,
+		AfterID:        payload.AfterID,
+		OverlapSeconds: int32(streamOverlap / time.Second),
+		Seen:           seen,
+		Limit:          streamBatchSize,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, e := range events {
+		payload.Seen[e.ID] = e.CreatedAt.Time
+		payload.AfterID = max(payload.AfterID, e.ID)
+	}
+
+	resp := make([]model.TransactionStatusEvent, 0, len(events))
+	for _, e := range events {
+		envelope := &eventv1.Envelope{}
+		if err := proto.Unmarshal(e.Payload, envelope); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal event %d :%w", e.ID, err)
+		}
+
+		changed := envelope.GetStatusChanged()
+		if changed == nil {
+			continue
+		}
+
+		resp = append(resp, model.TransactionStatusEvent{
+			ID:              e.ID,
+			Reference:       changed.Reference,
+			TransactionType: changed.TransactionType,
+			PreviousStatus:  changed.PreviousStatus,
+			Status:          changed.Status,
+			Amount:          changed.Amount,
+			OccurredAt:      envelope.OccurredAt.AsTime(),
+		})
+	}
+
+	return resp, nil
+}
+
+func timestampFilter(t time.Time) pgtype.Timestamp {
+	return pgtype.Timestamp{Time: t, Valid: !t.IsZero()}
+}
+
+// ListTransactions hands the user's transactions to send in id order. They are
+// read a page at a time, each page after the last id sent, so no database
+// connection or tx stays open while the receiver takes its time.
+func (s *StreamService) ListTransactions(ctx context.Context, payload *model.ListTransactions, send func(sqlc.Transaction) error) error {
+	var lastID int32
+	for {
+		items, err := s.q.GetUserTransactionsAfter(ctx, sqlc.GetUserTransactionsAfterParams{
+			UserID:            payload.UserID,
+			AfterID:           lastID,
+			TransactionType:   payload.TransactionType,
+			TransactionStatus: payload.TransactionStatus,
+			CreatedFrom:       timestampFilter(payload.From),
+			CreatedTo:         timestampFilter(payload.To),
+			Limit:             listPageSize,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to get transactions :%w", err)
+		}
+
+		for _, item := range items {
+			if err := send(item); err != nil {
+				return err
+			}
+			lastID = item.ID
+		}
+
+		if len(items) < listPageSize {
+			return nil
+		}
+	}
+}
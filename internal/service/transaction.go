@@ -2,6 +2,8 @@ package service
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"log"
@@ -11,18 +13,24 @@ import (
 
 	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/external"
 	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/model"
+	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/notification"
 	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/storage/sqlc"
+	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/tracing"
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 const (
-	StatusPending  = "PENDING"
-	StatusSuccess  = "SUCCESS"
-	StatusFailed   = "FAILED"
-	StatusReversed = "REVERSED"
+	StatusPending   = "PENDING"
+	StatusSuccess   = "SUCCESS"
+	StatusFailed    = "FAILED"
+	StatusReversed  = "REVERSED"
+	StatusCancelled = "CANCELLED"
 )
 
+// maxAdditionalInfoLength is the size of the additional_info column.
+const maxAdditionalInfoLength = 255
+
 var transType = map[string]bool{
 	"TOPUP":    true,
 	"PURCHASE": true,
@@ -30,41 +38,96 @@ var transType = map[string]bool{
 }
 
 var flowStatus = map[string][]string{
-	StatusPending: {StatusSuccess, StatusFailed},
+	StatusPending: {StatusSuccess, StatusFailed, StatusCancelled},
 	StatusSuccess: {StatusReversed},
 	StatusFailed:  {StatusSuccess},
 }
 
-func generateReference(typeTrans string, userID int32) string {
+func canTransition(from, to string) bool {
+	next := flowStatus[from]
+	for i := range next {
+		if next[i] == to {
+			return true
+		}
+	}
+	return false
+}
+
+// generateReference builds a transaction reference. The random suffix keeps
+// two references of the same user and type created in the same second apart,
+// the wallet tells retries apart from new operations by the reference.
+func generateReference(typeTrans string, userID int32) (string, error) {
+	b := make([]byte, 6)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate reference :%w", err)
+	}
+
 	now := time.Now()
 	timeFormatted := now.Format("200601022150405")
-	reference := fmt.Sprintf("%d%s%s", userID, typeTrans, timeFormatted)
-	return reference
+	reference := fmt.Sprintf("%d%s%s%s", userID, typeTrans, timeFormatted, strings.ToUpper(hex.EncodeToString(b)))
+	return reference, nil
+}
+
+// compensationReference derives the reference of the typeTrans compensation
+// of reference. A transaction is compensated at most once, so every retry of
+// the compensation sends the wallet the same reference and so the same
+// idempotency key, even when an earlier attempt moved the wallet and then
+// failed to commit.
+func compensationReference(typeTrans string, reference string) string {
+	return fmt.Sprintf("%s-%s", reference, typeTrans)
 }
 
 type TransactionService struct {
-	db       *pgxpool.Pool
-	q        *sqlc.Queries
-	external external.External
+	db            *pgxpool.Pool
+	q             *sqlc.Queries
+	external      external.External
+	reward        *RewardService
+	notifier      *notifier
+	batchMaxItems int
+	walletToken   string
 }
 
 func roundToTwoDecimalPlaces(amount float64) float64 {
 	return math.Round(amount*100) / 100
 }
 
-func (s *TransactionService) Create(ctx context.Context, payload *model.TransactionPayload) (sqlc.CreateTransactionRow, error) {
+func toNumeric(amount float64) (pgtype.Numeric, error) {
+	amountStr := fmt.Sprintf("%.2f", roundToTwoDecimalPlaces(amount))
+	amountNumeric := pgtype.Numeric{}
+	if err := amountNumeric.Scan(amountStr); err != nil {
+		return pgtype.Numeric{}, err
+	}
+	return amountNumeric, nil
+}
+
+func checkTransactionPayload(payload *model.TransactionPayload) error {
 	if !transType[payload.TransactionType] {
-		return sqlc.CreateTransactionRow{}, fmt.Errorf("transaction type not allowed only 'TOPUP', 'PURCHASE', 'REFUND'")
+		return ErrTransactionTypeNotAllowed
 	}
 
 	jsonAditionalInfo := map[string]interface{}{}
 	if payload.AdditionalInfo != "" {
 		err := json.Unmarshal([]byte(payload.AdditionalInfo), &jsonAditionalInfo)
 		if err != nil {
-			return sqlc.CreateTransactionRow{}, fmt.Errorf("additional info invalid format")
+			return ErrInvalidAdditionalInfo
 		}
 	}
-	reference := generateReference(payload.TransactionType, payload.UserID)
+
+	return nil
+}
+
+func (s *TransactionService) Create(ctx context.Context, payload *model.TransactionPayload) (_ sqlc.CreateTransactionRow, err error) {
+	ctx, span := tracing.Start(ctx, "TransactionService.Create")
+	defer func() { tracing.End(span, err) }()
+
+	if err := checkTransactionPayload(payload); err != nil {
+		return sqlc.CreateTransactionRow{}, err
+	}
+
+	reference, err := generateReference(payload.TransactionType, payload.UserID)
+	if err != nil {
+		return sqlc.CreateTransactionRow{}, err
+	}
 
 	amountFloat := roundToTwoDecimalPlaces(payload.Amount)
 	amountStr := fmt.Sprintf("%.2f", amountFloat)
@@ -73,7 +136,16 @@ func (s *TransactionService) Create(ctx context.Context, payload *model.Transact
 		return sqlc.CreateTransactionRow{}, err
 	}
 
-	resp, err := s.q.CreateTransaction(ctx, sqlc.CreateTransactionParams{
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return sqlc.CreateTransactionRow{}, fmt.Errorf("failed start database tx : %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.q.WithTx(tx)
+	ctx, events := withEventMetrics(ctx)
+
+	params := sqlc.CreateTransactionParams{
 		UserID:            payload.UserID,
 		Amount:            amountNumeric,
 		TransactionType:   sqlc.TransactionType(payload.TransactionType),
@@ -87,32 +159,55 @@ func (s *TransactionService) Create(ctx context.Context, payload *model.Transact
 			Valid:  true,
 		},
 		Reference: reference,
-	})
+	}
 
+	resp, err := qtx.CreateTransaction(ctx, params)
 	if err != nil {
 		return sqlc.CreateTransactionRow{}, err
 	}
 
+	if err := recordCreated(ctx, qtx, params); err != nil {
+		return sqlc.CreateTransactionRow{}, err
+	}
+
+	if err := rememberContact(ctx, qtx, payload.UserID, payload.Email); err != nil {
+		return sqlc.CreateTransactionRow{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return sqlc.CreateTransactionRow{}, err
+	}
+	events.observe()
+
 	return resp, nil
 }
 
-func (s *TransactionService) UpdateTransaction(ctx context.Context, payload *model.TransactionUpdatePayload) (model.TransactionResponse, error) {
-	tsx, err := s.q.GetTransactionByReference(ctx, payload.Reference)
+func (s *TransactionService) UpdateTransaction(ctx context.Context, payload *model.TransactionUpdatePayload) (_ model.TransactionResponse, err error) {
+	ctx, span := tracing.Start(ctx, "TransactionService.UpdateTransaction")
+	defer func() { tracing.End(span, err) }()
+
+	tx, err := s.db.Begin(ctx)
 	if err != nil {
-		return model.TransactionResponse{}, err
+		return model.TransactionResponse{}, fmt.Errorf("failed start database tx : %w", err)
 	}
+	defer tx.Rollback(ctx)
 
-	statusValid := false
-	statusTransaction := flowStatus[string(tsx.TransactionStatus)]
+	qtx := s.q.WithTx(tx)
+	ctx, events := withEventMetrics(ctx)
 
-	for i := range statusTransaction {
-		if statusTransaction[i] == payload.TransactionStatus {
-			statusValid = true
-		}
+	// the row stays locked until commit, so two updates can't both pass the
+	// transition check against the same status
+	tsx, err := qtx.GetTransactionByReferenceForUpdate(ctx, payload.Reference)
+	if err != nil {
+		return model.TransactionResponse{}, notFound(err, ErrTransactionNotFound, "transaction")
 	}
 
-	if !statusValid {
-		return model.TransactionResponse{}, fmt.Errorf("transaction status flow invalid, payload status - %s", payload.TransactionStatus)
+	if !canTransition(string(tsx.TransactionStatus), payload.TransactionStatus) {
+		return model.TransactionResponse{}, ErrInvalidTransition.Messagef("transaction status flow invalid, payload status - %s", payload.TransactionStatus)
+	}
+
+	if payload.TransactionStatus == StatusCancelled {
+		return model.TransactionResponse{}, ErrInvalidTransition.Messagef("transaction status %s only allowed through cancel", StatusCancelled)
 	}
 
 	currentAditionalInfo := map[string]interface{}{}
@@ -126,7 +221,7 @@ func (s *TransactionService) UpdateTransaction(ctx context.Context, payload *mod
 	if payload.AdditionalInfo != "" {
 		newAdditionalInfo := map[string]interface{}{}
 		if err := json.Unmarshal([]byte(payload.AdditionalInfo), &newAdditionalInfo); err != nil {
-			return model.TransactionResponse{}, fmt.Errorf("failed to umarshal new additional info: %w", err)
+			return model.TransactionResponse{}, ErrInvalidAdditionalInfo.Wrap(err)
 		}
 
 		for key, val := range newAdditionalInfo {
@@ -140,6 +235,9 @@ func (s *TransactionService) UpdateTransaction(ctx context.Context, payload *mod
 		if err != nil {
 			return model.TransactionResponse{}, fmt.Errorf("failed to umarshal updated additional info: %w", err)
 		}
+		if len(byteAdditionalInfo) > maxAdditionalInfoLength {
+			return model.TransactionResponse{}, ErrInvalidAdditionalInfo.Messagef("additional info must not exceed %d characters once merged", maxAdditionalInfoLength)
+		}
 
 		additionalInfo = pgtype.Text{
 			String: string(byteAdditionalInfo),
@@ -151,66 +249,53 @@ func (s *TransactionService) UpdateTransaction(ctx context.Context, payload *mod
 		}
 	}
 
-	tx, err := s.db.Begin(ctx)
-	if err != nil {
-		return model.TransactionResponse{}, fmt.Errorf("failed start database tx : %w", err)
-	}
-	defer tx.Rollback(ctx)
-
-	qtx := s.q.WithTx(tx)
-
 	resp, err := qtx.UpdateTransactionStatusByReference(ctx, sqlc.UpdateTransactionStatusByReferenceParams{
 		Reference:         payload.Reference,
 		AdditionalInfo:    additionalInfo,
 		TransactionStatus: sqlc.TransactionStatus(payload.TransactionStatus),
+		StatusReason:      pgtype.Text{String: payload.Reason, Valid: payload.Reason != ""},
 	})
 	if err != nil {
 		return model.TransactionResponse{}, err
 	}
 
+	if err := recordStatusChange(ctx, qtx, tsx, resp); err != nil {
+		return model.TransactionResponse{}, err
+	}
+
 	if payload.TransactionStatus == StatusFailed {
-		switch {
-		case strings.Contains(payload.Reference, string(sqlc.TransactionTypeTOPUP)):
-			amount, _ := tsx.Amount.Float64Value()
-			if err := s.external.Notif.SendNotification(ctx, external.NotifRequest{
-				Recipient:    payload.Email,
-				TemplateName: "topup_failed",
-				Placeholder: map[string]string{
-					"user_id":    string(tsx.UserID),
-					"amount":     fmt.Sprintf("%.2f", amount.Float64),
-					"reference":  payload.Reference,
-					"created_at": tsx.CreatedAt.Time.Format("2006-01-02 15:04:05"),
-				},
-			}); err != nil {
-				return model.TransactionResponse{}, err
-			}
-		case strings.Contains(payload.Reference, string(sqlc.TransactionTypePURCHASE)):
-			amount, _ := tsx.Amount.Float64Value()
-			if err := s.external.Notif.SendNotification(ctx, external.NotifRequest{
-				Recipient:    payload.Email,
-				TemplateName: "purchase_failed",
-				Placeholder: map[string]string{
-					"user_id":    string(tsx.UserID),
-					"amount":     fmt.Sprintf("%.2f", amount.Float64),
-					"reference":  payload.Reference,
-					"created_at": tsx.CreatedAt.Time.Format("2006-01-02 15:04:05"),
-				},
-			}); err != nil {
-				return model.TransactionResponse{}, err
-			}
+		if err := s.notifier.transitioned(ctx, qtx, tsx, resp, nil); err != nil {
+			return model.TransactionResponse{}, err
 		}
 		if err := tx.Commit(ctx); err != nil {
 			return model.TransactionResponse{}, fmt.Errorf("failed to process transaction")
 		}
+		events.observe()
 		return model.TransactionResponse{
 			Reference: payload.Reference,
 			Status:    payload.TransactionStatus,
 		}, nil
 	}
 
+	if payload.TransactionStatus == StatusReversed {
+		respTrans, err := s.reverse(ctx, qtx, tsx)
+		if err != nil {
+			return model.TransactionResponse{}, err
+		}
+
+		if err := tx.Commit(ctx); err != nil {
+			return model.TransactionResponse{}, err
+		}
+		events.observe()
+
+		respTrans.Status = string(resp)
+		return respTrans, nil
+	}
+
 	amountFloat, _ := tsx.Amount.Float64Value()
 
 	updatePayload := external.WalletRequest{
+		UserID:    tsx.UserID,
 		Amount:    amountFloat.Float64,
 		Reference: tsx.Reference,
 		Status:    string(resp),
@@ -220,9 +305,9 @@ func (s *TransactionService) UpdateTransaction(ctx context.Context, payload *mod
 	log.Println(tsx.TransactionType)
 	switch tsx.TransactionType {
 	case sqlc.TransactionTypePURCHASE:
-		d, err := s.external.Wallet.Debit(ctx, updatePayload, payload.Token)
+		d, err := s.external.Wallet.Debit(ctx, updatePayload, s.walletToken)
 		if err != nil {
-			return model.TransactionResponse{}, fmt.Errorf("credit wallet error :%w", err)
+			return model.TransactionResponse{}, walletError(err)
 		}
 		log.Println()
 		respTrans = model.TransactionResponse{
@@ -232,10 +317,16 @@ func (s *TransactionService) UpdateTransaction(ctx context.Context, payload *mod
 			CreatedAt: d.CreatedAt,
 		}
 
+		if payload.TransactionStatus == StatusSuccess {
+			if err := s.reward.accrue(ctx, qtx, tsx); err != nil {
+				return model.TransactionResponse{}, err
+			}
+		}
+
 	case sqlc.TransactionTypeTOPUP:
-		d, err := s.external.Wallet.Credit(ctx, updatePayload, payload.Token)
+		d, err := s.external.Wallet.Credit(ctx, updatePayload, s.walletToken)
 		if err != nil {
-			return model.TransactionResponse{}, fmt.Errorf("credit wallet error :%w", err)
+			return model.TransactionResponse{}, walletError(err)
 		}
 		respTrans = model.TransactionResponse{
 			WalletID:  d.UserID,
@@ -245,15 +336,206 @@ func (s *TransactionService) UpdateTransaction(ctx context.Context, payload *mod
 		}
 	}
 
+	if err := s.notifier.transitioned(ctx, qtx, tsx, resp, nil); err != nil {
+		return model.TransactionResponse{}, err
+	}
+
 	if err := tx.Commit(ctx); err != nil {
 		return model.TransactionResponse{}, err
 	}
+	events.observe()
 
 	respTrans.Status = string(resp)
 	return respTrans, nil
 }
 
-func (s *TransactionService) GetTransactions(ctx context.Context, payload *model.GetTransactions) ([]sqlc.GetTransactionsRow, error) {
+// reverse compensates the wallet for a SUCCESS transaction that is being
+// REVERSED by issuing the opposite wallet operation, and records a REVERSAL
+// transaction linked to the original reference.
+func (s *TransactionService) reverse(ctx context.Context, qtx *sqlc.Queries, tsx sqlc.Transaction) (model.TransactionResponse, error) {
+	// only the types settled through the wallet here have a compensation,
+	// cashback and dispute credits are undone through their own flows and
+	// reversals and refunds are compensations already
+	switch tsx.TransactionType {
+	case sqlc.TransactionTypePURCHASE:
+		refunded, err := hasRefund(ctx, qtx, tsx.Reference)
+		if err != nil {
+			return model.TransactionResponse{}, err
+		}
+		if refunded {
+			return model.TransactionResponse{}, ErrAlreadyRefunded
+		}
+	case sqlc.TransactionTypeTOPUP:
+	default:
+		return model.TransactionResponse{}, ErrInvalidTransition.Messagef("transaction type %s can't be reversed", tsx.TransactionType)
+	}
+
+	active, err := hasActiveDispute(ctx, qtx, tsx.Reference)
+	if err != nil {
+		return model.TransactionResponse{}, err
+	}
+	if active {
+		return model.TransactionResponse{}, ErrActiveDispute
+	}
+
+	reversalRef := compensationReference(string(sqlc.TransactionTypeREVERSAL), tsx.Reference)
+
+	reversal := sqlc.CreateTransactionParams{
+		UserID:            tsx.UserID,
+		Amount:            tsx.Amount,
+		TransactionType:   sqlc.TransactionTypeREVERSAL,
+		TransactionStatus: sqlc.TransactionStatusSUCCESS,
+		Reference:         reversalRef,
+		Description: pgtype.Text{
+			String: fmt.Sprintf("reversal of %s", tsx.Reference),
+			Valid:  true,
+		},
+	}
+	if _, err := qtx.CreateTransaction(ctx, reversal); err != nil {
+		return model.TransactionResponse{}, fmt.Errorf("failed to create reversal transaction :%w", err)
+	}
+
+	if err := recordCreated(ctx, qtx, reversal); err != nil {
+		return model.TransactionResponse{}, err
+	}
+
+	if _, err := qtx.CreateTransactionReversal(ctx, sqlc.CreateTransactionReversalParams{
+		TransactionReference: tsx.Reference,
+		ReversalReference:    reversalRef,
+		Amount:               tsx.Amount,
+	}); err != nil {
+		return model.TransactionResponse{}, fmt.Errorf("failed to link reversal transaction :%w", err)
+	}
+
+	if tsx.TransactionType == sqlc.TransactionTypePURCHASE {
+		if err := s.reward.clawback(ctx, qtx, tsx.Reference); err != nil {
+			return model.TransactionResponse{}, err
+		}
+	}
+
+	if err := s.notifier.transitioned(ctx, qtx, tsx, sqlc.TransactionStatusREVERSED, map[string]string{
+		notification.PlaceholderReversalReference: reversalRef,
+	}); err != nil {
+		return model.TransactionResponse{}, err
+	}
+
+	// the wallet goes last so nothing left in the tx can fail after it moved
+	amount, _ := tsx.Amount.Float64Value()
+	walletRequest := external.WalletRequest{
+		UserID:    tsx.UserID,
+		Amount:    amount.Float64,
+		Reference: reversalRef,
+		Status:    StatusReversed,
+	}
+
+	var d *external.WalletResponse
+	switch tsx.TransactionType {
+	case sqlc.TransactionTypePURCHASE:
+		// give the money spent back to the user
+		d, err = s.external.Wallet.Credit(ctx, walletRequest, s.walletToken)
+	case sqlc.TransactionTypeTOPUP:
+		// take back the money the top-up added
+		d, err = s.external.Wallet.Debit(ctx, walletRequest, s.walletToken)
+	}
+	if err != nil {
+		return model.TransactionResponse{}, walletError(err)
+	}
+
+	return model.TransactionResponse{
+		WalletID:          d.UserID,
+		Reference:         tsx.Reference,
+		ReversalReference: reversalRef,
+		Amount:            d.Amount,
+		CreatedAt:         d.CreatedAt,
+	}, nil
+}
+
+// hasRefund reports whether the purchase was refunded, a refund leaves it
+// SUCCESS.
+func hasRefund(ctx context.Context, qtx *sqlc.Queries, reference string) (bool, error) {
+	count, err := qtx.CountTransactionRefundsByReference(ctx, reference)
+	if err != nil {
+		return false, fmt.Errorf("failed to check refund :%w", err)
+	}
+	return count > 0, nil
+}
+
+func (s *TransactionService) CancelTransaction(ctx context.Context, payload *model.TransactionCancelPayload) (_ model.TransactionResponse, err error) {
+	ctx, span := tracing.Start(ctx, "TransactionService.CancelTransaction")
+	defer func() { tracing.End(span, err) }()
+
+	// make sure the transaction belongs to the requesting user
+	if _, err := s.q.GetTransactionByReferenceAndUserId(ctx, sqlc.GetTransactionByReferenceAndUserIdParams{
+		Reference: payload.Reference,
+		UserID:    payload.UserID,
+	}); err != nil {
+		return model.TransactionResponse{}, notFound(err, ErrTransactionNotFound, "transaction")
+	}
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return model.TransactionResponse{}, fmt.Errorf("failed start database tx : %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.q.WithTx(tx)
+	ctx, events := withEventMetrics(ctx)
+
+	// lock the row so a concurrent status update can't settle it while cancelling
+	tsx, err := qtx.GetTransactionByReferenceForUpdate(ctx, payload.Reference)
+	if err != nil {
+		return model.TransactionResponse{}, notFound(err, ErrTransactionNotFound, "transaction")
+	}
+
+	if !canTransition(string(tsx.TransactionStatus), StatusCancelled) {
+		return model.TransactionResponse{}, ErrInvalidTransition.Messagef("only 'PENDING' transaction can be cancelled, current status - %s", tsx.TransactionStatus)
+	}
+
+	resp, err := qtx.UpdateTransactionStatusByReference(ctx, sqlc.UpdateTransactionStatusByReferenceParams{
+		Reference:         tsx.Reference,
+		TransactionStatus: sqlc.TransactionStatusCANCELLED,
+		AdditionalInfo:    tsx.AdditionalInfo,
+		StatusReason:      pgtype.Text{String: payload.Reason, Valid: payload.Reason != ""},
+	})
+	if err != nil {
+		return model.TransactionResponse{}, err
+	}
+
+	if err := recordStatusChange(ctx, qtx, tsx, resp); err != nil {
+		return model.TransactionResponse{}, err
+	}
+
+	// a PENDING transaction has not moved any money yet: the wallet is only
+	// debited or credited on SUCCESS and rewards only accrue on SUCCESS, so
+	// there is nothing held that has to be released here.
+
+	if err := rememberContact(ctx, qtx, tsx.UserID, payload.Email); err != nil {
+		return model.TransactionResponse{}, err
+	}
+
+	if err := s.notifier.transitioned(ctx, qtx, tsx, resp, nil); err != nil {
+		return model.TransactionResponse{}, err
+	}
+
+	amount, _ := tsx.Amount.Float64Value()
+
+	if err := tx.Commit(ctx); err != nil {
+		return model.TransactionResponse{}, err
+	}
+	events.observe()
+
+	return model.TransactionResponse{
+		Reference: tsx.Reference,
+		Amount:    amount.Float64,
+		CreatedAt: tsx.CreatedAt.Time,
+		Status:    string(resp),
+	}, nil
+}
+
+func (s *TransactionService) GetTransactions(ctx context.Context, payload *model.GetTransactions) (_ []sqlc.GetTransactionsRow, err error) {
+	ctx, span := tracing.Start(ctx, "TransactionService.GetTransactions")
+	defer func() { tracing.End(span, err) }()
+
 	pageSize := payload.Limit
 	pageNumber := payload.Offset
 
@@ -272,50 +554,78 @@ func (s *TransactionService) GetTransactions(ctx context.Context, payload *model
 	return resp, nil
 }
 
-func (s *TransactionService) GetTransasction(ctx context.Context, payload *model.GetTransaction) (sqlc.Transaction, error) {
+func (s *TransactionService) GetTransasction(ctx context.Context, payload *model.GetTransaction) (_ sqlc.Transaction, err error) {
+	ctx, span := tracing.Start(ctx, "TransactionService.GetTransasction")
+	defer func() { tracing.End(span, err) }()
+
 	resp, err := s.q.GetTransactionByReferenceAndUserId(ctx, sqlc.GetTransactionByReferenceAndUserIdParams{
 		UserID:    payload.UserID,
 		Reference: payload.Reference,
 	})
 
 	if err != nil {
-		return sqlc.Transaction{}, err
+		return sqlc.Transaction{}, notFound(err, ErrTransactionNotFound, "transaction")
 	}
 
 	return resp, nil
 }
 
-func (s *TransactionService) CreateRefund(ctx context.Context, payload *model.TransactionRefundPayload) (*model.RefundResponse, error) {
+func (s *TransactionService) CreateRefund(ctx context.Context, payload *model.TransactionRefundPayload) (_ *model.RefundResponse, err error) {
+	ctx, span := tracing.Start(ctx, "TransactionService.CreateRefund")
+	defer func() { tracing.End(span, err) }()
+
 	// using transaction for consistent
 	tx, err := s.db.Begin(ctx)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("failed start database tx : %w", err)
 	}
 	defer tx.Rollback(ctx)
 
 	qtx := s.q.WithTx(tx)
-	// get transaction
-	tsx, err := qtx.GetTransactionByReference(ctx, payload.Reference)
+	ctx, events := withEventMetrics(ctx)
+	// get transaction, locked so concurrent refunds of it are serialized
+	tsx, err := qtx.GetTransactionByReferenceForUpdate(ctx, payload.Reference)
 	if err != nil {
-		return nil, err
+		return nil, notFound(err, ErrTransactionNotFound, "transaction")
+	}
+
+	// users can only refund their own purchases
+	if payload.UserID != 0 && tsx.UserID != payload.UserID {
+		return nil, ErrTransactionNotFound
 	}
 
 	log.Println(tsx.TransactionType)
 	log.Println(tsx.TransactionStatus)
 	// check type and status
 	if tsx.TransactionType != sqlc.TransactionTypePURCHASE || tsx.TransactionStatus != StatusSuccess {
-		return nil, fmt.Errorf("only type 'PURCHASE' and status 'SUCCESS' can be refunded")
+		return nil, ErrNotRefundable
+	}
+
+	refunded, err := hasRefund(ctx, qtx, tsx.Reference)
+	if err != nil {
+		return nil, err
+	}
+	if refunded {
+		return nil, ErrAlreadyRefunded
+	}
+
+	active, err := hasActiveDispute(ctx, qtx, tsx.Reference)
+	if err != nil {
+		return nil, err
+	}
+	if active {
+		return nil, ErrActiveDispute
 	}
 
 	jsonAditionalInfo := map[string]interface{}{}
 	if payload.AdditionalInfo != "" {
 		err := json.Unmarshal([]byte(payload.AdditionalInfo), &jsonAditionalInfo)
 		if err != nil {
-			return nil, fmt.Errorf("additional info invalid format")
+			return nil, ErrInvalidAdditionalInfo
 		}
 	}
-	// generate new reference
-	ref := generateReference(string(sqlc.TransactionTypeREFUND), tsx.UserID)
+	// derived from the purchase so a retried refund reuses it
+	ref := compensationReference(string(sqlc.TransactionTypeREFUND), tsx.Reference)
 	// create model for createtransaction
 	tsxReq := sqlc.CreateTransactionParams{
 		UserID:            tsx.UserID,
@@ -338,16 +648,59 @@ func (s *TransactionService) CreateRefund(ctx context.Context, payload *model.Tr
 		return nil, err
 	}
 
-	// connect to wallet (credit)
+	if err := recordCreated(ctx, qtx, tsxReq); err != nil {
+		return nil, err
+	}
+
+	if _, err := qtx.CreateTransactionRefund(ctx, sqlc.CreateTransactionRefundParams{
+		TransactionReference: tsx.Reference,
+		RefundReference:      resp.Reference,
+		Amount:               tsx.Amount,
+	}); err != nil {
+		return nil, fmt.Errorf("failed to link refund transaction :%w", err)
+	}
+
+	if err := recordRefunded(ctx, qtx, tsx, resp.Reference); err != nil {
+		return nil, err
+	}
+
+	// the owner asking for the refund, support staff refund without it
+	if payload.UserID != 0 {
+		if err := rememberContact(ctx, qtx, tsx.UserID, payload.Email); err != nil {
+			return nil, err
+		}
+	}
+
+	// a refund is created SUCCESS, there is no previous status
+	if err := s.notifier.transitioned(ctx, qtx, sqlc.Transaction{
+		UserID:          tsxReq.UserID,
+		Amount:          tsxReq.Amount,
+		TransactionType: tsxReq.TransactionType,
+		Reference:       tsxReq.Reference,
+		CreatedAt:       pgtype.Timestamp{Time: time.Now(), Valid: true},
+	}, tsxReq.TransactionStatus, map[string]string{
+		notification.PlaceholderOriginalReference: tsx.Reference,
+	}); err != nil {
+		return nil, err
+	}
+
+	// take back any cashback earned by the refunded purchase
+	if err := s.reward.clawback(ctx, qtx, tsx.Reference); err != nil {
+		return nil, err
+	}
+
+	// connect to wallet (credit), last so nothing left in the tx can fail
+	// after it moved
 	amount, _ := tsx.Amount.Float64Value()
 	walletRequest := external.WalletRequest{
+		UserID:    tsx.UserID,
 		Reference: resp.Reference,
 		Amount:    amount.Float64,
 	}
 
-	walletResp, err := s.external.Wallet.Credit(ctx, walletRequest, payload.Token)
+	walletResp, err := s.external.Wallet.Credit(ctx, walletRequest, s.walletToken)
 	if err != nil {
-		return nil, err
+		return nil, walletError(err)
 	}
 
 	response := model.RefundResponse{
@@ -360,6 +713,7 @@ func (s *TransactionService) CreateRefund(ctx context.Context, payload *model.Tr
 	if err := tx.Commit(ctx); err != nil {
 		return nil, err
 	}
+	events.observe()
 
 	return &response, nil
 }
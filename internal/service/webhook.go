@@ -0,0 +1,434 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/external"
+	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/model"
+	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/storage/sqlc"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	// webhookBatchSize caps how many deliveries are attempted per worker run.
+	webhookBatchSize = 50
+	// webhookMaxBackoff caps the delay between two attempts of a delivery.
+	webhookMaxBackoff = 6 * time.Hour
+	// webhookLease hides a claimed delivery from the other workers while it
+	// is sent, it outlasts the client timeout. A worker stopped mid-send
+	// leaves the delivery to be attempted again once the lease ends.
+	webhookLease = time.Minute
+)
+
+type WebhookService struct {
+	db           *pgxpool.Pool
+	q            *sqlc.Queries
+	external     external.External
+	maxAttempts  int
+	retryBase    time.Duration
+	disableAfter int
+}
+
+func generateWebhookSecret() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate webhook secret :%w", err)
+	}
+	return "whsec_" + hex.EncodeToString(b), nil
+}
+
+// enqueueWebhook queues a delivery of a status change to every active endpoint
+// of the transaction owner. It runs on the caller's database tx so nothing is
+// delivered for a change that is rolled back.
+func enqueueWebhook(ctx context.Context, qtx *sqlc.Queries, tsx sqlc.Transaction, status sqlc.TransactionStatus) error {
+	amount, _ := tsx.Amount.Float64Value()
+	event := model.WebhookEvent{
+		Event:           "transaction." + strings.ToLower(string(status)),
+		Reference:       tsx.Reference,
+		UserID:          tsx.UserID,
+		TransactionType: string(tsx.TransactionType),
+		PreviousStatus:  string(tsx.TransactionStatus),
+		Status:          string(status),
+		Amount:          amount.Float64,
+		OccurredAt:      time.Now(),
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal webhook event :%w", err)
+	}
+
+	if _, err := qtx.EnqueueWebhookDeliveries(ctx, sqlc.EnqueueWebhookDeliveriesParams{
+		Event:     event.Event,
+		Reference: tsx.Reference,
+		Payload:   string(payload),
+		UserID:    tsx.UserID,
+	}); err != nil {
+		return fmt.Errorf("failed to enqueue webhook deliveries :%w", err)
+	}
+
+	return nil
+}
+
+// backoff doubles the retry delay after every failed attempt.
+func (s *WebhookService) backoff(attempts int32) time.Duration {
+	delay := s.retryBase
+	for i := int32(1); i < attempts && delay < webhookMaxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > webhookMaxBackoff {
+		delay = webhookMaxBackoff
+	}
+	return delay
+}
+
+func (s *WebhookService) Register(ctx context.Context, payload *model.WebhookEndpointPayload) (*model.WebhookEndpointResponse, error) {
+	secret, err := generateWebhookSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint, err := s.q.CreateWebhookEndpoint(ctx, sqlc.CreateWebhookEndpointParams{
+		UserID: payload.UserID,
+		Url:    payload.URL,
+		Secret: secret,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create webhook endpoint :%w", err)
+	}
+
+	// the secret is only shown once, when the endpoint is registered
+	resp := webhookEndpointResponse(endpoint)
+	resp.Secret = endpoint.Secret
+	return &resp, nil
+}
+
+func (s *WebhookService) GetEndpoints(ctx context.Context, payload *model.GetWebhookEndpoints) ([]model.WebhookEndpointResponse, error) {
+	endpoints, err := s.q.GetWebhookEndpointsByUserId(ctx, payload.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := make([]model.WebhookEndpointResponse, 0, len(endpoints))
+	for _, endpoint := range endpoints {
+		resp = append(resp, webhookEndpointResponse(endpoint))
+	}
+
+	return resp, nil
+}
+
+func (s *WebhookService) DeleteEndpoint(ctx context.Context, payload *model.GetWebhookEndpoint) error {
+	deleted, err := s.q.DeleteWebhookEndpoint(ctx, sqlc.DeleteWebhookEndpointParams{
+		ID:     payload.EndpointID,
+		UserID: payload.UserID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete webhook endpoint :%w", err)
+	}
+	if deleted == 0 {
+		return ErrWebhookEndpointNotFound
+	}
+
+	return nil
+}
+
+// EnableEndpoint re-activates an endpoint that was disabled after failing too
+// many times. Deliveries still PENDING are picked up again by the worker.
+func (s *WebhookService) EnableEndpoint(ctx context.Context, payload *model.GetWebhookEndpoint) (*model.WebhookEndpointResponse, error) {
+	endpoint, err := s.q.EnableWebhookEndpoint(ctx, sqlc.EnableWebhookEndpointParams{
+		ID:     payload.EndpointID,
+		UserID: payload.UserID,
+	})
+	if err != nil {
+		return nil, notFound(err, ErrWebhookEndpointNotFound, "webhook endpoint")
+	}
+
+	resp := webhookEndpointResponse(endpoint)
+	return &resp, nil
+}
+
+func (s *WebhookService) GetDeliveries(ctx context.Context, payload *model.GetWebhookDeliveries) ([]model.WebhookDeliveryResponse, error) {
+	if _, err := s.q.GetWebhookEndpointByIdAndUserId(ctx, sqlc.GetWebhookEndpointByIdAndUserIdParams{
+		ID:     payload.EndpointID,
+		UserID: payload.UserID,
+	}); err != nil {
+		return nil, notFound(err, ErrWebhookEndpointNotFound, "webhook endpoint")
+	}
+
+	pageSize := payload.Limit
+	pageNumber := payload.Offset
+
+	deliveries, err := s.q.GetWebhookDeliveriesByEndpointId(ctx, sqlc.GetWebhookDeliveriesByEndpointIdParams{
+		EndpointID: payload.EndpointID,
+		Limit:      pageSize,
+		Offset:     (pageNumber - 1) * pageSize,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resp := make([]model.WebhookDeliveryResponse, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		resp = append(resp, webhookDeliveryResponse(delivery, nil))
+	}
+
+	return resp, nil
+}
+
+func (s *WebhookService) GetDelivery(ctx context.Context, payload *model.GetWebhookDelivery) (*model.WebhookDeliveryResponse, error) {
+	if _, err := s.q.GetWebhookEndpointByIdAndUserId(ctx, sqlc.GetWebhookEndpointByIdAndUserIdParams{
+		ID:     payload.EndpointID,
+		UserID: payload.UserID,
+	}); err != nil {
+		return nil, notFound(err, ErrWebhookEndpointNotFound, "webhook endpoint")
+	}
+
+	delivery, err := s.q.GetWebhookDeliveryByIdAndEndpointId(ctx, sqlc.GetWebhookDeliveryByIdAndEndpointIdParams{
+		ID:         payload.DeliveryID,
+		EndpointID: payload.EndpointID,
+	})
+	if err != nil {
+		return nil, notFound(err, ErrWebhookDeliveryNotFound, "webhook delivery")
+	}
+
+	attempts, err := s.q.GetWebhookDeliveryAttempts(ctx, delivery.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := webhookDeliveryResponse(delivery, attempts)
+	return &resp, nil
+}
+
+// Redeliver queues a new delivery with the payload of an earlier one, so the
+// original delivery and its attempt log are kept as they were.
+func (s *WebhookService) Redeliver(ctx context.Context, payload *model.GetWebhookDelivery) (*model.WebhookDeliveryResponse, error) {
+	endpoint, err := s.q.GetWebhookEndpointByIdAndUserId(ctx, sqlc.GetWebhookEndpointByIdAndUserIdParams{
+		ID:     payload.EndpointID,
+		UserID: payload.UserID,
+	})
+	if err != nil {
+		return nil, notFound(err, ErrWebhookEndpointNotFound, "webhook endpoint")
+	}
+
+	if !endpoint.IsActive {
+		return nil, ErrWebhookEndpointDisabled
+	}
+
+	delivery, err := s.q.GetWebhookDeliveryByIdAndEndpointId(ctx, sqlc.GetWebhookDeliveryByIdAndEndpointIdParams{
+		ID:         payload.DeliveryID,
+		EndpointID: endpoint.ID,
+	})
+	if err != nil {
+		return nil, notFound(err, ErrWebhookDeliveryNotFound, "webhook delivery")
+	}
+
+	redelivery, err := s.q.CreateWebhookDelivery(ctx, sqlc.CreateWebhookDeliveryParams{
+		EndpointID: endpoint.ID,
+		Event:      delivery.Event,
+		Reference:  delivery.Reference,
+		Payload:    delivery.Payload,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create webhook delivery :%w", err)
+	}
+
+	resp := webhookDeliveryResponse(redelivery, nil)
+	return &resp, nil
+}
+
+// DeliverDue attempts the deliveries whose next attempt is due. A delivery is
+// claimed and its result recorded in two short database txs, no connection is
+// held while the endpoint is called.
+func (s *WebhookService) DeliverDue(ctx context.Context) (int, error) {
+	attempted := 0
+	for attempted < webhookBatchSize {
+		ok, err := s.deliverNext(ctx)
+		if err != nil {
+			return attempted, err
+		}
+		if !ok {
+			break
+		}
+		attempted++
+	}
+
+	return attempted, nil
+}
+
+func (s *WebhookService) deliverNext(ctx context.Context) (bool, error) {
+	d, ok, err := s.claimNext(ctx)
+	if err != nil || !ok {
+		return false, err
+	}
+
+	start := time.Now()
+	statusCode, sendErr := s.external.Webhook.Send(ctx, external.WebhookRequest{
+		URL:        d.Url,
+		Secret:     d.Secret,
+		DeliveryID: d.ID,
+		Event:      d.Event,
+		Payload:    []byte(d.Payload),
+	})
+
+	if err := s.recordAttempt(ctx, d, start, statusCode, sendErr); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// claimNext leases the next due delivery.
+func (s *WebhookService) claimNext(ctx context.Context) (sqlc.GetDueWebhookDeliveriesRow, bool, error) {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return sqlc.GetDueWebhookDeliveriesRow{}, false, fmt.Errorf("failed start database tx : %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.q.WithTx(tx)
+
+	now := time.Now()
+	deliveries, err := qtx.GetDueWebhookDeliveries(ctx, sqlc.GetDueWebhookDeliveriesParams{
+		NextAttemptAt: pgtype.Timestamp{Time: now, Valid: true},
+		Limit:         1,
+	})
+	if err != nil {
+		return sqlc.GetDueWebhookDeliveriesRow{}, false, fmt.Errorf("failed to get due webhook deliveries :%w", err)
+	}
+	if len(deliveries) == 0 {
+		return sqlc.GetDueWebhookDeliveriesRow{}, false, nil
+	}
+	d := deliveries[0]
+
+	if err := qtx.LeaseWebhookDelivery(ctx, sqlc.LeaseWebhookDeliveryParams{
+		ID:            d.ID,
+		NextAttemptAt: pgtype.Timestamp{Time: now.Add(webhookLease), Valid: true},
+	}); err != nil {
+		return sqlc.GetDueWebhookDeliveriesRow{}, false, fmt.Errorf("failed to lease webhook delivery :%w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return sqlc.GetDueWebhookDeliveriesRow{}, false, err
+	}
+
+	return d, true, nil
+}
+
+// recordAttempt logs the attempt of a claimed delivery and schedules the next
+// one when it failed.
+func (s *WebhookService) recordAttempt(ctx context.Context, d sqlc.GetDueWebhookDeliveriesRow, start time.Time, statusCode int, sendErr error) error {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed start database tx : %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.q.WithTx(tx)
+
+	lastStatusCode := pgtype.Int4{Int32: int32(statusCode), Valid: statusCode != 0}
+	var lastError pgtype.Text
+	if sendErr != nil {
+		lastError = pgtype.Text{String: sendErr.Error(), Valid: true}
+	}
+
+	if err := qtx.CreateWebhookDeliveryAttempt(ctx, sqlc.CreateWebhookDeliveryAttemptParams{
+		DeliveryID: d.ID,
+		StatusCode: lastStatusCode,
+		Error:      lastError,
+		DurationMs: int32(time.Since(start).Milliseconds()),
+	}); err != nil {
+		return fmt.Errorf("failed to log webhook attempt :%w", err)
+	}
+
+	now := time.Now()
+	attempts := d.Attempts + 1
+	update := sqlc.UpdateWebhookDeliveryParams{
+		ID:             d.ID,
+		DeliveryStatus: sqlc.WebhookDeliveryStatusDELIVERED,
+		Attempts:       attempts,
+		NextAttemptAt:  pgtype.Timestamp{Time: now, Valid: true},
+		LastStatusCode: lastStatusCode,
+		LastError:      lastError,
+		DeliveredAt:    pgtype.Timestamp{Time: now, Valid: true},
+	}
+
+	if sendErr != nil {
+		update.DeliveryStatus = sqlc.WebhookDeliveryStatusPENDING
+		update.NextAttemptAt = pgtype.Timestamp{Time: now.Add(s.backoff(attempts)), Valid: true}
+		update.DeliveredAt = pgtype.Timestamp{}
+		if int(attempts) >= s.maxAttempts {
+			update.DeliveryStatus = sqlc.WebhookDeliveryStatusFAILED
+		}
+
+		// an endpoint failing every delivery is disabled until its owner
+		// enables it again
+		if _, err := qtx.RecordWebhookEndpointFailure(ctx, sqlc.RecordWebhookEndpointFailureParams{
+			DisableAfter: int32(s.disableAfter),
+			ID:           d.EndpointID,
+		}); err != nil {
+			return fmt.Errorf("failed to record webhook endpoint failure :%w", err)
+		}
+	} else {
+		if err := qtx.RecordWebhookEndpointSuccess(ctx, d.EndpointID); err != nil {
+			return fmt.Errorf("failed to record webhook endpoint success :%w", err)
+		}
+	}
+
+	if err := qtx.UpdateWebhookDelivery(ctx, update); err != nil {
+		return fmt.Errorf("failed to update webhook delivery :%w", err)
+	}
+
+	return tx.Commit(ctx)
+}
+
+func webhookEndpointResponse(endpoint sqlc.WebhookEndpoint) model.WebhookEndpointResponse {
+	resp := model.WebhookEndpointResponse{
+		ID:                  endpoint.ID,
+		URL:                 endpoint.Url,
+		IsActive:            endpoint.IsActive,
+		ConsecutiveFailures: endpoint.ConsecutiveFailures,
+		CreatedAt:           endpoint.CreatedAt.Time,
+	}
+	if endpoint.DisabledAt.Valid {
+		resp.DisabledAt = &endpoint.DisabledAt.Time
+	}
+	return resp
+}
+
+func webhookDeliveryResponse(delivery sqlc.WebhookDelivery, attempts []sqlc.WebhookDeliveryAttempt) model.WebhookDeliveryResponse {
+	resp := model.WebhookDeliveryResponse{
+		ID:             delivery.ID,
+		EndpointID:     delivery.EndpointID,
+		Event:          delivery.Event,
+		Reference:      delivery.Reference,
+		Payload:        delivery.Payload,
+		Status:         string(delivery.DeliveryStatus),
+		Attempts:       delivery.Attempts,
+		NextAttemptAt:  delivery.NextAttemptAt.Time,
+		LastStatusCode: delivery.LastStatusCode.Int32,
+		LastError:      delivery.LastError.String,
+		CreatedAt:      delivery.CreatedAt.Time,
+	}
+	if delivery.DeliveredAt.Valid {
+		resp.DeliveredAt = &delivery.DeliveredAt.Time
+	}
+
+	for _, attempt := range attempts {
+		resp.AttemptLog = append(resp.AttemptLog, model.WebhookDeliveryAttemptResponse{
+			StatusCode: attempt.StatusCode.Int32,
+			Error:      attempt.Error.String,
+			DurationMs: attempt.DurationMs,
+			CreatedAt:  attempt.CreatedAt.Time,
+		})
+	}
+
+	return resp
+}
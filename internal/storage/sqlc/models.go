@@ -11,13 +11,318 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+type BatchItemStatus string
+
+const (
+	BatchItemStatusCREATED  BatchItemStatus = "CREATED"
+	BatchItemStatusREJECTED BatchItemStatus = "REJECTED"
+)
+
+func (e *BatchItemStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = BatchItemStatus(s)
+	case string:
+		*e = BatchItemStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for BatchItemStatus: %T", src)
+	}
+	return nil
+}
+
+type NullBatchItemStatus struct {
+	BatchItemStatus BatchItemStatus
+	Valid           bool // Valid is true if BatchItemStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullBatchItemStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.BatchItemStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.BatchItemStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullBatchItemStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.BatchItemStatus), nil
+}
+
+type BatchStatus string
+
+const (
+	BatchStatusCOMPLETED BatchStatus = "COMPLETED"
+	BatchStatusPARTIAL   BatchStatus = "PARTIAL"
+	BatchStatusFAILED    BatchStatus = "FAILED"
+)
+
+func (e *BatchStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = BatchStatus(s)
+	case string:
+		*e = BatchStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for BatchStatus: %T", src)
+	}
+	return nil
+}
+
+type NullBatchStatus struct {
+	BatchStatus BatchStatus
+	Valid       bool // Valid is true if BatchStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullBatchStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.BatchStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.BatchStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullBatchStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.BatchStatus), nil
+}
+
+type DiscrepancyCategory string
+
+const (
+	DiscrepancyCategoryMISSING        DiscrepancyCategory = "MISSING"
+	DiscrepancyCategoryORPHAN         DiscrepancyCategory = "ORPHAN"
+	DiscrepancyCategoryAMOUNTMISMATCH DiscrepancyCategory = "AMOUNT_MISMATCH"
+	DiscrepancyCategorySTATUSMISMATCH DiscrepancyCategory = "STATUS_MISMATCH"
+)
+
+func (e *DiscrepancyCategory) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = DiscrepancyCategory(s)
+	case string:
+		*e = DiscrepancyCategory(s)
+	default:
+		return fmt.Errorf("unsupported scan type for DiscrepancyCategory: %T", src)
+	}
+	return nil
+}
+
+type NullDiscrepancyCategory struct {
+	DiscrepancyCategory DiscrepancyCategory
+	Valid               bool // Valid is true if DiscrepancyCategory is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullDiscrepancyCategory) Scan(value interface{}) error {
+	if value == nil {
+		ns.DiscrepancyCategory, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.DiscrepancyCategory.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullDiscrepancyCategory) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.DiscrepancyCategory), nil
+}
+
+type DisputeStatus string
+
+const (
+	DisputeStatusOPENED      DisputeStatus = "OPENED"
+	DisputeStatusUNDERREVIEW DisputeStatus = "UNDER_REVIEW"
+	DisputeStatusWON         DisputeStatus = "WON"
+	DisputeStatusLOST        DisputeStatus = "LOST"
+)
+
+func (e *DisputeStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = DisputeStatus(s)
+	case string:
+		*e = DisputeStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for DisputeStatus: %T", src)
+	}
+	return nil
+}
+
+type NullDisputeStatus struct {
+	DisputeStatus DisputeStatus
+	Valid         bool // Valid is true if DisputeStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullDisputeStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.DisputeStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.DisputeStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullDisputeStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.DisputeStatus), nil
+}
+
+type NotificationStatus string
+
+const (
+	NotificationStatusPENDING NotificationStatus = "PENDING"
+	NotificationStatusSENT    NotificationStatus = "SENT"
+	NotificationStatusDEAD    NotificationStatus = "DEAD"
+	NotificationStatusSKIPPED NotificationStatus = "SKIPPED"
+)
+
+func (e *NotificationStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = NotificationStatus(s)
+	case string:
+		*e = NotificationStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for NotificationStatus: %T", src)
+	}
+	return nil
+}
+
+type NullNotificationStatus struct {
+	NotificationStatus NotificationStatus
+	Valid              bool // Valid is true if NotificationStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullNotificationStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.NotificationStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.NotificationStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullNotificationStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.NotificationStatus), nil
+}
+
+type ReconciliationStatus string
+
+const (
+	ReconciliationStatusRUNNING   ReconciliationStatus = "RUNNING"
+	ReconciliationStatusCOMPLETED ReconciliationStatus = "COMPLETED"
+	ReconciliationStatusFAILED    ReconciliationStatus = "FAILED"
+)
+
+func (e *ReconciliationStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = ReconciliationStatus(s)
+	case string:
+		*e = ReconciliationStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for ReconciliationStatus: %T", src)
+	}
+	return nil
+}
+
+type NullReconciliationStatus struct {
+	ReconciliationStatus ReconciliationStatus
+	Valid                bool // Valid is true if ReconciliationStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullReconciliationStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.ReconciliationStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.ReconciliationStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullReconciliationStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.ReconciliationStatus), nil
+}
+
+type RewardStatus string
+
+const (
+	RewardStatusPENDING    RewardStatus = "PENDING"
+	RewardStatusCREDITED   RewardStatus = "CREDITED"
+	RewardStatusCANCELLED  RewardStatus = "CANCELLED"
+	RewardStatusCLAWEDBACK RewardStatus = "CLAWED_BACK"
+)
+
+func (e *RewardStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = RewardStatus(s)
+	case string:
+		*e = RewardStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for RewardStatus: %T", src)
+	}
+	return nil
+}
+
+type NullRewardStatus struct {
+	RewardStatus RewardStatus
+	Valid        bool // Valid is true if RewardStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullRewardStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.RewardStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.RewardStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullRewardStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.RewardStatus), nil
+}
+
 type TransactionStatus string
 
 const (
-	TransactionStatusPENDING  TransactionStatus = "PENDING"
-	TransactionStatusSUCCESS  TransactionStatus = "SUCCESS"
-	TransactionStatusFAILED   TransactionStatus = "FAILED"
-	TransactionStatusREVERSED TransactionStatus = "REVERSED"
+	TransactionStatusPENDING   TransactionStatus = "PENDING"
+	TransactionStatusSUCCESS   TransactionStatus = "SUCCESS"
+	TransactionStatusFAILED    TransactionStatus = "FAILED"
+	TransactionStatusREVERSED  TransactionStatus = "REVERSED"
+	TransactionStatusCANCELLED TransactionStatus = "CANCELLED"
 )
 
 func (e *TransactionStatus) Scan(src interface{}) error {
@@ -58,9 +363,12 @@ func (ns NullTransactionStatus) Value() (driver.Value, error) {
 type TransactionType string
 
 const (
-	TransactionTypeTOPUP    TransactionType = "TOPUP"
-	TransactionTypePURCHASE TransactionType = "PURCHASE"
-	TransactionTypeREFUND   TransactionType = "REFUND"
+	TransactionTypeTOPUP         TransactionType = "TOPUP"
+	TransactionTypePURCHASE      TransactionType = "PURCHASE"
+	TransactionTypeREFUND        TransactionType = "REFUND"
+	TransactionTypeCASHBACK      TransactionType = "CASHBACK"
+	TransactionTypeREVERSAL      TransactionType = "REVERSAL"
+	TransactionTypeDISPUTECREDIT TransactionType = "DISPUTE_CREDIT"
 )
 
 func (e *TransactionType) Scan(src interface{}) error {
@@ -98,6 +406,186 @@ func (ns NullTransactionType) Value() (driver.Value, error) {
 	return string(ns.TransactionType), nil
 }
 
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryStatusPENDING   WebhookDeliveryStatus = "PENDING"
+	WebhookDeliveryStatusDELIVERED WebhookDeliveryStatus = "DELIVERED"
+	WebhookDeliveryStatusFAILED    WebhookDeliveryStatus = "FAILED"
+)
+
+func (e *WebhookDeliveryStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = WebhookDeliveryStatus(s)
+	case string:
+		*e = WebhookDeliveryStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for WebhookDeliveryStatus: %T", src)
+	}
+	return nil
+}
+
+type NullWebhookDeliveryStatus struct {
+	WebhookDeliveryStatus WebhookDeliveryStatus
+	Valid                 bool // Valid is true if WebhookDeliveryStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullWebhookDeliveryStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.WebhookDeliveryStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.WebhookDeliveryStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullWebhookDeliveryStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.WebhookDeliveryStatus), nil
+}
+
+type AdminAuditLog struct {
+	ID         int32
+	AdminID    int32
+	AdminEmail string
+	Action     string
+	Reference  pgtype.Text
+	Reason     pgtype.Text
+	Detail     pgtype.Text
+	Succeeded  bool
+	Error      pgtype.Text
+	CreatedAt  pgtype.Timestamp
+}
+
+type Dispute struct {
+	ID                   int32
+	TransactionReference string
+	UserID               int32
+	Amount               pgtype.Numeric
+	Reason               string
+	DisputeStatus        DisputeStatus
+	CreditReference      pgtype.Text
+	Resolution           pgtype.Text
+	DeadlineAt           pgtype.Timestamp
+	ResolvedAt           pgtype.Timestamp
+	CreatedAt            pgtype.Timestamp
+	UpdatedAt            pgtype.Timestamp
+}
+
+type DisputeEvidence struct {
+	ID             int32
+	DisputeID      int32
+	Author         string
+	Message        string
+	AttachmentName pgtype.Text
+	AttachmentUrl  pgtype.Text
+	CreatedAt      pgtype.Timestamp
+}
+
+type EventOutbox struct {
+	ID          int64
+	Reference   string
+	EventType   string
+	Payload     []byte
+	Attempts    int32
+	LastError   pgtype.Text
+	PublishedAt pgtype.Timestamp
+	CreatedAt   pgtype.Timestamp
+	UserID      pgtype.Int4
+	DeadAt      pgtype.Timestamp
+}
+
+type NotificationContact struct {
+	UserID    int32
+	Email     string
+	UpdatedAt pgtype.Timestamp
+}
+
+type NotificationPreference struct {
+	UserID         int32
+	DisabledEvents []string
+	Channels       []string
+	QuietStart     pgtype.Int2
+	QuietEnd       pgtype.Int2
+	Timezone       string
+	MinAmount      pgtype.Numeric
+	UpdatedAt      pgtype.Timestamp
+}
+
+type NotificationQueue struct {
+	ID                 int32
+	Reference          string
+	Recipient          string
+	TemplateName       string
+	Placeholder        string
+	NotificationStatus NotificationStatus
+	Attempts           int32
+	NextAttemptAt      pgtype.Timestamp
+	LastError          pgtype.Text
+	SentAt             pgtype.Timestamp
+	CreatedAt          pgtype.Timestamp
+	UpdatedAt          pgtype.Timestamp
+	UserID             pgtype.Int4
+	Event              pgtype.Text
+	Amount             pgtype.Numeric
+	Channel            string
+}
+
+type ReconciliationDiscrepancy struct {
+	ID                int32
+	RunID             int32
+	Category          DiscrepancyCategory
+	Reference         string
+	TransactionAmount pgtype.Numeric
+	WalletAmount      pgtype.Numeric
+	TransactionStatus pgtype.Text
+	SuggestedRepair   string
+	CreatedAt         pgtype.Timestamp
+}
+
+type ReconciliationRun struct {
+	ID                   int32
+	WindowStart          pgtype.Timestamp
+	WindowEnd            pgtype.Timestamp
+	ReconciliationStatus ReconciliationStatus
+	MatchedCount         int32
+	DiscrepancyCount     int32
+	Error                pgtype.Text
+	CreatedAt            pgtype.Timestamp
+	FinishedAt           pgtype.Timestamp
+}
+
+type Reward struct {
+	ID                   int32
+	CampaignID           int32
+	UserID               int32
+	TransactionReference string
+	CreditReference      pgtype.Text
+	Amount               pgtype.Numeric
+	RewardStatus         RewardStatus
+	ClearAt              pgtype.Timestamp
+	CreatedAt            pgtype.Timestamp
+	UpdatedAt            pgtype.Timestamp
+}
+
+type RewardCampaign struct {
+	ID                 int32
+	Name               string
+	Percentage         pgtype.Numeric
+	MaxPerUser         pgtype.Numeric
+	MerchantCategories []string
+	StartsAt           pgtype.Timestamp
+	EndsAt             pgtype.Timestamp
+	IsActive           bool
+	CreatedAt          pgtype.Timestamp
+	UpdatedAt          pgtype.Timestamp
+}
+
 type Transaction struct {
 	ID                int32
 	UserID            int32
@@ -109,4 +597,82 @@ type Transaction struct {
 	AdditionalInfo    pgtype.Text
 	CreatedAt         pgtype.Timestamp
 	UpdatedAt         pgtype.Timestamp
+	StatusReason      pgtype.Text
+	SettledAt         pgtype.Timestamp
+}
+
+type TransactionBatch struct {
+	ID            int32
+	BatchID       string
+	UserID        int32
+	AllOrNothing  bool
+	TotalItems    int32
+	CreatedItems  int32
+	RejectedItems int32
+	BatchStatus   BatchStatus
+	CreatedAt     pgtype.Timestamp
+	UpdatedAt     pgtype.Timestamp
+}
+
+type TransactionBatchItem struct {
+	ID         int32
+	BatchID    string
+	ItemIndex  int32
+	Reference  pgtype.Text
+	ItemStatus BatchItemStatus
+	Error      pgtype.Text
+	CreatedAt  pgtype.Timestamp
+}
+
+type TransactionRefund struct {
+	ID                   int32
+	TransactionReference string
+	RefundReference      string
+	Amount               pgtype.Numeric
+	CreatedAt            pgtype.Timestamp
+}
+
+type TransactionReversal struct {
+	ID                   int32
+	TransactionReference string
+	ReversalReference    string
+	Amount               pgtype.Numeric
+	CreatedAt            pgtype.Timestamp
+}
+
+type WebhookDelivery struct {
+	ID             int32
+	EndpointID     int32
+	Event          string
+	Reference      string
+	Payload        string
+	DeliveryStatus WebhookDeliveryStatus
+	Attempts       int32
+	NextAttemptAt  pgtype.Timestamp
+	LastStatusCode pgtype.Int4
+	LastError      pgtype.Text
+	DeliveredAt    pgtype.Timestamp
+	CreatedAt      pgtype.Timestamp
+	UpdatedAt      pgtype.Timestamp
+}
+
+type WebhookDeliveryAttempt struct {
+	ID         int32
+	DeliveryID int32
+	StatusCode pgtype.Int4
+	Error      pgtype.Text
+	DurationMs int32
+	CreatedAt  pgtype.Timestamp
+}
+
+type WebhookEndpoint struct {
+	ID                  int32
+	UserID              int32
+	Url                 string
+	Secret              string
+	IsActive            bool
+	ConsecutiveFailures int32
+	DisabledAt          pgtype.Timestamp
+	CreatedAt           pgtype.Timestamp
+	UpdatedAt           pgtype.Timestamp
 }
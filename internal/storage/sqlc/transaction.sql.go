@@ -47,8 +47,18 @@ func (q *Queries) CreateTransaction(ctx context.Context, arg CreateTransactionPa
 	return i, err
 }
 
+type CreateTransactionsParams struct {
+	UserID            int32
+	Amount            pgtype.Numeric
+	TransactionType   TransactionType
+	TransactionStatus TransactionStatus
+	Reference         string
+	Description       pgtype.Text
+	AdditionalInfo    pgtype.Text
+}
+
 const getTransactionByReference = `-- name: GetTransactionByReference :one
-SELECT id, user_id, amount, transaction_type, transaction_status, reference, description, additional_info, created_at, updated_at
+SELECT id, user_id, amount, transaction_type, transaction_status, reference, description, additional_info, created_at, updated_at, status_reason, settled_at
 FROM transaction WHERE reference = $1
 `
 
@@ -66,12 +76,14 @@ func (q *Queries) GetTransactionByReference(ctx context.Context, reference strin
 		&i.AdditionalInfo,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.StatusReason,
+		&i.SettledAt,
 	)
 	return i, err
 }
 
 const getTransactionByReferenceAndUserId = `-- name: GetTransactionByReferenceAndUserId :one
-SELECT id, user_id, amount, transaction_type, transaction_status, reference, description, additional_info, created_at, updated_at
+SELECT id, user_id, amount, transaction_type, transaction_status, reference, description, additional_info, created_at, updated_at, status_reason, settled_at
 FROM transaction 
 WHERE reference = $1 AND user_id = $2
 `
@@ -95,6 +107,34 @@ func (q *Queries) GetTransactionByReferenceAndUserId(ctx context.Context, arg Ge
 		&i.AdditionalInfo,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.StatusReason,
+		&i.SettledAt,
+	)
+	return i, err
+}
+
+const getTransactionByReferenceForUpdate = `-- name: GetTransactionByReferenceForUpdate :one
+SELECT id, user_id, amount, transaction_type, transaction_status, reference, description, additional_info, created_at, updated_at, status_reason, settled_at
+FROM transaction WHERE reference = $1
+FOR UPDATE
+`
+
+func (q *Queries) GetTransactionByReferenceForUpdate(ctx context.Context, reference string) (Transaction, error) {
+	row := q.db.QueryRow(ctx, getTransactionByReferenceForUpdate, reference)
+	var i Transaction
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Amount,
+		&i.TransactionType,
+		&i.TransactionStatus,
+		&i.Reference,
+		&i.Description,
+		&i.AdditionalInfo,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.StatusReason,
+		&i.SettledAt,
 	)
 	return i, err
 }
@@ -146,8 +186,155 @@ func (q *Queries) GetTransactions(ctx context.Context, arg GetTransactionsParams
 	return items, nil
 }
 
+const getTransactionsByReferences = `-- name: GetTransactionsByReferences :many
+SELECT id, user_id, amount, transaction_type, transaction_status, reference, description, additional_info, created_at, updated_at, status_reason, settled_at
+FROM transaction
+WHERE reference = ANY($1::text[])
+`
+
+func (q *Queries) GetTransactionsByReferences(ctx context.Context, references []string) ([]Transaction, error) {
+	rows, err := q.db.Query(ctx, getTransactionsByReferences, references)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Transaction
+	for rows.Next() {
+		var i Transaction
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Amount,
+			&i.TransactionType,
+			&i.TransactionStatus,
+			&i.Reference,
+			&i.Description,
+			&i.AdditionalInfo,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.StatusReason,
+			&i.SettledAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getTransactionsBySettledAt = `-- name: GetTransactionsBySettledAt :many
+SELECT id, user_id, amount, transaction_type, transaction_status, reference, description, additional_info, created_at, updated_at, status_reason, settled_at
+FROM transaction
+WHERE settled_at >= $1 AND settled_at < $2
+ORDER BY settled_at
+`
+
+type GetTransactionsBySettledAtParams struct {
+	SettledAt   pgtype.Timestamp
+	SettledAt_2 pgtype.Timestamp
+}
+
+func (q *Queries) GetTransactionsBySettledAt(ctx context.Context, arg GetTransactionsBySettledAtParams) ([]Transaction, error) {
+	rows, err := q.db.Query(ctx, getTransactionsBySettledAt, arg.SettledAt, arg.SettledAt_2)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Transaction
+	for rows.Next() {
+		var i Transaction
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Amount,
+			&i.TransactionType,
+			&i.TransactionStatus,
+			&i.Reference,
+			&i.Description,
+			&i.AdditionalInfo,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.StatusReason,
+			&i.SettledAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getUserTransactionsAfter = `-- name: GetUserTransactionsAfter :many
+SELECT id, user_id, amount, transaction_type, transaction_status, reference, description, additional_info, created_at, updated_at, status_reason, settled_at
+FROM transaction
+WHERE user_id = $1 AND id > $2
+AND ($3::text = '' OR transaction_type::text = $3)
+AND ($4::text = '' OR transaction_status::text = $4)
+AND ($5::timestamp IS NULL OR created_at >= $5)
+AND ($6::timestamp IS NULL OR created_at < $6)
+ORDER BY id
+LIMIT $7
+`
+
+type GetUserTransactionsAfterParams struct {
+	UserID            int32
+	AfterID           int32
+	TransactionType   string
+	TransactionStatus string
+	CreatedFrom       pgtype.Timestamp
+	CreatedTo         pgtype.Timestamp
+	Limit             int32
+}
+
+func (q *Queries) GetUserTransactionsAfter(ctx context.Context, arg GetUserTransactionsAfterParams) ([]Transaction, error) {
+	rows, err := q.db.Query(ctx, getUserTransactionsAfter,
+		arg.UserID,
+		arg.AfterID,
+		arg.TransactionType,
+		arg.TransactionStatus,
+		arg.CreatedFrom,
+		arg.CreatedTo,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Transaction
+	for rows.Next() {
+		var i Transaction
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Amount,
+			&i.TransactionType,
+			&i.TransactionStatus,
+			&i.Reference,
+			&i.Description,
+			&i.AdditionalInfo,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.StatusReason,
+			&i.SettledAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const updateTransactionStatusByReference = `-- name: UpdateTransactionStatusByReference :one
-UPDATE transaction SET transaction_status = $2, additional_info = $3, updated_at = CURRENT_TIMESTAMP
+UPDATE transaction SET transaction_status = $2, additional_info = $3, status_reason = $4, updated_at = CURRENT_TIMESTAMP
 WHERE reference = $1
 RETURNING transaction_status
 `
@@ -156,10 +343,16 @@ type UpdateTransactionStatusByReferenceParams struct {
 	Reference         string
 	TransactionStatus TransactionStatus
 	AdditionalInfo    pgtype.Text
+	StatusReason      pgtype.Text
 }
 
 func (q *Queries) UpdateTransactionStatusByReference(ctx context.Context, arg UpdateTransactionStatusByReferenceParams) (TransactionStatus, error) {
-	row := q.db.QueryRow(ctx, updateTransactionStatusByReference, arg.Reference, arg.TransactionStatus, arg.AdditionalInfo)
+	row := q.db.QueryRow(ctx, updateTransactionStatusByReference,
+		arg.Reference,
+		arg.TransactionStatus,
+		arg.AdditionalInfo,
+		arg.StatusReason,
+	)
 	var transaction_status TransactionStatus
 	err := row.Scan(&transaction_status)
 	return transaction_status, err
@@ -0,0 +1,139 @@
+package stream
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/sirupsen/logrus"
+)
+
+// Channel is notified by the event_outbox trigger on every new event.
+const Channel = "transaction_events"
+
+const reconnectDelay = 5 * time.Second
+
+type notification struct {
+	ID     int64 `json:"id"`
+	UserID int32 `json:"user_id"`
+}
+
+// Hub fans Postgres notifications out to the streams open on this instance.
+// Subscribers are only told that a user has new events, they read the events
+// themselves from the outbox, so a missed or coalesced signal loses nothing.
+type Hub struct {
+	db     *pgxpool.Pool
+	logger *logrus.Logger
+
+	mu   sync.Mutex
+	subs map[int32]map[chan struct{}]struct{}
+}
+
+func NewHub(db *pgxpool.Pool, logger *logrus.Logger) *Hub {
+	return &Hub{
+		db:     db,
+		logger: logger,
+		subs:   map[int32]map[chan struct{}]struct{}{},
+	}
+}
+
+// Subscribe returns a channel signalled whenever userID has new events, and a
+// func that removes the subscription.
+func (h *Hub) Subscribe(userID int32) (<-chan struct{}, func()) {
+	ch := make(chan struct{}, 1)
+
+	h.mu.Lock()
+	if h.subs[userID] == nil {
+		h.subs[userID] = map[chan struct{}]struct{}{}
+	}
+	h.subs[userID][ch] = struct{}{}
+	h.mu.Unlock()
+
+	return ch, func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		delete(h.subs[userID], ch)
+		if len(h.subs[userID]) == 0 {
+			delete(h.subs, userID)
+		}
+	}
+}
+
+func signal(ch chan struct{}) {
+	select {
+	case ch <- struct{}{}:
+	default:
+	}
+}
+
+func (h *Hub) notify(userID int32) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for ch := range h.subs[userID] {
+		signal(ch)
+	}
+}
+
+func (h *Hub) notifyAll() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, subs := range h.subs {
+		for ch := range subs {
+			signal(ch)
+		}
+	}
+}
+
+// Listen holds a dedicated connection LISTENing on Channel until ctx is
+// cancelled, reconnecting when the connection is lost.
+func (h *Hub) Listen(ctx context.Context) {
+	h.logger.Infof("stream hub listening on %s", Channel)
+
+	for {
+		if err := h.listen(ctx); err != nil && ctx.Err() == nil {
+			h.logger.WithError(err).Errorf("stream hub lost connection, retrying in %v", reconnectDelay)
+		}
+
+		select {
+		case <-ctx.Done():
+			h.logger.Info("stream hub stopped")
+			return
+		case <-time.After(reconnectDelay):
+		}
+	}
+}
+
+func (h *Hub) listen(ctx context.Context) error {
+	poolConn, err := h.db.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+
+	// the connection stays in LISTEN mode, so it must not go back to the pool
+	conn := poolConn.Hijack()
+	defer conn.Close(context.Background())
+
+	if _, err := conn.Exec(ctx, "LISTEN "+Channel); err != nil {
+		return err
+	}
+
+	// events may have been written while we were not listening
+	h.notifyAll()
+
+	for {
+		n, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+
+		var payload notification
+		if err := json.Unmarshal([]byte(n.Payload), &payload); err != nil {
+			h.logger.WithError(err).Warnf("stream hub got malformed notification: %s", n.Payload)
+			continue
+		}
+
+		h.notify(payload.UserID)
+	}
+}
@@ -0,0 +1,73 @@
+package tracing
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/valyala/fasthttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// headerCarrier reads and writes the trace context in fasthttp headers.
+type headerCarrier struct {
+	header *fasthttp.RequestHeader
+}
+
+func (c headerCarrier) Get(key string) string {
+	return string(c.header.Peek(key))
+}
+
+func (c headerCarrier) Set(key, value string) {
+	c.header.Set(key, value)
+}
+
+func (c headerCarrier) Keys() []string {
+	var keys []string
+	c.header.VisitAll(func(key, _ []byte) {
+		keys = append(keys, string(key))
+	})
+	return keys
+}
+
+// HTTP starts a server span for every request, continuing the trace of the
+// traceparent header, and hands it to the handlers in the user context. It
+// has to be installed before the routes. Like metrics.HTTP it answers errors
+// itself so the span carries the status the client gets.
+func HTTP() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		parent := otel.GetTextMapPropagator().Extract(ctx.UserContext(), headerCarrier{&ctx.Request().Header})
+		spanCtx, span := tracer.Start(parent, ctx.Method(),
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPRequestMethodKey.String(ctx.Method()),
+				semconv.URLPath(ctx.Path()),
+			),
+		)
+		defer span.End()
+
+		ctx.SetUserContext(spanCtx)
+		own := ctx.Route()
+
+		if err := ctx.Next(); err != nil {
+			span.RecordError(err)
+			if err := ctx.App().Config().ErrorHandler(ctx, err); err != nil {
+				_ = ctx.SendStatus(fiber.StatusInternalServerError)
+			}
+		}
+
+		// without a matching route the context keeps the route of the last
+		// middleware, installed at the same path as this one
+		if r := ctx.Route(); r.Path != own.Path {
+			span.SetName(ctx.Method() + " " + r.Path)
+			span.SetAttributes(semconv.HTTPRoute(r.Path))
+		}
+
+		status := ctx.Response().StatusCode()
+		span.SetAttributes(semconv.HTTPResponseStatusCode(status))
+		if status >= fiber.StatusInternalServerError {
+			span.SetStatus(codes.Error, fiber.ErrInternalServerError.Message)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,58 @@
+package tracing
+
+import (
+	"context"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// QueryTracer is the pgx tracer of the pool, it starts a client span for every
+// query and copy.
+type QueryTracer struct{}
+
+// queryName names a query after its sqlc name, e.g. GetTransactionByReference,
+// or else after its first keyword.
+func queryName(sql string) string {
+	sql = strings.TrimSpace(sql)
+	if name, ok := strings.CutPrefix(sql, "-- name: "); ok {
+		name, _, _ = strings.Cut(name, " ")
+		return name
+	}
+
+	keyword, _, _ := strings.Cut(sql, " ")
+	return strings.ToUpper(keyword)
+}
+
+func (QueryTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	ctx, _ = tracer.Start(ctx, queryName(data.SQL),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBSystemPostgreSQL,
+			semconv.DBOperationName(queryName(data.SQL)),
+			semconv.DBQueryText(data.SQL),
+		),
+	)
+	return ctx
+}
+
+func (QueryTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	End(trace.SpanFromContext(ctx), data.Err)
+}
+
+func (QueryTracer) TraceCopyFromStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceCopyFromStartData) context.Context {
+	ctx, _ = tracer.Start(ctx, "COPY "+data.TableName.Sanitize(),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBSystemPostgreSQL,
+			semconv.DBOperationName("COPY"),
+		),
+	)
+	return ctx
+}
+
+func (QueryTracer) TraceCopyFromEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceCopyFromEndData) {
+	End(trace.SpanFromContext(ctx), data.Err)
+}
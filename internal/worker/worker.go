@@ -0,0 +1,51 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"github.com/ArdiSasongko/EwalletProjects-transaction/internal/metrics"
+	"github.com/sirupsen/logrus"
+)
+
+type Job func(context.Context) error
+
+type Worker struct {
+	name     string
+	interval time.Duration
+	job      Job
+	logger   *logrus.Logger
+}
+
+func New(name string, interval time.Duration, job Job, logger *logrus.Logger) *Worker {
+	return &Worker{
+		name:     name,
+		interval: interval,
+		job:      job,
+		logger:   logger,
+	}
+}
+
+// Run executes the job every interval until ctx is cancelled. A failing run is
+// logged and retried on the next tick.
+func (w *Worker) Run(ctx context.Context) {
+	w.logger.Infof("worker %s has running, interval %v", w.name, w.interval)
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			w.logger.Infof("worker %s stopped", w.name)
+			return
+		case <-ticker.C:
+			start := time.Now()
+			err := w.job(ctx)
+			metrics.ObserveWorkerRun(w.name, start, err)
+			if err != nil {
+				w.logger.WithError(err).Errorf("worker %s failed", w.name)
+			}
+		}
+	}
+}